@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"os"
 
 	"k8s.io/klog/v2"
@@ -10,25 +11,32 @@ import (
 	"github.com/rikatz/kgame/pkg/controllers"
 	"github.com/rikatz/kgame/pkg/controllers/gateway"
 	"github.com/rikatz/kgame/pkg/controllers/gatewayclass"
+	"github.com/rikatz/kgame/pkg/flags"
+	"github.com/rikatz/kgame/pkg/hooks"
 )
 
 // THIS IS AN EXAMPLE USED FOR TESTS!
 func main() {
+	kgameFlags := flags.New()
+	kgameFlags.AddGoFlags(flag.CommandLine)
+	flag.Parse()
+
 	ctx := ctrl.SetupSignalHandler()
 
-	addFinalizerFunc := func(ctx context.Context) error {
-		klog.Info("I am a finalizer add function")
+	addFinalizerFunc := func(ctx context.Context, fctx hooks.FinalizerContext) error {
+		fctx.Logger.Info("I am a finalizer add function", "reason", fctx.Reason)
 		return nil
 	}
 
-	removeFinalizerFunc := func(ctx context.Context) error {
-		klog.Info("I am a finalizer removal function")
+	removeFinalizerFunc := func(ctx context.Context, fctx hooks.FinalizerContext) error {
+		fctx.Logger.Info("I am a finalizer removal function", "reason", fctx.Reason)
 		return nil
 	}
 
 	opts := &controllers.ControllerOptions{
 		ControllerClass: "gateway.mylab.tld/gatewayclass-controller",
 		ControllerName:  "something",
+		SetGlobalLogger: true,
 		GatewayClassOptions: gatewayclass.GatewayClassOptions{
 			FinalizerName:       "gateway.mylab.tld/gatewayclass-finalizer",
 			AddFinalizerFunc:    addFinalizerFunc,
@@ -40,7 +48,7 @@ func main() {
 			RemoveFinalizerFunc: removeFinalizerFunc,
 		},
 	}
-	ctr, err := controllers.NewController(opts)
+	ctr, err := controllers.NewController(opts, kgameFlags.Option())
 	if err != nil {
 		klog.Errorf("unable to create the controller instance: %s", err)
 		os.Exit(1)
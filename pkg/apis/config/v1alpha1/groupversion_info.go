@@ -0,0 +1,37 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The package v1alpha1 contains the KgameConfig API, the runtime configuration
+// of the kgame controllers.
+// +kubebuilder:object:generate=true
+// +groupName=kgame.io
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/scheme"
+)
+
+var (
+	// GroupVersion is the group version of the KgameConfig API
+	GroupVersion = schema.GroupVersion{Group: "kgame.io", Version: "v1alpha1"}
+
+	// SchemeBuilder registers the KgameConfig types on a scheme
+	SchemeBuilder = &scheme.Builder{GroupVersion: GroupVersion}
+
+	// AddToScheme adds the KgameConfig types to a scheme
+	AddToScheme = SchemeBuilder.AddToScheme
+)
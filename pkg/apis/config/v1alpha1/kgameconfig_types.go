@@ -0,0 +1,89 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// KgameConfigSpec are the settings of the kgame controllers that are safe to
+// change while the controllers are running. Unset fields restore the values the
+// controllers were started with
+type KgameConfigSpec struct {
+	// LogLevel is the klog verbosity of the controllers. Unsetting it keeps the
+	// last verbosity set
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	LogLevel *int32 `json:"logLevel,omitempty"`
+	// LogLevels are the verbosity of each logging subsystem, like
+	// {"cache-transform": 0, "gateway": 4}. They apply only to the controllers
+	// logging through the kgame logging package
+	// +optional
+	LogLevels map[string]int32 `json:"logLevels,omitempty"`
+	// GatewayClass configures the GatewayClass controller
+	// +optional
+	GatewayClass *ControllerConfig `json:"gatewayClass,omitempty"`
+	// Gateway configures the Gateway controller
+	// +optional
+	Gateway *ControllerConfig `json:"gateway,omitempty"`
+	// HTTPRoute configures the HTTPRoute controller
+	// +optional
+	HTTPRoute *ControllerConfig `json:"httpRoute,omitempty"`
+	// FeatureGates enables or disables the implementer features, by name. They are
+	// read by the hooks through Controller.FeatureEnabled
+	// +optional
+	FeatureGates map[string]bool `json:"featureGates,omitempty"`
+}
+
+// ControllerConfig are the runtime settings of a controller
+type ControllerConfig struct {
+	// MaxConcurrentReconciles is the number of resources reconciled in parallel.
+	// It is capped by the number of workers the controller was started with
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxConcurrentReconciles *int32 `json:"maxConcurrentReconciles,omitempty"`
+	// ResyncPeriod reconciles each resource again after the given period since
+	// its last successful reconciliation. Zero disables it
+	// +optional
+	ResyncPeriod *metav1.Duration `json:"resyncPeriod,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+
+// KgameConfig is the runtime configuration of a kgame based controller. The
+// controller watches the KgameConfig named after it, and applies the changes
+// without restarting
+type KgameConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec KgameConfigSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// KgameConfigList contains a list of KgameConfig
+type KgameConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KgameConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&KgameConfig{}, &KgameConfigList{})
+}
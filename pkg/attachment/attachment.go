@@ -0,0 +1,235 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The package attachment resolves if and how routes attach to the listeners of
+// their parent Gateways, following the Gateway API attachment rules.
+package attachment
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// routeKindsByProtocol are the route kinds supported by kgame for each listener
+// protocol
+var routeKindsByProtocol = map[gatewayv1.ProtocolType][]gatewayv1.Kind{
+	gatewayv1.HTTPProtocolType:  {"HTTPRoute"},
+	gatewayv1.HTTPSProtocolType: {"HTTPRoute"},
+}
+
+// Route contains the route information required to resolve its attachment
+type Route struct {
+	// Kind of the route, like HTTPRoute
+	Kind gatewayv1.Kind
+	// Namespace of the route
+	Namespace string
+	// NamespaceLabels are the labels of the route namespace, used by listeners
+	// allowing routes from namespaces matching a selector
+	NamespaceLabels map[string]string
+	// Hostnames of the route
+	Hostnames []gatewayv1.Hostname
+}
+
+// Result is the outcome of the attachment of a route to a parent Gateway
+type Result struct {
+	// Accepted defines if the route is attached to at least one listener
+	Accepted bool
+	// Reason of the route Accepted condition
+	Reason gatewayv1.RouteConditionReason
+	// Message of the route Accepted condition
+	Message string
+	// Listeners are the names of the listeners the route is attached to
+	Listeners []gatewayv1.SectionName
+}
+
+// Resolve resolves the attachment of the route to the Gateway referenced by
+// parentRef. The listeners are first filtered by the parentRef sectionName and
+// port, then by the allowedRoutes of each listener and finally by the hostname
+// intersection between the listener and the route, and the result carries the
+// reason of the first rule that left no listener available
+func Resolve(route Route, parentRef gatewayv1.ParentReference, gw *gatewayv1.Gateway) Result {
+	candidates := make([]gatewayv1.Listener, 0, len(gw.Spec.Listeners))
+	for _, listener := range gw.Spec.Listeners {
+		if parentRef.SectionName != nil && *parentRef.SectionName != listener.Name {
+			continue
+		}
+		if parentRef.Port != nil && *parentRef.Port != listener.Port {
+			continue
+		}
+		candidates = append(candidates, listener)
+	}
+	if len(candidates) == 0 {
+		return Result{
+			Reason:  gatewayv1.RouteReasonNoMatchingParent,
+			Message: "no listener matches the parentRef sectionName or port",
+		}
+	}
+
+	allowed := make([]gatewayv1.Listener, 0, len(candidates))
+	for _, listener := range candidates {
+		if allowsRoute(listener, gw.GetNamespace(), route) {
+			allowed = append(allowed, listener)
+		}
+	}
+	if len(allowed) == 0 {
+		return Result{
+			Reason:  gatewayv1.RouteReasonNotAllowedByListeners,
+			Message: fmt.Sprintf("no listener allows %s from namespace %s", route.Kind, route.Namespace),
+		}
+	}
+
+	result := Result{
+		Accepted: true,
+		Reason:   gatewayv1.RouteReasonAccepted,
+		Message:  "Route is accepted",
+	}
+	for _, listener := range allowed {
+		if HostnamesIntersect(listener.Hostname, route.Hostnames) {
+			result.Listeners = append(result.Listeners, listener.Name)
+		}
+	}
+	if len(result.Listeners) == 0 {
+		return Result{
+			Reason:  gatewayv1.RouteReasonNoMatchingListenerHostname,
+			Message: "no listener hostname matches the route hostnames",
+		}
+	}
+	return result
+}
+
+// SupportedKinds returns the route kinds that can be attached to the listener,
+// being the kinds supported by kgame for the listener protocol, filtered by the
+// allowedRoutes.kinds of the listener when set
+func SupportedKinds(listener gatewayv1.Listener) []gatewayv1.RouteGroupKind {
+	kinds := make([]gatewayv1.RouteGroupKind, 0)
+	for _, kind := range routeKindsByProtocol[listener.Protocol] {
+		if listener.AllowedRoutes != nil && len(listener.AllowedRoutes.Kinds) > 0 && !allowsKind(listener.AllowedRoutes.Kinds, kind) {
+			continue
+		}
+		group := gatewayv1.Group(gatewayv1.GroupName)
+		kinds = append(kinds, gatewayv1.RouteGroupKind{
+			Group: &group,
+			Kind:  kind,
+		})
+	}
+	return kinds
+}
+
+// HostnamesIntersect returns true if the listener hostname intersects with any of
+// the route hostnames. An empty listener hostname or an empty list of route
+// hostnames matches everything
+func HostnamesIntersect(listenerHostname *gatewayv1.Hostname, routeHostnames []gatewayv1.Hostname) bool {
+	if listenerHostname == nil || *listenerHostname == "" || len(routeHostnames) == 0 {
+		return true
+	}
+	for _, hostname := range routeHostnames {
+		if hostnameMatches(string(*listenerHostname), string(hostname)) {
+			return true
+		}
+	}
+	return false
+}
+
+// hostnameMatches verifies if two hostnames, any of them possibly being a
+// wildcard, intersect
+func hostnameMatches(a, b string) bool {
+	if a == b {
+		return true
+	}
+	aWildcard, bWildcard := strings.HasPrefix(a, "*."), strings.HasPrefix(b, "*.")
+	switch {
+	case aWildcard && bWildcard:
+		return strings.HasSuffix(a[1:], b[1:]) || strings.HasSuffix(b[1:], a[1:])
+	case aWildcard:
+		return strings.HasSuffix(b, a[1:])
+	case bWildcard:
+		return strings.HasSuffix(a, b[1:])
+	}
+	return false
+}
+
+// allowsRoute verifies the listener allowedRoutes against the route kind and
+// namespace
+func allowsRoute(listener gatewayv1.Listener, gatewayNamespace string, route Route) bool {
+	var kindAllowed bool
+	for _, rgk := range SupportedKinds(listener) {
+		if rgk.Kind == route.Kind {
+			kindAllowed = true
+			break
+		}
+	}
+	if !kindAllowed {
+		return false
+	}
+
+	from := gatewayv1.NamespacesFromSame
+	var selector *metav1.LabelSelector
+	if listener.AllowedRoutes != nil && listener.AllowedRoutes.Namespaces != nil {
+		if listener.AllowedRoutes.Namespaces.From != nil {
+			from = *listener.AllowedRoutes.Namespaces.From
+		}
+		selector = listener.AllowedRoutes.Namespaces.Selector
+	}
+
+	switch from {
+	case gatewayv1.NamespacesFromAll:
+		return true
+	case gatewayv1.NamespacesFromSame:
+		return route.Namespace == gatewayNamespace
+	case gatewayv1.NamespacesFromSelector:
+		if selector == nil {
+			return false
+		}
+		s, err := metav1.LabelSelectorAsSelector(selector)
+		if err != nil {
+			return false
+		}
+		return s.Matches(labels.Set(route.NamespaceLabels))
+	}
+	return false
+}
+
+func allowsKind(allowed []gatewayv1.RouteGroupKind, kind gatewayv1.Kind) bool {
+	for _, rgk := range allowed {
+		if rgk.Kind != kind {
+			continue
+		}
+		if rgk.Group == nil || *rgk.Group == gatewayv1.GroupName {
+			return true
+		}
+	}
+	return false
+}
+
+// ParentRefersTo returns true if parentRef, from a route on routeNamespace,
+// references the Gateway
+func ParentRefersTo(parentRef gatewayv1.ParentReference, routeNamespace string, gw *gatewayv1.Gateway) bool {
+	if parentRef.Group != nil && *parentRef.Group != gatewayv1.GroupName {
+		return false
+	}
+	if parentRef.Kind != nil && *parentRef.Kind != "Gateway" {
+		return false
+	}
+	namespace := routeNamespace
+	if parentRef.Namespace != nil {
+		namespace = string(*parentRef.Namespace)
+	}
+	return namespace == gw.GetNamespace() && string(parentRef.Name) == gw.GetName()
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The package conditions contains helpers to manipulate the status conditions
+// of the resources reconciled by kgame.
+package conditions
+
+import (
+	"slices"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Set adds the condition to conditions, or replaces an existing condition with
+// the same type. The LastTransitionTime is only changed when the status of the
+// condition changes. It returns true if conditions was modified
+func Set(conditions *[]metav1.Condition, condition metav1.Condition) bool {
+	return meta.SetStatusCondition(conditions, condition)
+}
+
+// Remove removes the condition with the given type from conditions. It returns
+// true if a condition was removed
+func Remove(conditions *[]metav1.Condition, conditionType string) bool {
+	return meta.RemoveStatusCondition(conditions, conditionType)
+}
+
+// BecameTrue returns true if the condition with the given type is True on after,
+// but was not True on before
+func BecameTrue(before, after []metav1.Condition, conditionType string) bool {
+	return !meta.IsStatusConditionTrue(before, conditionType) && meta.IsStatusConditionTrue(after, conditionType)
+}
+
+// Prune removes from conditions every condition whose type is owned by kgame
+// but that is not on the active list anymore, like a Conflicted condition after
+// the conflict was resolved. Conditions not owned by kgame are never touched.
+// It returns true if any condition was removed
+func Prune(conditions *[]metav1.Condition, owned []string, active []string) bool {
+	if conditions == nil {
+		return false
+	}
+	before := len(*conditions)
+	*conditions = slices.DeleteFunc(*conditions, func(c metav1.Condition) bool {
+		return slices.Contains(owned, c.Type) && !slices.Contains(active, c.Type)
+	})
+	return len(*conditions) != before
+}
+
+// Owned returns the conditions whose type is owned by kgame, like to write only
+// them with server side apply
+func Owned(conditions []metav1.Condition, owned ...string) []metav1.Condition {
+	var result []metav1.Condition
+	for _, c := range conditions {
+		if slices.Contains(owned, c.Type) {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// Tracker records the conditions set during a reconciliation, so the conditions
+// owned by kgame that were not set on this reconciliation can be pruned
+type Tracker struct {
+	conditions *[]metav1.Condition
+	owned      []string
+	active     []string
+}
+
+// NewTracker returns a Tracker for conditions. owned is the list of condition
+// types that are managed by kgame and are candidates for pruning
+func NewTracker(conditions *[]metav1.Condition, owned ...string) *Tracker {
+	return &Tracker{
+		conditions: conditions,
+		owned:      owned,
+	}
+}
+
+// Set sets the condition and marks its type as active
+func (t *Tracker) Set(condition metav1.Condition) bool {
+	if !slices.Contains(t.active, condition.Type) {
+		t.active = append(t.active, condition.Type)
+	}
+	return Set(t.conditions, condition)
+}
+
+// Prune removes all the owned conditions that were not set through this Tracker
+func (t *Tracker) Prune() bool {
+	return Prune(t.conditions, t.owned, t.active)
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conditions
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// Polarity defines which status of a condition represents a healthy resource
+type Polarity int
+
+const (
+	// PositivePolarity conditions are healthy when True, like Accepted
+	PositivePolarity Polarity = iota
+	// NegativePolarity conditions are healthy when False, like Conflicted
+	NegativePolarity
+)
+
+// negativePolarity are the Gateway API conditions that represent a problem when
+// True
+var negativePolarity = map[string]struct{}{
+	string(gatewayv1.ListenerConditionConflicted):           {},
+	string(gatewayv1.ListenerConditionDetached):             {},
+	string(gatewayv1.ListenerConditionOverlappingTLSConfig): {},
+}
+
+// PolarityOf returns the polarity of the condition type
+func PolarityOf(conditionType string) Polarity {
+	if _, ok := negativePolarity[conditionType]; ok {
+		return NegativePolarity
+	}
+	return PositivePolarity
+}
+
+// HealthyStatus returns the status that represents a healthy resource for the
+// condition type
+func HealthyStatus(conditionType string) metav1.ConditionStatus {
+	if PolarityOf(conditionType) == NegativePolarity {
+		return metav1.ConditionFalse
+	}
+	return metav1.ConditionTrue
+}
+
+// IsHealthy returns true if the condition status represents a healthy resource,
+// according to the polarity of the condition. Unknown is never healthy
+func IsHealthy(condition metav1.Condition) bool {
+	return condition.Status == HealthyStatus(condition.Type)
+}
+
+// Healthy returns a condition of conditionType with the healthy status for its
+// polarity, like Accepted=True or Conflicted=False
+func Healthy(conditionType string, reason string, message string, generation int64) metav1.Condition {
+	return metav1.Condition{
+		Type:               conditionType,
+		Status:             HealthyStatus(conditionType),
+		Reason:             reason,
+		Message:            message,
+		LastTransitionTime: metav1.Now(),
+		ObservedGeneration: generation,
+	}
+}
+
+// SetDefaults sets each of the defaults conditions that does not exist yet on
+// conditions, keeping the existing ones untouched. It returns true if conditions
+// was modified
+func SetDefaults(conditions *[]metav1.Condition, defaults ...metav1.Condition) bool {
+	if conditions == nil {
+		return false
+	}
+	var changed bool
+	for _, condition := range defaults {
+		if meta.FindStatusCondition(*conditions, condition.Type) != nil {
+			continue
+		}
+		changed = Set(conditions, condition) || changed
+	}
+	return changed
+}
+
+// Equal returns true if the conditions have the same type, status, reason, message
+// and observed generation, ignoring the LastTransitionTime
+func Equal(a, b metav1.Condition) bool {
+	return a.Type == b.Type &&
+		a.Status == b.Status &&
+		a.Reason == b.Reason &&
+		a.Message == b.Message &&
+		a.ObservedGeneration == b.ObservedGeneration
+}
+
+// EqualHealth returns true if the conditions have the same type and both represent
+// the same health according to the condition polarity, so a Conflicted=False
+// condition can be asserted as healthy without caring about its reason
+func EqualHealth(a, b metav1.Condition) bool {
+	return a.Type == b.Type && IsHealthy(a) == IsHealthy(b)
+}
@@ -0,0 +1,315 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The package config loads the kgame ControllerOptions from a YAML or JSON file,
+// so deployments can be configured without code changes. Only the options that
+// can be represented on a file are supported; hooks and other functions are
+// still set with code, like:
+//
+//	cfg, err := config.Load("/etc/kgame/config.yaml")
+//	...
+//	ctr, err := controllers.NewController(cfg.Option(), controllers.WithGatewayHooks(h))
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/rikatz/kgame/pkg/controllers"
+	"github.com/rikatz/kgame/pkg/writer"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/yaml"
+)
+
+// Feature gates supported by the configuration file
+const (
+	// WaitForRoutesOnDelete enables the GatewayOptions WaitForRoutesOnDelete
+	WaitForRoutesOnDelete = "WaitForRoutesOnDelete"
+	// AdmissionWebhooks enables the admission webhooks
+	AdmissionWebhooks = "AdmissionWebhooks"
+)
+
+var featureGates = []string{
+	WaitForRoutesOnDelete,
+	AdmissionWebhooks,
+}
+
+// Config is the configuration file. Unset fields keep the ControllerOptions
+// defaults
+type Config struct {
+	ControllerClass string `json:"controllerClass,omitempty"`
+	ControllerName  string `json:"controllerName,omitempty"`
+	// ControllerClasses are additional GatewayClass controllerNames managed by the
+	// instance
+	ControllerClasses []string `json:"controllerClasses,omitempty"`
+	// Namespaces limits the watched namespaces
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Selectors are the label selectors of each kind, like "team=edge"
+	Selectors Selectors `json:"selectors,omitempty"`
+	// SyncPeriod is the period of the full resync of the caches, like "10h"
+	SyncPeriod metav1.Duration `json:"syncPeriod,omitempty"`
+	// DisabledControllers are the kinds of the controllers not started
+	DisabledControllers []controllers.Kind `json:"disabledControllers,omitempty"`
+	// FeatureGates enables or disables the optional behaviors, by name
+	FeatureGates map[string]bool `json:"featureGates,omitempty"`
+
+	LeaderElection LeaderElection `json:"leaderElection,omitempty"`
+	Metrics        Metrics        `json:"metrics,omitempty"`
+	Probes         Probes         `json:"probes,omitempty"`
+	Webhooks       Webhooks       `json:"webhooks,omitempty"`
+	// PprofBindAddress is the address of the pprof endpoints
+	PprofBindAddress string `json:"pprofBindAddress,omitempty"`
+	// FieldManager is the field manager of the kgame writes
+	FieldManager string `json:"fieldManager,omitempty"`
+	// WriteStrategy is the strategy of the status writes, one of "MergePatch",
+	// "Update" or "ServerSideApply"
+	WriteStrategy writer.Strategy `json:"writeStrategy,omitempty"`
+
+	GatewayClass GatewayClass `json:"gatewayClass,omitempty"`
+	Gateway      Gateway      `json:"gateway,omitempty"`
+	HTTPRoute    HTTPRoute    `json:"httpRoute,omitempty"`
+}
+
+// Selectors are the label selectors of each kind
+type Selectors struct {
+	GatewayClass string `json:"gatewayClass,omitempty"`
+	Gateway      string `json:"gateway,omitempty"`
+	HTTPRoute    string `json:"httpRoute,omitempty"`
+}
+
+// LeaderElection configures the leader election
+type LeaderElection struct {
+	Enabled         bool            `json:"enabled,omitempty"`
+	Namespace       string          `json:"namespace,omitempty"`
+	Name            string          `json:"name,omitempty"`
+	LeaseDuration   metav1.Duration `json:"leaseDuration,omitempty"`
+	RenewDeadline   metav1.Duration `json:"renewDeadline,omitempty"`
+	RetryPeriod     metav1.Duration `json:"retryPeriod,omitempty"`
+	ReleaseOnCancel bool            `json:"releaseOnCancel,omitempty"`
+}
+
+// Metrics configures the metrics server
+type Metrics struct {
+	BindAddress   string `json:"bindAddress,omitempty"`
+	SecureServing bool   `json:"secureServing,omitempty"`
+	CertDir       string `json:"certDir,omitempty"`
+}
+
+// Probes configures the health and readiness probes
+type Probes struct {
+	BindAddress   string `json:"bindAddress,omitempty"`
+	RequireLeader bool   `json:"requireLeader,omitempty"`
+}
+
+// Webhooks configures the admission webhooks server. The webhooks are enabled by
+// the AdmissionWebhooks feature gate
+type Webhooks struct {
+	Port    int    `json:"port,omitempty"`
+	CertDir string `json:"certDir,omitempty"`
+}
+
+// Controller are the settings shared by all the controllers
+type Controller struct {
+	MaxConcurrentReconciles int             `json:"maxConcurrentReconciles,omitempty"`
+	ResyncPeriod            metav1.Duration `json:"resyncPeriod,omitempty"`
+	HookTimeout             metav1.Duration `json:"hookTimeout,omitempty"`
+}
+
+// GatewayClass configures the GatewayClass controller
+type GatewayClass struct {
+	Controller             `json:",inline"`
+	FinalizerName          string          `json:"finalizerName,omitempty"`
+	PendingRequeueInterval metav1.Duration `json:"pendingRequeueInterval,omitempty"`
+}
+
+// Gateway configures the Gateway controller
+type Gateway struct {
+	Controller        `json:",inline"`
+	FinalizerName     string          `json:"finalizerName,omitempty"`
+	RouteDrainTimeout metav1.Duration `json:"routeDrainTimeout,omitempty"`
+	AnnotationPrefix  string          `json:"annotationPrefix,omitempty"`
+}
+
+// HTTPRoute configures the HTTPRoute controller
+type HTTPRoute struct {
+	Controller `json:",inline"`
+}
+
+// Load reads and validates the configuration file on path. Unknown fields are
+// rejected, so typos are not silently ignored
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading the configuration file: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse parses and validates a YAML or JSON configuration
+func Parse(data []byte) (*Config, error) {
+	cfg := &Config{}
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return nil, fmt.Errorf("error parsing the configuration: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Validate returns all the invalid fields of the configuration, joined. The
+// resulting ControllerOptions are validated again by NewController
+func (c *Config) Validate() error {
+	var errs []error
+	for _, gate := range slices.Sorted(maps.Keys(c.FeatureGates)) {
+		if !slices.Contains(featureGates, gate) {
+			errs = append(errs, fmt.Errorf("unknown feature gate %q, supported gates are %v", gate, featureGates))
+		}
+	}
+	selectors := []struct {
+		field    string
+		selector string
+	}{
+		{"selectors.gatewayClass", c.Selectors.GatewayClass},
+		{"selectors.gateway", c.Selectors.Gateway},
+		{"selectors.httpRoute", c.Selectors.HTTPRoute},
+	}
+	for _, selector := range selectors {
+		if _, err := parseSelector(selector.selector); err != nil {
+			errs = append(errs, fmt.Errorf("invalid %s: %w", selector.field, err))
+		}
+	}
+	if err := (writer.Options{Strategy: c.WriteStrategy}).Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("invalid writeStrategy: %w", err))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	return nil
+}
+
+// Option returns the Option applying the configuration over the ControllerOptions.
+// Options set after it take precedence
+func (c *Config) Option() controllers.Option {
+	return controllers.OptionFunc(c.ApplyTo)
+}
+
+// ApplyTo sets the configured fields on opts, keeping the fields not configured
+func (c *Config) ApplyTo(opts *controllers.ControllerOptions) {
+	setString(&opts.ControllerClass, c.ControllerClass)
+	setString(&opts.ControllerName, c.ControllerName)
+	opts.ControllerClasses = append(opts.ControllerClasses, c.ControllerClasses...)
+	opts.Namespaces = append(opts.Namespaces, c.Namespaces...)
+	opts.DisabledControllers = append(opts.DisabledControllers, c.DisabledControllers...)
+	setDuration(&opts.SyncPeriod, c.SyncPeriod)
+	setString(&opts.PprofBindAddress, c.PprofBindAddress)
+	setString(&opts.Writes.FieldManager, c.FieldManager)
+	if c.WriteStrategy != "" {
+		opts.Writes.Strategy = c.WriteStrategy
+	}
+
+	// Selectors are validated by Validate
+	if selector, _ := parseSelector(c.Selectors.GatewayClass); selector != nil {
+		opts.Selectors.GatewayClass = selector
+	}
+	if selector, _ := parseSelector(c.Selectors.Gateway); selector != nil {
+		opts.Selectors.Gateway = selector
+	}
+	if selector, _ := parseSelector(c.Selectors.HTTPRoute); selector != nil {
+		opts.Selectors.HTTPRoute = selector
+	}
+
+	if c.LeaderElection.Enabled {
+		opts.LeaderElection.Enabled = true
+	}
+	setString(&opts.LeaderElection.Namespace, c.LeaderElection.Namespace)
+	setString(&opts.LeaderElection.Name, c.LeaderElection.Name)
+	setDuration(&opts.LeaderElection.LeaseDuration, c.LeaderElection.LeaseDuration)
+	setDuration(&opts.LeaderElection.RenewDeadline, c.LeaderElection.RenewDeadline)
+	setDuration(&opts.LeaderElection.RetryPeriod, c.LeaderElection.RetryPeriod)
+	if c.LeaderElection.ReleaseOnCancel {
+		opts.LeaderElection.ReleaseOnCancel = true
+	}
+
+	setString(&opts.Metrics.BindAddress, c.Metrics.BindAddress)
+	if c.Metrics.SecureServing {
+		opts.Metrics.SecureServing = true
+	}
+	setString(&opts.Metrics.CertDir, c.Metrics.CertDir)
+	setString(&opts.Probes.BindAddress, c.Probes.BindAddress)
+	if c.Probes.RequireLeader {
+		opts.Probes.RequireLeader = true
+	}
+
+	if enabled, ok := c.FeatureGates[AdmissionWebhooks]; ok {
+		opts.Webhooks.Enabled = enabled
+	}
+	if c.Webhooks.Port != 0 {
+		opts.Webhooks.Port = c.Webhooks.Port
+	}
+	setString(&opts.Webhooks.CertDir, c.Webhooks.CertDir)
+
+	setInt(&opts.GatewayClassOptions.MaxConcurrentReconciles, c.GatewayClass.MaxConcurrentReconciles)
+	setDuration(&opts.GatewayClassOptions.ResyncPeriod, c.GatewayClass.ResyncPeriod)
+	setDuration(&opts.GatewayClassOptions.HookTimeout, c.GatewayClass.HookTimeout)
+	setString(&opts.GatewayClassOptions.FinalizerName, c.GatewayClass.FinalizerName)
+	setDuration(&opts.GatewayClassOptions.PendingRequeueInterval, c.GatewayClass.PendingRequeueInterval)
+
+	setInt(&opts.GatewayOptions.MaxConcurrentReconciles, c.Gateway.MaxConcurrentReconciles)
+	setDuration(&opts.GatewayOptions.ResyncPeriod, c.Gateway.ResyncPeriod)
+	setDuration(&opts.GatewayOptions.HookTimeout, c.Gateway.HookTimeout)
+	setString(&opts.GatewayOptions.FinalizerName, c.Gateway.FinalizerName)
+	setDuration(&opts.GatewayOptions.RouteDrainTimeout, c.Gateway.RouteDrainTimeout)
+	setString(&opts.GatewayOptions.AnnotationPrefix, c.Gateway.AnnotationPrefix)
+	if enabled, ok := c.FeatureGates[WaitForRoutesOnDelete]; ok {
+		opts.GatewayOptions.WaitForRoutesOnDelete = enabled
+	}
+
+	setInt(&opts.HTTPRouteOptions.MaxConcurrentReconciles, c.HTTPRoute.MaxConcurrentReconciles)
+	setDuration(&opts.HTTPRouteOptions.ResyncPeriod, c.HTTPRoute.ResyncPeriod)
+	setDuration(&opts.HTTPRouteOptions.HookTimeout, c.HTTPRoute.HookTimeout)
+}
+
+// parseSelector parses a label selector, returning nil for an empty one
+func parseSelector(selector string) (labels.Selector, error) {
+	if selector == "" {
+		return nil, nil
+	}
+	return labels.Parse(selector)
+}
+
+func setString(target *string, value string) {
+	if value != "" {
+		*target = value
+	}
+}
+
+func setInt(target *int, value int) {
+	if value != 0 {
+		*target = value
+	}
+}
+
+func setDuration(target *time.Duration, value metav1.Duration) {
+	if value.Duration != 0 {
+		*target = value.Duration
+	}
+}
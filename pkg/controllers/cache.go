@@ -0,0 +1,166 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"reflect"
+
+	"github.com/rikatz/kgame/pkg/attachment"
+	"github.com/rikatz/kgame/pkg/provisioner"
+	"github.com/rikatz/kgame/pkg/tunables"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// CacheOptions returns the cache options required by the kgame controllers, like
+// dropping the GatewayClasses of other controllers from the cache. Managers passed
+// to SetupAllWithManager must be created with them, merging any ByObject entry of
+// their own
+func CacheOptions(options ...Option) cache.Options {
+	opts := &ControllerOptions{}
+	for _, option := range options {
+		if option != nil {
+			option.apply(opts)
+		}
+	}
+	setDefaults(opts)
+	transformFunc := tunables.NewTunables(tunables.TunableConfig{
+		Logger:                      opts.logger(),
+		GatewayClassName:            gatewayv1.GatewayController(opts.ControllerClass),
+		AdditionalGatewayClassNames: opts.additionalControllerNames(),
+	})
+	gatewayTransform := cache.TransformStripManagedFields()
+	if opts.DropUnmanagedGateways {
+		gatewayTransform = transformFunc.TransformGateway()
+	}
+	cacheOptions := cache.Options{
+		ByObject: map[client.Object]cache.ByObject{
+			&gatewayv1.GatewayClass{}: {
+				Label:     opts.Selectors.GatewayClass,
+				Transform: transformFunc.TransformGatewayClass(),
+			},
+			&gatewayv1.Gateway{}: {
+				Label:     opts.Selectors.Gateway,
+				Transform: gatewayTransform,
+			},
+			&gatewayv1.HTTPRoute{}: {
+				Label:     opts.Selectors.HTTPRoute,
+				Transform: tunables.TransformRoute(opts.RouteCache),
+			},
+			// kgame only reads the Namespaces metadata, see attachment.NamespaceLabels
+			attachment.NamespaceMetadata(): {
+				Transform: tunables.StripManagedFields(),
+			},
+		},
+	}
+	if opts.Provisioner != nil {
+		// Only the provisioned Deployments, DaemonSets, HorizontalPodAutoscalers,
+		// PodDisruptionBudgets, ServiceAccounts, Roles, RoleBindings and dataplane
+		// Pods are cached. The Services are cached fully, as backends, and the
+		// ConfigMaps, as parameters
+		managed := labels.SelectorFromSet(labels.Set{provisioner.ManagedByLabel: provisioner.ManagedByValue})
+		cacheOptions.ByObject[&appsv1.Deployment{}] = cache.ByObject{Label: managed}
+		cacheOptions.ByObject[&appsv1.DaemonSet{}] = cache.ByObject{Label: managed}
+		cacheOptions.ByObject[&corev1.Pod{}] = cache.ByObject{Label: managed}
+		if opts.Provisioner.Autoscaling != nil {
+			cacheOptions.ByObject[&autoscalingv2.HorizontalPodAutoscaler{}] = cache.ByObject{Label: managed}
+		}
+		if opts.Provisioner.Disruption != nil {
+			cacheOptions.ByObject[&policyv1.PodDisruptionBudget{}] = cache.ByObject{Label: managed}
+		}
+		if opts.Provisioner.ServiceAccount != nil {
+			cacheOptions.ByObject[&corev1.ServiceAccount{}] = cache.ByObject{Label: managed}
+			cacheOptions.ByObject[&rbacv1.Role{}] = cache.ByObject{Label: managed}
+			cacheOptions.ByObject[&rbacv1.RoleBinding{}] = cache.ByObject{Label: managed}
+		}
+	}
+	if opts.WatchErrors != nil {
+		cacheOptions.DefaultWatchErrorHandler = opts.WatchErrors.handle
+	}
+	opts.SecretCache.addByObject(cacheOptions.ByObject)
+	mergeByObject(cacheOptions.ByObject, opts.ByObject)
+	if opts.SyncPeriod > 0 {
+		cacheOptions.SyncPeriod = &opts.SyncPeriod
+	}
+	if len(opts.Namespaces) > 0 {
+		cacheOptions.DefaultNamespaces = make(map[string]cache.Config, len(opts.Namespaces))
+		for _, namespace := range opts.Namespaces {
+			cacheOptions.DefaultNamespaces[namespace] = cache.Config{}
+		}
+	}
+	return cacheOptions
+}
+
+// WithByObject sets the cache configuration of the kind of obj, like
+// WithByObject(&gatewayv1.Gateway{}, cache.ByObject{Field: selector}). See
+// ControllerOptions.ByObject
+func WithByObject(obj client.Object, byObject cache.ByObject) Option {
+	return OptionFunc(func(opts *ControllerOptions) {
+		if opts.ByObject == nil {
+			opts.ByObject = make(map[client.Object]cache.ByObject)
+		}
+		opts.ByObject[obj] = byObject
+	})
+}
+
+// mergeByObject merges the ByObject of the options over the kgame ones. The
+// entries of the same kind are found by their Go type and their kind, set on the
+// metadata only objects, as the map keys are pointers
+func mergeByObject(byObject, options map[client.Object]cache.ByObject) {
+	for obj, config := range options {
+		key, current := obj, cache.ByObject{}
+		for existing, existingConfig := range byObject {
+			if reflect.TypeOf(existing) == reflect.TypeOf(obj) && existing.GetObjectKind().GroupVersionKind() == obj.GetObjectKind().GroupVersionKind() {
+				key, current = existing, existingConfig
+				break
+			}
+		}
+		byObject[key] = mergeCacheConfig(current, config)
+	}
+}
+
+// mergeCacheConfig returns current with the fields set on config. The config
+// Transform is called after the current one, so it cannot bring back the objects
+// dropped by kgame
+func mergeCacheConfig(current, config cache.ByObject) cache.ByObject {
+	if config.Namespaces != nil {
+		current.Namespaces = config.Namespaces
+	}
+	if config.Label != nil {
+		current.Label = config.Label
+	}
+	if config.Field != nil {
+		current.Field = config.Field
+	}
+	if config.Transform != nil {
+		current.Transform = tunables.Chain(current.Transform, config.Transform)
+	}
+	if config.UnsafeDisableDeepCopy != nil {
+		current.UnsafeDisableDeepCopy = config.UnsafeDisableDeepCopy
+	}
+	if config.EnableWatchBookmarks != nil {
+		current.EnableWatchBookmarks = config.EnableWatchBookmarks
+	}
+	return current
+}
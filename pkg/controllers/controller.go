@@ -2,19 +2,45 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
+	configv1alpha1 "github.com/rikatz/kgame/pkg/apis/config/v1alpha1"
 	"github.com/rikatz/kgame/pkg/controllers/gateway"
 	"github.com/rikatz/kgame/pkg/controllers/gatewayclass"
+	"github.com/rikatz/kgame/pkg/controllers/httproute"
+	"github.com/rikatz/kgame/pkg/health"
+	"github.com/rikatz/kgame/pkg/ir"
+	"github.com/rikatz/kgame/pkg/logging"
+	"github.com/rikatz/kgame/pkg/notify"
+	"github.com/rikatz/kgame/pkg/provisioner"
+	"github.com/rikatz/kgame/pkg/sharding"
 	"github.com/rikatz/kgame/pkg/tunables"
+	"github.com/rikatz/kgame/pkg/webhooks"
+	"github.com/rikatz/kgame/pkg/writer"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 )
 
 var (
@@ -22,8 +48,48 @@ var (
 )
 
 type Controller struct {
-	mgr    ctrl.Manager
-	logger logr.Logger
+	mgr            ctrl.Manager
+	logger         logr.Logger
+	controllerName gatewayv1.GatewayController
+	policyKinds    []schema.GroupVersionKind
+	triggers       triggers
+	controllers    map[Kind]controller.Controller
+	featureGates   *featureGates
+
+	// additionalControllerNames are the ControllerClasses
+	additionalControllerNames []gatewayv1.GatewayController
+
+	reporter        *health.Reporter
+	onShutdown      []func(ctx context.Context)
+	shutdownTimeout time.Duration
+
+	// options are the NewController options, reused by Restart. Restartable is
+	// false for the controllers set up on an existing manager
+	options     []Option
+	restartable bool
+
+	waitForCacheSync bool
+	// ready is closed once the caches are synced
+	ready chan struct{}
+
+	mu sync.Mutex
+	// stop cancels the context of Start, and done is closed once the manager is
+	// stopped, with its error on err
+	stop context.CancelFunc
+	done chan struct{}
+	err  error
+}
+
+// ClusterSnapshot is the intermediate representation of all the managed
+// resources, at the moment it was taken
+type ClusterSnapshot = ir.Graph
+
+// ResourceSelectors are label selectors of each kind managed by kgame. An empty
+// selector matches all the resources of the kind
+type ResourceSelectors struct {
+	GatewayClass labels.Selector
+	Gateway      labels.Selector
+	HTTPRoute    labels.Selector
 }
 
 type ControllerOptions struct {
@@ -31,17 +97,308 @@ type ControllerOptions struct {
 	ControllerName      string
 	GatewayClassOptions gatewayclass.GatewayClassOptions
 	GatewayOptions      gateway.GatewayOptions
+	HTTPRouteOptions    httproute.HTTPRouteOptions
+	// ControllerClasses are additional GatewayClass controllerNames managed by the
+	// same instance, like "mylab.tld/internal" and "mylab.tld/internet-facing".
+	// Each managed class can be programmed differently, see
+	// gateway.ClassProgrammers
+	ControllerClasses []string
+	// Logger is the logger of the kgame controllers, like the logger of the host
+	// program. Defaults to klog
+	Logger logr.Logger
+	// SetGlobalLogger sets the Logger as the controller-runtime global logger on
+	// NewController, for programs that do not set up their own. Otherwise
+	// controller-runtime warns about the global logger not being set, if anything
+	// logs through it
+	SetGlobalLogger bool
+	// Logging creates the Logger with the given format and verbosities, when the
+	// Logger is empty. The verbosities can be changed at runtime through the
+	// KgameConfig. Defaults to klog
+	Logging *logging.Options
+	// OnShutdown are called by Start once the manager is stopped, like when the
+	// program receives a SIGTERM, to clean up the dataplane. They run after the
+	// in-flight reconciliations are drained and the status report is flushed,
+	// sharing a context bounded by the ShutdownTimeout
+	OnShutdown []func(ctx context.Context)
+	// ShutdownTimeout is the maximum time to drain the in-flight reconciliations
+	// when stopping, and then to run the OnShutdown hooks. Defaults to 30 seconds
+	ShutdownTimeout time.Duration
+	// WaitForCacheSync makes Start return once the caches are synced, instead of
+	// blocking until the controller is stopped. The manager keeps running in the
+	// background, see Controller.Wait for its error
+	WaitForCacheSync bool
+	// RestConfig is the configuration of the cluster kgame connects to, like the
+	// envtest one or one returned by RestConfigFromKubeconfig. Defaults to the
+	// controller-runtime configuration, from the --kubeconfig flag, the
+	// KUBECONFIG environment variable or the in-cluster configuration
+	RestConfig *rest.Config
+	// Client configures the throttling of the requests to the API server
+	Client ClientOptions
+	// WatchErrors tracks the list and watch failures of the informers, like RBAC
+	// gaps or deleted CRDs. Only logged by default
+	WatchErrors *WatchErrors
+	// Provisioner creates and reconciles a Deployment, Service and ConfigMap for
+	// the dataplane of each accepted Gateway, and provides the Gateway addresses
+	// from the Service when the GatewayOptions have no AddressProviderFunc. The
+	// Gateways are Pending until the rollout of their dataplane finishes, when
+	// the GatewayOptions have no DataplaneReadyFunc. The listeners whose host
+	// ports are used by an older dataplane on the same nodes are rejected. The
+	// rollout of the Provisioner ClassImageFunc images is reported on the
+	// GatewayClass DataplaneRolledOut condition, when the GatewayClassOptions
+	// have no RolloutStatusFunc. Disabled when empty. Managers passed to
+	// SetupAllWithManager must have the apps/v1 types on their scheme, the
+	// autoscaling/v2 types with the Provisioner Autoscaling, the policy/v1 types
+	// with the Provisioner Disruption and the rbac/v1 types with the
+	// Provisioner ServiceAccount
+	Provisioner *provisioner.Options
+	// Sharding partitions the reconciliations across active replicas, each one
+	// reconciling the resources whose namespace, or name, hash matches its
+	// ordinal. All the replicas run the same Sharding.Replicas, and may still use
+	// the LeaderElection, electing a leader for each ordinal. Disabled by default
+	Sharding sharding.Options
+	// LeaderElection configures the leader election of the manager created by
+	// NewController. Disabled by default, so running more than one replica
+	// programs the dataplanes more than once
+	LeaderElection LeaderElectionOptions
+	// Metrics configures the metrics server of the manager created by
+	// NewController
+	Metrics MetricsOptions
+	// Probes configures the health and readiness probes
+	Probes ProbeOptions
+	// PprofBindAddress is the address serving the pprof endpoints of the manager
+	// created by NewController, like "127.0.0.1:6060", to profile the
+	// reconciliations and the caches memory. Disabled when empty
+	PprofBindAddress string
+	// Namespaces limits the Gateways, routes and their referenced resources watched
+	// by kgame to the given namespaces, so it can run with namespaced RBAC. The
+	// GatewayClasses and Namespaces are cluster scoped and are still watched.
+	// Defaults to all the namespaces
+	Namespaces []string
+	// Selectors limits the resources cached by kgame to the ones matching the
+	// label selector of their kind. The resources filtered out are invisible to
+	// kgame, and are never reconciled
+	Selectors ResourceSelectors
+	// SyncPeriod is the period of the full resync of the informer caches, when
+	// all the cached resources are reconciled again. Defaults to the
+	// controller-runtime one, of 10 hours. See also the ResyncPeriod of each
+	// controller, to reconcile its resources more often
+	SyncPeriod time.Duration
+	// DisabledControllers are the controllers not started by kgame, like the
+	// Gateway controller when the implementer reconciles the Gateways on its own.
+	// TriggerReconcile and WatchAdditional return an error for the disabled kinds
+	DisabledControllers []Kind
+	// AddToScheme registers additional types on the scheme of the manager created
+	// by NewController, like the implementer parameters and policy CRDs, so they
+	// can be read by the hooks through the manager client. Both the generated
+	// AddToScheme functions and runtime.SchemeBuilder.AddToScheme are accepted
+	AddToScheme []func(*runtime.Scheme) error
+	// ByObject configures the cache of each kind, like field selectors of the
+	// kgame kinds or the cache of the implementer CRDs. On the kinds configured by
+	// kgame, the fields set override the kgame ones, and the Transform is chained
+	// after the kgame one
+	ByObject map[client.Object]cache.ByObject
+	// DropUnmanagedGateways drops from the cache the Gateways of the GatewayClasses
+	// not managed by kgame, like on clusters with many Gateways of other
+	// implementations. A GatewayClass recreated with the kgame controllerName
+	// only gets its existing Gateways back on their next change
+	DropUnmanagedGateways bool
+	// RouteCache drops from the cache the routes that cannot target a managed
+	// Gateway, like the mesh routes. They are invisible to kgame, and are never
+	// reconciled. Defaults to caching all the routes
+	RouteCache tunables.RouteTransformOptions
+	// SecretCache configures the cache of the Secrets, like to only keep their TLS
+	// data. Defaults to caching the whole Secrets
+	SecretCache SecretCacheOptions
+	// FeatureGates are the implementer feature gates, by name, read by the hooks
+	// through Controller.FeatureEnabled. They can be changed at runtime through the
+	// KgameConfig
+	FeatureGates map[string]bool
+	// ConfigReload watches the KgameConfig resource, applying the settings that
+	// are safe to change without restarting the controllers. Disabled by default
+	ConfigReload ConfigReloadOptions
+	// StatusReport configures the ConfigMap where the controller health is
+	// reported. With the Sharding, each ordinal reports to its own ConfigMap,
+	// suffixed by the ordinal. Disabled by default
+	StatusReport health.StatusOptions
+	// Notify receives the notifications of all the controllers that do not set
+	// their own. See notify.Channel to receive them on a channel
+	Notify notify.Func
+	// Webhooks configures the admission webhooks rejecting the resources that use
+	// capabilities not supported by the implementation. Disabled by default
+	Webhooks webhooks.Options
+	// ParametersKinds are the kinds of the GatewayClass and Gateway infrastructure
+	// parameters, like the implementer parameters CRD. Their objects are watched,
+	// so their changes validate the GatewayClasses referencing them again and
+	// reconcile the Gateways of those classes, or the Gateways referencing them
+	ParametersKinds []schema.GroupVersionKind
+	// PolicyKinds are the policy kinds attached to the nodes of the Snapshot.
+	// Each kind must be installed on the cluster
+	PolicyKinds []schema.GroupVersionKind
+	// Writes configures the field manager of all the kgame writes, shown on the
+	// managedFields, and the strategy of the status writes of the controllers
+	// that do not set their own. See writer.Options
+	Writes writer.Options
 }
 
 const (
-	defaultNameAndClass = "kgame"
+	defaultNameAndClass    = "kgame"
+	defaultShutdownTimeout = 30 * time.Second
 )
 
-func NewController(opts *ControllerOptions) (*Controller, error) {
-	if opts == nil {
-		return nil, fmt.Errorf("options cannot be null")
+// NewController creates the manager and sets up the kgame controllers on it, like
+// NewController(WithControllerClass("mylab.tld/gateway"), WithGatewayHooks(h)).
+// All the invalid options are returned on a single error. Use SetupAllWithManager
+// to set the controllers up on an existing manager instead
+func NewController(options ...Option) (*Controller, error) {
+	return newController(options, false)
+}
+
+// newController creates the manager and sets up the controllers on it. The
+// controller names were registered by the previous manager when restarting, so
+// their validation is skipped
+func newController(options []Option, restart bool) (*Controller, error) {
+	opts, err := buildOptions(options)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := opts.setupLogging(); err != nil {
+		return nil, err
+	}
+	logger := opts.logger()
+	if opts.SetGlobalLogger {
+		ctrl.SetLogger(logger)
 	}
 
+	if err := AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	if opts.ConfigReload.Enabled {
+		if err := configv1alpha1.AddToScheme(scheme); err != nil {
+			return nil, fmt.Errorf("failed to add the kgame config types to scheme: %w", err)
+		}
+	}
+	if opts.Provisioner != nil {
+		if err := appsv1.AddToScheme(scheme); err != nil {
+			return nil, fmt.Errorf("failed to add appsv1 to scheme: %w", err)
+		}
+		if opts.Provisioner.Autoscaling != nil {
+			if err := autoscalingv2.AddToScheme(scheme); err != nil {
+				return nil, fmt.Errorf("failed to add autoscalingv2 to scheme: %w", err)
+			}
+		}
+		if opts.Provisioner.Disruption != nil {
+			if err := policyv1.AddToScheme(scheme); err != nil {
+				return nil, fmt.Errorf("failed to add policyv1 to scheme: %w", err)
+			}
+		}
+		if opts.Provisioner.ServiceAccount != nil {
+			if err := rbacv1.AddToScheme(scheme); err != nil {
+				return nil, fmt.Errorf("failed to add rbacv1 to scheme: %w", err)
+			}
+		}
+	}
+	for _, addToScheme := range opts.AddToScheme {
+		if err := addToScheme(scheme); err != nil {
+			return nil, fmt.Errorf("failed to add the additional types to scheme: %w", err)
+		}
+	}
+
+	logger.Info("ControllerClass configured", "class", opts.ControllerClass, "additionalClasses", opts.ControllerClasses)
+
+	restConfig := opts.RestConfig
+	if restConfig == nil {
+		config, err := ctrl.GetConfig()
+		if err != nil {
+			return nil, fmt.Errorf("unable to get the kubernetes configuration: %w", err)
+		}
+		restConfig = config
+	}
+	restConfig = opts.Client.apply(restConfig)
+
+	managerOptions := ctrl.Options{
+		Scheme: scheme,
+		Logger: logger,
+		WebhookServer: webhook.NewServer(webhook.Options{
+			Port:    opts.Webhooks.Port,
+			CertDir: opts.Webhooks.CertDir,
+		}),
+		Cache:                   CacheOptions(opts),
+		Metrics:                 opts.Metrics.serverOptions(),
+		HealthProbeBindAddress:  opts.Probes.BindAddress,
+		PprofBindAddress:        opts.PprofBindAddress,
+		GracefulShutdownTimeout: &opts.ShutdownTimeout,
+		NewClient: func(config *rest.Config, options client.Options) (client.Client, error) {
+			c, err := client.New(config, options)
+			if err != nil {
+				return nil, err
+			}
+			return opts.Writes.Client(c), nil
+		},
+	}
+	opts.LeaderElection.apply(opts.ControllerName, &managerOptions)
+	if opts.Sharding.Enabled() && managerOptions.LeaderElection {
+		// Each ordinal elects its own leader
+		managerOptions.LeaderElectionID = fmt.Sprintf("%s-%d", managerOptions.LeaderElectionID, opts.Sharding.Ordinal)
+	}
+	if restart {
+		managerOptions.Controller.SkipNameValidation = ptr.To(true)
+	}
+
+	mgr, err := ctrl.NewManager(restConfig, managerOptions)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the manager, please check if the CRDs are installed: %w", err)
+	}
+	k, err := setupAll(mgr, logger, opts)
+	if err != nil {
+		return nil, err
+	}
+	k.options, k.restartable = options, true
+	return k, nil
+}
+
+// AddToScheme adds the types reconciled by kgame to s
+func AddToScheme(s *runtime.Scheme) error {
+	if err := v1.AddToScheme(s); err != nil {
+		return fmt.Errorf("failed to add corev1 to scheme: %w", err)
+	}
+
+	if err := discoveryv1.AddToScheme(s); err != nil {
+		return fmt.Errorf("failed to add discoveryv1 to scheme: %w", err)
+	}
+
+	if err := gatewayv1.Install(s); err != nil {
+		return fmt.Errorf("failed to add gatewayapiv1 to scheme: %w", err)
+	}
+
+	if err := gatewayv1beta1.Install(s); err != nil {
+		return fmt.Errorf("failed to add gatewayapiv1beta1 to scheme: %w", err)
+	}
+	return nil
+}
+
+// SetupAllWithManager sets up the kgame controllers, indexes and webhooks on an
+// existing manager, like one that already reconciles the implementer CRDs. The
+// manager must be created with the CacheOptions and a scheme with the AddToScheme
+// types, and the KgameConfig types when the ConfigReload is enabled. The Logger
+// defaults to the manager one. The manager is started by the caller, so Start
+// must not be called on the returned Controller
+func SetupAllWithManager(mgr ctrl.Manager, options ...Option) (*Controller, error) {
+	opts, err := buildOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	if err := opts.setupLogging(); err != nil {
+		return nil, err
+	}
+	logger := mgr.GetLogger().WithName(opts.ControllerName)
+	if opts.Logger.GetSink() != nil {
+		logger = opts.logger()
+	}
+	return setupAll(mgr, logger, opts)
+}
+
+func setDefaults(opts *ControllerOptions) {
 	if opts.ControllerClass == "" {
 		opts.ControllerClass = defaultNameAndClass
 	}
@@ -50,69 +407,435 @@ func NewController(opts *ControllerOptions) (*Controller, error) {
 		opts.ControllerName = defaultNameAndClass
 	}
 
-	logger := klog.NewKlogr().WithName(opts.ControllerName)
-	ctrl.SetLogger(logger)
+	if opts.ShutdownTimeout == 0 {
+		opts.ShutdownTimeout = defaultShutdownTimeout
+	}
+}
 
-	if err := v1.AddToScheme(scheme); err != nil {
-		return nil, fmt.Errorf("failed to add corev1 to scheme: %w", err)
+// setupAll sets up the kgame controllers on mgr
+func setupAll(mgr ctrl.Manager, logger logr.Logger, opts *ControllerOptions) (*Controller, error) {
+	var reporter *health.Reporter
+	if opts.StatusReport.Name != "" {
+		statusReport := opts.StatusReport
+		if opts.Sharding.Enabled() {
+			// Each ordinal reports the health of its own reconciliations
+			statusReport.Name = fmt.Sprintf("%s-%d", statusReport.Name, opts.Sharding.Ordinal)
+		}
+		reporter = health.NewReporter(mgr, statusReport)
+		if err := mgr.Add(reporter); err != nil {
+			return nil, fmt.Errorf("unable to add the status reporter: %w", err)
+		}
+		opts.GatewayClassOptions.Reporter = reporter
+		opts.GatewayOptions.Reporter = reporter
+		opts.HTTPRouteOptions.Reporter = reporter
 	}
 
-	if err := gatewayv1.Install(scheme); err != nil {
-		return nil, fmt.Errorf("failed to add gatewayapiv1 to scheme: %w", err)
+	if opts.Notify != nil {
+		if opts.GatewayClassOptions.Notify == nil {
+			opts.GatewayClassOptions.Notify = opts.Notify
+		}
+		if opts.GatewayOptions.Notify == nil {
+			opts.GatewayOptions.Notify = opts.Notify
+		}
+		if opts.HTTPRouteOptions.Notify == nil {
+			opts.HTTPRouteOptions.Notify = opts.Notify
+		}
 	}
 
-	tunablesConfig := tunables.TunableConfig{
-		Logger:           logger,
-		GatewayClassName: gatewayv1.GatewayController(opts.ControllerClass),
+	if opts.Writes != (writer.Options{}) {
+		if opts.GatewayClassOptions.Writes == (writer.Options{}) {
+			opts.GatewayClassOptions.Writes = opts.Writes
+		}
+		if opts.GatewayOptions.Writes == (writer.Options{}) {
+			opts.GatewayOptions.Writes = opts.Writes
+		}
+		if opts.HTTPRouteOptions.Writes == (writer.Options{}) {
+			opts.HTTPRouteOptions.Writes = opts.Writes
+		}
 	}
 
-	logger.Info("ControllerClass configured", "class", opts.ControllerClass)
+	if opts.Sharding.Enabled() {
+		opts.GatewayClassOptions.Sharding = &opts.Sharding
+		opts.GatewayOptions.Sharding = &opts.Sharding
+		opts.HTTPRouteOptions.Sharding = &opts.Sharding
+	}
 
-	transformFunc := tunables.NewTunables(tunablesConfig)
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
-		Scheme: scheme,
-		Logger: logger,
-		Cache: cache.Options{
-			ByObject: map[client.Object]cache.ByObject{
-				&gatewayv1.GatewayClass{}: {
-					Transform: transformFunc.TransformGatewayClass(),
-				},
-				&gatewayv1.Gateway{}: {
-					Transform: cache.TransformStripManagedFields(),
-				},
-				&gatewayv1.HTTPRoute{}: {
-					Transform: cache.TransformStripManagedFields(),
-				},
-			},
-		},
-	})
-	if err != nil {
-		return nil, fmt.Errorf("unable to create the manager, please check if the CRDs are installed: %w", err)
+	if len(opts.ParametersKinds) > 0 {
+		opts.GatewayClassOptions.ParametersKinds = append(opts.GatewayClassOptions.ParametersKinds, opts.ParametersKinds...)
+		opts.GatewayOptions.ParametersKinds = append(opts.GatewayOptions.ParametersKinds, opts.ParametersKinds...)
+	}
+
+	if err := addProbes(mgr, opts); err != nil {
+		return nil, err
+	}
+	if err := opts.Metrics.addCacheMetrics(mgr); err != nil {
+		return nil, fmt.Errorf("unable to add the cache metrics: %w", err)
+	}
+
+	reloadable := make(map[Kind]reloadableController)
+	if opts.ConfigReload.Enabled {
+		opts.GatewayClassOptions.Settings = newReloadSettings(opts.ConfigReload, opts.GatewayClassOptions.MaxConcurrentReconciles, opts.GatewayClassOptions.ResyncPeriod)
+		opts.GatewayOptions.Settings = newReloadSettings(opts.ConfigReload, opts.GatewayOptions.MaxConcurrentReconciles, opts.GatewayOptions.ResyncPeriod)
+		opts.HTTPRouteOptions.Settings = newReloadSettings(opts.ConfigReload, opts.HTTPRouteOptions.MaxConcurrentReconciles, opts.HTTPRouteOptions.ResyncPeriod)
 	}
 
-	if err := gatewayclass.SetupWithManager(mgr, opts.GatewayClassOptions); err != nil {
-		return nil, fmt.Errorf("unable to add gatewayclass controller: %w", err)
+	triggers := newTriggers()
+	controllers := make(map[Kind]controller.Controller)
+	if !opts.disabled(KindGatewayClass) {
+		opts.GatewayClassOptions.Trigger = triggers[KindGatewayClass]
+		if opts.Provisioner != nil && opts.Provisioner.ClassImageFunc != nil && opts.GatewayClassOptions.RolloutStatusFunc == nil {
+			opts.GatewayClassOptions.RolloutStatusFunc = provisioner.ClassRolloutStatus(mgr.GetClient(), *opts.Provisioner)
+		}
+		gatewayClassController, err := gatewayclass.BuildWithManager(mgr, opts.GatewayClassOptions)
+		if err != nil {
+			return nil, fmt.Errorf("unable to add gatewayclass controller: %w", err)
+		}
+		controllers[KindGatewayClass] = gatewayClassController
+		if opts.Provisioner != nil && opts.Provisioner.ClassImageFunc != nil {
+			if err := provisioner.WatchClassRollouts(mgr, gatewayClassController); err != nil {
+				return nil, fmt.Errorf("unable to watch the provisioned workloads: %w", err)
+			}
+		}
+		if opts.GatewayClassOptions.Settings != nil {
+			reloadable[KindGatewayClass] = reloadableController{
+				settings:                opts.GatewayClassOptions.Settings,
+				maxConcurrentReconciles: opts.GatewayClassOptions.MaxConcurrentReconciles,
+				resyncPeriod:            opts.GatewayClassOptions.ResyncPeriod,
+			}
+		}
 	}
 
-	if err := gateway.SetupWithManager(mgr, opts.GatewayOptions); err != nil {
-		return nil, fmt.Errorf("unable to add gatewayclass controller: %w", err)
+	if !opts.disabled(KindGateway) {
+		opts.GatewayOptions.Trigger = triggers[KindGateway]
+		if opts.Provisioner != nil && opts.GatewayOptions.AddressProviderFunc == nil {
+			opts.GatewayOptions.AddressProviderFunc = provisioner.AddressProvider(mgr.GetClient())
+		}
+		if opts.Provisioner != nil && opts.GatewayOptions.DataplaneReadyFunc == nil {
+			opts.GatewayOptions.DataplaneReadyFunc = provisioner.RolloutStatus(mgr.GetClient())
+		}
+		if opts.Provisioner != nil && opts.Provisioner.MergeGateways {
+			opts.GatewayOptions.ValidateGatewayFunc = validateListenerConflicts(provisioner.ConflictingListeners(mgr.GetClient()), opts.GatewayOptions.ValidateGatewayFunc, logger)
+		}
+		if opts.Provisioner != nil && opts.Provisioner.ExposesHostPorts() {
+			opts.GatewayOptions.ValidateGatewayFunc = validateListenerConflicts(provisioner.ConflictingHostPorts(mgr.GetClient(), *opts.Provisioner), opts.GatewayOptions.ValidateGatewayFunc, logger)
+		}
+		if opts.Provisioner != nil && opts.Provisioner.DriftPolicy == provisioner.DriftPolicyReport && opts.GatewayOptions.DriftStatusFunc == nil {
+			opts.GatewayOptions.DriftStatusFunc = provisioner.DriftStatus(mgr.GetClient(), *opts.Provisioner)
+		}
+		if opts.Provisioner != nil && opts.GatewayOptions.ExposedPortsFunc == nil {
+			opts.GatewayOptions.ExposedPortsFunc = provisioner.ExposedPorts(mgr.GetClient(), *opts.Provisioner)
+		}
+		if len(opts.GatewayOptions.ControllerNames) == 0 {
+			opts.GatewayOptions.ControllerNames = append([]gatewayv1.GatewayController{gatewayv1.GatewayController(opts.ControllerClass)}, opts.additionalControllerNames()...)
+		}
+		gatewayController, err := gateway.BuildWithManager(mgr, opts.GatewayOptions)
+		if err != nil {
+			return nil, fmt.Errorf("unable to add gateway controller: %w", err)
+		}
+		controllers[KindGateway] = gatewayController
+		if opts.Provisioner != nil {
+			if err := provisioner.WatchAddresses(mgr, gatewayController); err != nil {
+				return nil, fmt.Errorf("unable to watch the provisioned services: %w", err)
+			}
+			if err := provisioner.WatchRollouts(mgr, gatewayController); err != nil {
+				return nil, fmt.Errorf("unable to watch the provisioned workloads: %w", err)
+			}
+			if opts.Provisioner.ExposesHostPorts() {
+				if err := provisioner.WatchHostPorts(mgr, gatewayController); err != nil {
+					return nil, fmt.Errorf("unable to watch the provisioned host ports: %w", err)
+				}
+			}
+			if opts.Provisioner.DriftPolicy == provisioner.DriftPolicyReport {
+				if err := provisioner.WatchDrift(mgr, gatewayController, *opts.Provisioner); err != nil {
+					return nil, fmt.Errorf("unable to watch the drift of the provisioned resources: %w", err)
+				}
+			}
+		}
+		if opts.GatewayOptions.Settings != nil {
+			reloadable[KindGateway] = reloadableController{
+				settings:                opts.GatewayOptions.Settings,
+				maxConcurrentReconciles: opts.GatewayOptions.MaxConcurrentReconciles,
+				resyncPeriod:            opts.GatewayOptions.ResyncPeriod,
+			}
+		}
+	}
+
+	if !opts.disabled(KindHTTPRoute) {
+		opts.HTTPRouteOptions.Trigger = triggers[KindHTTPRoute]
+		opts.HTTPRouteOptions.AdditionalControllerNames = opts.additionalControllerNames()
+		httpRouteController, err := httproute.BuildWithManager(mgr, gatewayv1.GatewayController(opts.ControllerClass), opts.HTTPRouteOptions)
+		if err != nil {
+			return nil, fmt.Errorf("unable to add httproute controller: %w", err)
+		}
+		controllers[KindHTTPRoute] = httpRouteController
+		if opts.HTTPRouteOptions.Settings != nil {
+			reloadable[KindHTTPRoute] = reloadableController{
+				settings:                opts.HTTPRouteOptions.Settings,
+				maxConcurrentReconciles: opts.HTTPRouteOptions.MaxConcurrentReconciles,
+				resyncPeriod:            opts.HTTPRouteOptions.ResyncPeriod,
+			}
+		}
+	}
+	for kind := range triggers {
+		if _, ok := controllers[kind]; !ok {
+			delete(triggers, kind)
+		}
+	}
+
+	gates := newFeatureGates(opts.FeatureGates)
+	if opts.ConfigReload.Enabled {
+		if err := setupConfigReload(mgr, opts, reloadable, gates); err != nil {
+			return nil, fmt.Errorf("unable to add the kgameconfig controller: %w", err)
+		}
+	}
+
+	if opts.Provisioner != nil {
+		provisionerOptions := *opts.Provisioner
+		provisionerOptions.ParametersKinds = append(provisionerOptions.ParametersKinds, opts.ParametersKinds...)
+		if !opts.disabled(KindGateway) {
+			provisionerOptions.GatewayTrigger = triggers[KindGateway]
+		}
+		if opts.Sharding.Enabled() {
+			provisionerOptions.Sharding = &opts.Sharding
+		}
+		if err := provisioner.SetupWithManager(mgr, provisionerOptions); err != nil {
+			return nil, fmt.Errorf("unable to add the provisioner: %w", err)
+		}
+	}
+
+	if opts.Webhooks.Enabled {
+		opts.Webhooks.AdditionalControllerNames = opts.additionalControllerNames()
+		if err := webhooks.SetupWithManager(mgr, gatewayv1.GatewayController(opts.ControllerClass), opts.Webhooks); err != nil {
+			return nil, fmt.Errorf("unable to add the webhooks: %w", err)
+		}
+	}
+
+	ready := make(chan struct{})
+	if err := mgr.Add(&readinessRunnable{cache: mgr.GetCache(), ready: ready}); err != nil {
+		return nil, fmt.Errorf("unable to add the readiness signal: %w", err)
 	}
 
 	return &Controller{
-		mgr:    mgr,
-		logger: logger,
+		mgr:            mgr,
+		logger:         logger,
+		controllerName: gatewayv1.GatewayController(opts.ControllerClass),
+		policyKinds:    opts.PolicyKinds,
+		triggers:       triggers,
+		controllers:    controllers,
+		featureGates:   gates,
+
+		additionalControllerNames: opts.additionalControllerNames(),
+
+		reporter:        reporter,
+		onShutdown:      opts.OnShutdown,
+		shutdownTimeout: opts.ShutdownTimeout,
+
+		waitForCacheSync: opts.WaitForCacheSync,
+		ready:            ready,
 	}, nil
 }
 
+// setupLogging creates the Logger from the Logging options, when it is empty
+func (o *ControllerOptions) setupLogging() error {
+	if o.Logger.GetSink() != nil || o.Logging == nil {
+		return nil
+	}
+	logger, err := logging.New(*o.Logging)
+	if err != nil {
+		return fmt.Errorf("unable to create the logger: %w", err)
+	}
+	o.Logger = logger
+	return nil
+}
+
+// logger returns the Logger, or the klog one when empty, named after the
+// ControllerName
+func (o *ControllerOptions) logger() logr.Logger {
+	logger := o.Logger
+	if logger.GetSink() == nil {
+		logger = klog.NewKlogr()
+	}
+	return logger.WithName(o.ControllerName)
+}
+
+// additionalControllerNames returns the ControllerClasses
+func (o *ControllerOptions) additionalControllerNames() []gatewayv1.GatewayController {
+	names := make([]gatewayv1.GatewayController, 0, len(o.ControllerClasses))
+	for _, class := range o.ControllerClasses {
+		names = append(names, gatewayv1.GatewayController(class))
+	}
+	return names
+}
+
+// disabled returns true if the controller of kind is disabled
+func (o *ControllerOptions) disabled(kind Kind) bool {
+	return slices.Contains(o.DisabledControllers, kind)
+}
+
+// GetManager returns the manager running the kgame controllers
+func (k *Controller) GetManager() ctrl.Manager {
+	return k.mgr
+}
+
+// GetClient returns the manager client, reading from the kgame caches
+func (k *Controller) GetClient() client.Client {
+	return k.mgr.GetClient()
+}
+
+// GetCache returns the cache shared by the kgame controllers, like to add an
+// informer or an index of the implementer
+func (k *Controller) GetCache() cache.Cache {
+	return k.mgr.GetCache()
+}
+
+// GetScheme returns the scheme of the manager
+func (k *Controller) GetScheme() *runtime.Scheme {
+	return k.mgr.GetScheme()
+}
+
+// Snapshot returns the intermediate representation of all the managed Gateways
+// and routes, as seen by the informer caches, so it can only be called once the
+// controller is Ready.
+// The objects of the snapshot are shared with the caches to keep it cheap, and
+// must not be modified
+func (k *Controller) Snapshot(ctx context.Context) (ClusterSnapshot, error) {
+	graph, err := ir.Build(ctx, k.mgr.GetClient(), ir.BuildOptions{
+		ControllerName:            k.controllerName,
+		AdditionalControllerNames: k.additionalControllerNames,
+		PolicyKinds:               k.policyKinds,
+		ListOptions:               []client.ListOption{client.UnsafeDisableDeepCopy},
+	})
+	if err != nil {
+		return ClusterSnapshot{}, fmt.Errorf("error building the cluster snapshot: %w", err)
+	}
+	return *graph, nil
+}
+
+// Start starts the manager, blocking until ctx is done or Stop is called, or
+// until the caches are synced with WaitForCacheSync. Once the manager is stopped
+// and the in-flight reconciliations are drained, the status report is flushed
+// and the OnShutdown hooks are called. A manager can only be started once, see
+// Restart to start the controllers again
 func (k *Controller) Start(ctx context.Context) error {
 	// This is not ideal, but eventually the caller does not want to control the context
 	if ctx == nil {
 		ctx = ctrl.SetupSignalHandler()
 	}
+	ctx, stop := context.WithCancel(ctx)
+	done := make(chan struct{})
+	k.mu.Lock()
+	k.stop, k.done = stop, done
+	k.mu.Unlock()
+
+	run := func() {
+		defer close(done)
+		defer stop()
+		k.logger.Info("starting the controller")
+		err := k.mgr.Start(ctx)
+		k.shutdown()
+		k.mu.Lock()
+		k.err = err
+		k.mu.Unlock()
+	}
+	if !k.waitForCacheSync {
+		run()
+		return k.Wait()
+	}
+
+	go run()
+	select {
+	case <-k.ready:
+		return nil
+	case <-done:
+		return k.Wait()
+	}
+}
+
+// Ready returns a channel closed once the informer caches are synced after the
+// manager is started, so the Snapshot and the reads of the manager client
+// reflect the cluster state
+func (k *Controller) Ready() <-chan struct{} {
+	return k.ready
+}
+
+// Wait blocks until the controller is stopped, returning the error of the
+// manager. It returns immediately if the controller was not started
+func (k *Controller) Wait() error {
+	k.mu.Lock()
+	done := k.done
+	k.mu.Unlock()
+	if done == nil {
+		return nil
+	}
+	<-done
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.err
+}
+
+// Stop stops the controller, blocking until the manager is stopped. It is a noop
+// if the controller was not started
+func (k *Controller) Stop() {
+	k.mu.Lock()
+	stop, done := k.stop, k.done
+	k.mu.Unlock()
+	if stop == nil {
+		return
+	}
+	stop()
+	<-done
+}
+
+// Restart stops the controller and returns a new one, created with the
+// NewController options followed by options, like a new ControllerClass or
+// hooks. The returned Controller must be started. The leader election Lease is
+// only released on stop with ReleaseOnCancel, otherwise the new Controller waits
+// for it to expire. Only the controllers created by NewController can be
+// restarted
+func (k *Controller) Restart(options ...Option) (*Controller, error) {
+	if !k.restartable {
+		return nil, errors.New("only the controllers created by NewController can be restarted")
+	}
+	k.Stop()
+	return newController(append(slices.Clip(k.options), options...), true)
+}
+
+// shutdown flushes the status report and calls the OnShutdown hooks, with a
+// context bounded by the shutdownTimeout
+func (k *Controller) shutdown() {
+	ctx, cancel := context.WithTimeout(context.Background(), k.shutdownTimeout)
+	defer cancel()
+
+	if err := k.reporter.Flush(ctx); err != nil {
+		k.logger.Error(err, "unable to flush the controller health report")
+	}
+	for i, hook := range k.onShutdown {
+		k.logger.V(2).Info("calling the shutdown hook", "index", i)
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					k.logger.Error(fmt.Errorf("panic: %v", r), "shutdown hook panicked", "index", i)
+				}
+			}()
+			hook(ctx)
+		}()
+	}
+}
 
-	// TODO: should we wait for client cache to be populated?
+// readinessRunnable closes ready once the caches are synced. It runs on all the
+// replicas, as the caches do
+type readinessRunnable struct {
+	cache cache.Cache
+	ready chan struct{}
+}
 
-	k.logger.Info("starting the controller")
-	return k.mgr.Start(ctx)
+func (r *readinessRunnable) Start(ctx context.Context) error {
+	if r.cache.WaitForCacheSync(ctx) {
+		close(r.ready)
+	}
+	return nil
+}
 
+func (r *readinessRunnable) NeedLeaderElection() bool {
+	return false
 }
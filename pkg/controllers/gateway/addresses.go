@@ -0,0 +1,175 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/rikatz/kgame/pkg/hooks"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// AddressProviderFunc is called during the programming of the Gateway to get the
+// addresses assigned to it. The Gateway spec.addresses contains the addresses
+// requested by the user, and the provider is expected to return the ones it was
+// able to assign. Requested addresses that are not returned are considered not
+// usable.
+// If empty, the addresses are the ones returned by the Programmer or Translator,
+// and a Gateway requesting addresses without any of them configured is never
+// programmed
+type AddressProviderFunc func(ctx context.Context, gw *gatewayv1.Gateway) ([]gatewayv1.GatewayStatusAddress, error)
+
+// ExposedPortsFunc returns the ports each listener of the Gateway is reachable
+// on, from outside of the cluster, by listener name, like the node ports of its
+// Service. They are reported on the message of the listeners Programmed
+// condition, as the Gateway addresses have no ports
+type ExposedPortsFunc func(ctx context.Context, gw *gatewayv1.Gateway) (map[gatewayv1.SectionName]int32, error)
+
+// resolveAddresses validates the requested addresses, calls the address provider
+// and copies the usable addresses to the Gateway status. When no address provider
+// is configured the addresses are expected to be returned by the Programmer.
+// A non nil condition is returned when the addresses cannot be honored, and should
+// be set as the Gateway Programmed condition. An error is returned when the address
+// provider failed, and the reconciliation should be retried
+func (r *reconciler) resolveAddresses(ctx context.Context, gw *gatewayv1.Gateway) (*metav1.Condition, error) {
+	if r.options.AddressProviderFunc == nil && r.programmer == nil {
+		if len(gw.Spec.Addresses) == 0 {
+			return nil, nil
+		}
+		cond := newCondition(
+			string(gatewayv1.GatewayConditionProgrammed),
+			string(gatewayv1.GatewayReasonAddressNotUsable),
+			metav1.ConditionFalse,
+			"Gateway addresses are not supported by this controller",
+			gw.Generation)
+		return &cond, nil
+	}
+
+	for _, requested := range gw.Spec.Addresses {
+		if err := validateAddress(requested); err != nil {
+			cond := newCondition(
+				string(gatewayv1.GatewayConditionProgrammed),
+				string(gatewayv1.GatewayReasonAddressNotUsable),
+				metav1.ConditionFalse,
+				err.Error(),
+				gw.Generation)
+			return &cond, nil
+		}
+	}
+
+	if r.options.AddressProviderFunc == nil {
+		return nil, nil
+	}
+
+	addresses, err := hooks.Call(ctx, r.hooks.Runner, gw, "AddressProviderFunc", func(ctx context.Context, gw *gatewayv1.Gateway) ([]gatewayv1.GatewayStatusAddress, error) {
+		return r.options.AddressProviderFunc(ctx, gw)
+	})
+	if err != nil {
+		cond := newCondition(
+			string(gatewayv1.GatewayConditionProgrammed),
+			string(gatewayv1.GatewayReasonAddressNotAssigned),
+			metav1.ConditionFalse,
+			fmt.Sprintf("error assigning addresses: %s", err),
+			gw.Generation)
+		return &cond, fmt.Errorf("error executing address provider function: %w", err)
+	}
+
+	return applyAddresses(gw, addresses), nil
+}
+
+// reportExposedPorts adds the ports returned by the ExposedPortsFunc to the
+// message of the programmed listeners
+func (r *reconciler) reportExposedPorts(ctx context.Context, gw *gatewayv1.Gateway, listenersProgrammed map[gatewayv1.SectionName]metav1.Condition) error {
+	ports, err := hooks.Call(ctx, r.hooks.Runner, gw, "ExposedPortsFunc", func(ctx context.Context, gw *gatewayv1.Gateway) (map[gatewayv1.SectionName]int32, error) {
+		return r.options.ExposedPortsFunc(ctx, gw)
+	})
+	if err != nil {
+		return fmt.Errorf("error executing exposed ports function: %w", err)
+	}
+	for name, condition := range listenersProgrammed {
+		port, ok := ports[name]
+		if !ok || condition.Status != metav1.ConditionTrue {
+			continue
+		}
+		condition.Message = fmt.Sprintf("%s, exposed on the port %d", condition.Message, port)
+		listenersProgrammed[name] = condition
+	}
+	return nil
+}
+
+// applyAddresses copies the assigned addresses to the Gateway status, if all the
+// requested addresses are usable. A non nil condition is returned when the
+// requested addresses cannot be honored, with the AddressNotAssigned reason when
+// none was assigned
+func applyAddresses(gw *gatewayv1.Gateway, addresses []gatewayv1.GatewayStatusAddress) *metav1.Condition {
+	if len(gw.Spec.Addresses) > 0 && len(addresses) == 0 {
+		cond := newCondition(
+			string(gatewayv1.GatewayConditionProgrammed),
+			string(gatewayv1.GatewayReasonAddressNotAssigned),
+			metav1.ConditionFalse,
+			"no addresses were assigned to the Gateway",
+			gw.Generation)
+		return &cond
+	}
+	if notUsable := unusableAddresses(gw.Spec.Addresses, addresses); len(notUsable) > 0 {
+		cond := newCondition(
+			string(gatewayv1.GatewayConditionProgrammed),
+			string(gatewayv1.GatewayReasonAddressNotUsable),
+			metav1.ConditionFalse,
+			fmt.Sprintf("requested addresses cannot be assigned: %s", strings.Join(notUsable, ", ")),
+			gw.Generation)
+		return &cond
+	}
+
+	// Only the usable addresses are copied to status
+	gw.Status.Addresses = addresses
+	return nil
+}
+
+// validateAddress verifies if the requested address is well formed for its type
+func validateAddress(address gatewayv1.GatewaySpecAddress) error {
+	if address.Value == "" {
+		return nil
+	}
+	if addressType(address.Type) == gatewayv1.IPAddressType && net.ParseIP(address.Value) == nil {
+		return fmt.Errorf("address %q is not a valid IP address", address.Value)
+	}
+	return nil
+}
+
+// unusableAddresses returns the requested addresses that are not part of the
+// assigned addresses. A requested address without a value is honored if any
+// address of the same type was assigned
+func unusableAddresses(requested []gatewayv1.GatewaySpecAddress, assigned []gatewayv1.GatewayStatusAddress) []string {
+	notUsable := make([]string, 0)
+	for _, req := range requested {
+		var found bool
+		for _, addr := range assigned {
+			if addressType(req.Type) != addressType(addr.Type) {
+				continue
+			}
+			if req.Value == "" || req.Value == addr.Value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			value := req.Value
+			if value == "" {
+				value = fmt.Sprintf("any %s", addressType(req.Type))
+			}
+			notUsable = append(notUsable, value)
+		}
+	}
+	return notUsable
+}
+
+// addressType returns the address type, defaulting to IPAddress as the API does
+func addressType(t *gatewayv1.AddressType) gatewayv1.AddressType {
+	if t == nil {
+		return gatewayv1.IPAddressType
+	}
+	return *t
+}
@@ -0,0 +1,65 @@
+package gateway
+
+import (
+	"context"
+
+	"github.com/rikatz/kgame/pkg/indexes"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// gatewaysOfService enqueues the parent Gateways of the HTTPRoutes referencing a
+// Service on their backendRefs, so the Translator receives the changed Service
+func gatewaysOfService(kubeclient client.Client) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		return gatewaysOfBackend(ctx, kubeclient, obj.GetNamespace(), obj.GetName())
+	}
+}
+
+// gatewaysOfEndpointSlice enqueues the Gateways of the Service of an
+// EndpointSlice, so the dataplane endpoints follow the Service endpoints
+func gatewaysOfEndpointSlice(kubeclient client.Client) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		service, ok := obj.GetLabels()[discoveryv1.LabelServiceName]
+		if !ok {
+			return nil
+		}
+		return gatewaysOfBackend(ctx, kubeclient, obj.GetNamespace(), service)
+	}
+}
+
+// gatewaysOfBackend returns the requests of the Gateways referenced by the routes
+// of the Service, found through the HTTPRouteBackendService index
+func gatewaysOfBackend(ctx context.Context, kubeclient client.Client, namespace, name string) []reconcile.Request {
+	routes := &gatewayv1.HTTPRouteList{}
+	if err := kubeclient.List(ctx, routes, client.MatchingFields{
+		indexes.HTTPRouteBackendService: indexes.ServiceKey(namespace, name),
+	}); err != nil {
+		return nil
+	}
+	seen := make(map[types.NamespacedName]bool)
+	var requests []reconcile.Request
+	for _, route := range routes.Items {
+		for _, parentRef := range route.Spec.ParentRefs {
+			if parentRef.Group != nil && *parentRef.Group != gatewayv1.GroupName {
+				continue
+			}
+			if parentRef.Kind != nil && *parentRef.Kind != "Gateway" {
+				continue
+			}
+			key := types.NamespacedName{Namespace: route.GetNamespace(), Name: string(parentRef.Name)}
+			if parentRef.Namespace != nil {
+				key.Namespace = string(*parentRef.Namespace)
+			}
+			if !seen[key] {
+				seen[key] = true
+				requests = append(requests, reconcile.Request{NamespacedName: key})
+			}
+		}
+	}
+	return requests
+}
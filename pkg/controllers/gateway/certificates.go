@@ -0,0 +1,177 @@
+package gateway
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"slices"
+
+	"github.com/rikatz/kgame/pkg/indexes"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+// listenerCertificates are the resolved certificateRefs of a listener
+type listenerCertificates struct {
+	// secrets are the valid Secrets of the certificateRefs
+	secrets []*corev1.Secret
+	// reason and message of the listener ResolvedRefs condition, when one of
+	// the certificateRefs is not resolved
+	reason  string
+	message string
+}
+
+// resolved returns true if all the certificateRefs are resolved
+func (c listenerCertificates) resolved() bool {
+	return c.reason == ""
+}
+
+// unresolved records the first certificateRef not resolved
+func (c *listenerCertificates) unresolved(reason gatewayv1.ListenerConditionReason, format string, args ...any) {
+	if c.reason == "" {
+		c.reason = string(reason)
+		c.message = fmt.Sprintf(format, args...)
+	}
+}
+
+// resolveCertificates resolves the certificateRefs of the listener. The Secrets
+// on other namespaces must be allowed by a ReferenceGrant, and be valid
+// kubernetes.io/tls Secrets
+func (r *reconciler) resolveCertificates(ctx context.Context, gw *gatewayv1.Gateway, listener gatewayv1.Listener) (listenerCertificates, error) {
+	var certificates listenerCertificates
+	if listener.TLS == nil {
+		return certificates, nil
+	}
+	for _, ref := range listener.TLS.CertificateRefs {
+		if (ref.Group != nil && *ref.Group != "") || (ref.Kind != nil && *ref.Kind != "Secret") {
+			certificates.unresolved(gatewayv1.ListenerReasonInvalidCertificateRef, "Certificate reference %s is not a Secret", ref.Name)
+			continue
+		}
+		key := types.NamespacedName{Namespace: gw.GetNamespace(), Name: string(ref.Name)}
+		if ref.Namespace != nil && string(*ref.Namespace) != gw.GetNamespace() {
+			key.Namespace = string(*ref.Namespace)
+			permitted, err := r.certificatePermitted(ctx, gw, key)
+			if err != nil {
+				return certificates, err
+			}
+			if !permitted {
+				certificates.unresolved(gatewayv1.ListenerReasonRefNotPermitted, "Certificate reference to the Secret %s is not permitted by any ReferenceGrant", key)
+				continue
+			}
+		}
+		secret := &corev1.Secret{}
+		if err := r.client.Get(ctx, key, secret); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return certificates, fmt.Errorf("error getting certificate %s: %w", key, err)
+			}
+			certificates.unresolved(gatewayv1.ListenerReasonInvalidCertificateRef, "Certificate Secret %s does not exist", key)
+			continue
+		}
+		if err := validCertificate(secret); err != nil {
+			certificates.unresolved(gatewayv1.ListenerReasonInvalidCertificateRef, "Certificate Secret %s is not valid: %s", key, err)
+			continue
+		}
+		certificates.secrets = append(certificates.secrets, secret)
+	}
+	return certificates, nil
+}
+
+// resolveListenersCertificates resolves the certificateRefs of the listeners of
+// gw, by listener name
+func (r *reconciler) resolveListenersCertificates(ctx context.Context, gw *gatewayv1.Gateway) (map[gatewayv1.SectionName]listenerCertificates, error) {
+	resolved := make(map[gatewayv1.SectionName]listenerCertificates, len(gw.Spec.Listeners))
+	for _, listener := range gw.Spec.Listeners {
+		certificates, err := r.resolveCertificates(ctx, gw, listener)
+		if err != nil {
+			return nil, err
+		}
+		resolved[listener.Name] = certificates
+	}
+	return resolved, nil
+}
+
+// validCertificate returns an error if the Secret is not a kubernetes.io/tls
+// Secret with a valid certificate and key
+func validCertificate(secret *corev1.Secret) error {
+	if secret.Type != corev1.SecretTypeTLS {
+		return fmt.Errorf("type is %q, not %q", secret.Type, corev1.SecretTypeTLS)
+	}
+	if _, err := tls.X509KeyPair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey]); err != nil {
+		return err
+	}
+	return nil
+}
+
+// certificatePermitted returns true if a ReferenceGrant on the namespace of the
+// Secret allows the Gateways of gw namespace to reference it
+func (r *reconciler) certificatePermitted(ctx context.Context, gw *gatewayv1.Gateway, secret types.NamespacedName) (bool, error) {
+	grants := &gatewayv1beta1.ReferenceGrantList{}
+	if err := r.client.List(ctx, grants, client.InNamespace(secret.Namespace)); err != nil {
+		return false, fmt.Errorf("error listing the referencegrants of %s: %w", secret.Namespace, err)
+	}
+	for _, grant := range grants.Items {
+		from := slices.ContainsFunc(grant.Spec.From, func(from gatewayv1beta1.ReferenceGrantFrom) bool {
+			return from.Group == gatewayv1.GroupName && from.Kind == "Gateway" && string(from.Namespace) == gw.GetNamespace()
+		})
+		to := slices.ContainsFunc(grant.Spec.To, func(to gatewayv1beta1.ReferenceGrantTo) bool {
+			return to.Group == "" && to.Kind == "Secret" && (to.Name == nil || string(*to.Name) == secret.Name)
+		})
+		if from && to {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// gatewaysOfSecret enqueues the Gateways with listeners using a Secret as their
+// certificate, found through the GatewayCertificateSecret index, so a rotated
+// certificate is programmed and the listeners ResolvedRefs condition follows the
+// Secret creation and removal
+func gatewaysOfSecret(kubeclient client.Client) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		gateways := &gatewayv1.GatewayList{}
+		if err := kubeclient.List(ctx, gateways, client.MatchingFields{
+			indexes.GatewayCertificateSecret: indexes.SecretKey(obj.GetNamespace(), obj.GetName()),
+		}); err != nil {
+			return nil
+		}
+		requests := make([]reconcile.Request, 0, len(gateways.Items))
+		for _, gw := range gateways.Items {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&gw)})
+		}
+		return requests
+	}
+}
+
+// gatewaysOfReferenceGrant enqueues the Gateways with listeners using a
+// certificate on the namespace of a ReferenceGrant, so the listeners
+// ResolvedRefs condition follows the grants
+func gatewaysOfReferenceGrant(kubeclient client.Client) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		gateways := &gatewayv1.GatewayList{}
+		if err := kubeclient.List(ctx, gateways); err != nil {
+			return nil
+		}
+		var requests []reconcile.Request
+		for _, gw := range gateways.Items {
+			if gw.GetNamespace() == obj.GetNamespace() {
+				continue
+			}
+			referenced := slices.ContainsFunc(gw.Spec.Listeners, func(listener gatewayv1.Listener) bool {
+				return listener.TLS != nil && slices.ContainsFunc(listener.TLS.CertificateRefs, func(ref gatewayv1.SecretObjectReference) bool {
+					return ref.Namespace != nil && string(*ref.Namespace) == obj.GetNamespace()
+				})
+			})
+			if referenced {
+				requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&gw)})
+			}
+		}
+		return requests
+	}
+}
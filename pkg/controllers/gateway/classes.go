@@ -0,0 +1,79 @@
+package gateway
+
+import (
+	"context"
+
+	"github.com/rikatz/kgame/pkg/indexes"
+	"github.com/rikatz/kgame/pkg/parameters"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// gatewaysOfClass enqueues the Gateways of a GatewayClass, found through the
+// GatewayClassName index, like when the class becomes accepted after its
+// Gateways were created
+func gatewaysOfClass(kubeclient client.Client) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		gateways := &gatewayv1.GatewayList{}
+		if err := kubeclient.List(ctx, gateways, client.MatchingFields{indexes.GatewayClassName: obj.GetName()}); err != nil {
+			return nil
+		}
+		requests := make([]reconcile.Request, 0, len(gateways.Items))
+		for _, gw := range gateways.Items {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&gw)})
+		}
+		return requests
+	}
+}
+
+// gatewaysOfParameters enqueues the Gateways of the GatewayClasses referencing a
+// parameters object of the kind gvk, so the Programmer receives the changed
+// parameters
+func gatewaysOfParameters(kubeclient client.Client, gvk schema.GroupVersionKind) handler.MapFunc {
+	classGateways := gatewaysOfClass(kubeclient)
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		classes, err := parameters.GatewayClassesOf(ctx, kubeclient, gvk, obj)
+		if err != nil {
+			return nil
+		}
+		requests := gatewaysOfInfrastructureParameters(ctx, kubeclient, gvk, obj)
+		for i := range classes {
+			requests = append(requests, classGateways(ctx, &classes[i])...)
+		}
+		return requests
+	}
+}
+
+// classChanged filters the GatewayClass events affecting its Gateways: the
+// creation of the class, the changes of its spec and of its Accepted condition,
+// like between Pending and InvalidParameters
+var classChanged = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		if e.ObjectOld.GetGeneration() != e.ObjectNew.GetGeneration() {
+			return true
+		}
+		oldClass, ok := e.ObjectOld.(*gatewayv1.GatewayClass)
+		if !ok {
+			return false
+		}
+		newClass, ok := e.ObjectNew.(*gatewayv1.GatewayClass)
+		if !ok {
+			return false
+		}
+		accepted := string(gatewayv1.GatewayClassConditionStatusAccepted)
+		oldAccepted := meta.FindStatusCondition(oldClass.Status.Conditions, accepted)
+		newAccepted := meta.FindStatusCondition(newClass.Status.Conditions, accepted)
+		if oldAccepted == nil || newAccepted == nil {
+			return oldAccepted != newAccepted
+		}
+		return oldAccepted.Status != newAccepted.Status || oldAccepted.Reason != newAccepted.Reason
+	},
+	DeleteFunc:  func(event.DeleteEvent) bool { return false },
+	GenericFunc: func(event.GenericEvent) bool { return false },
+}
@@ -0,0 +1,71 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/rikatz/kgame/pkg/indexes"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// waitForRoutes returns true while the Gateway being deleted is referenced by any
+// route and the RouteDrainTimeout did not expire. The result requeues the Gateway
+// once the timeout expires, while the removal of the routes is watched
+func (r *reconciler) waitForRoutes(ctx context.Context, logger logr.Logger, gw *gatewayv1.Gateway) (bool, reconcile.Result, error) {
+	var remaining time.Duration
+	if r.options.RouteDrainTimeout > 0 {
+		remaining = time.Until(gw.GetDeletionTimestamp().Add(r.options.RouteDrainTimeout))
+		if remaining <= 0 {
+			return false, reconcile.Result{}, nil
+		}
+	}
+
+	routes := &gatewayv1.HTTPRouteList{}
+	if err := r.client.List(ctx, routes, client.MatchingFields{
+		indexes.HTTPRouteParentGateway: indexes.GatewayKey(gw.GetNamespace(), gw.GetName()),
+	}); err != nil {
+		return false, reconcile.Result{}, fmt.Errorf("error listing the routes of %s/%s: %w", gw.GetNamespace(), gw.GetName(), err)
+	}
+	if len(routes.Items) == 0 {
+		return false, reconcile.Result{}, nil
+	}
+
+	logger.Info("deletion waiting for routes to be removed", "routes", len(routes.Items))
+	return true, reconcile.Result{RequeueAfter: remaining}, nil
+}
+
+// deletingParentGatewaysOf maps a route to the requests of the parent Gateways that
+// are being deleted
+func deletingParentGatewaysOf(kubeclient client.Client) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		route, ok := obj.(*gatewayv1.HTTPRoute)
+		if !ok {
+			return nil
+		}
+		var requests []reconcile.Request
+		for _, parentRef := range route.Spec.ParentRefs {
+			if parentRef.Group != nil && *parentRef.Group != gatewayv1.GroupName {
+				continue
+			}
+			if parentRef.Kind != nil && *parentRef.Kind != "Gateway" {
+				continue
+			}
+			key := types.NamespacedName{Namespace: route.GetNamespace(), Name: string(parentRef.Name)}
+			if parentRef.Namespace != nil {
+				key.Namespace = string(*parentRef.Namespace)
+			}
+			gw := &gatewayv1.Gateway{}
+			if err := kubeclient.Get(ctx, key, gw); err != nil || gw.GetDeletionTimestamp().IsZero() {
+				continue
+			}
+			requests = append(requests, reconcile.Request{NamespacedName: key})
+		}
+		return requests
+	}
+}
@@ -0,0 +1,52 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rikatz/kgame/pkg/hooks"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// ConditionDataplaneDrifted is the Gateway condition reporting the out of band
+// changes of its dataplane, see DriftStatusFunc
+const ConditionDataplaneDrifted = "DataplaneDrifted"
+
+// DriftStatus are the out of band changes of the dataplane of a Gateway
+type DriftStatus struct {
+	// Drifted is true when the dataplane was changed out of band
+	Drifted bool
+	// Reason and Message of the DataplaneDrifted condition
+	Reason  string
+	Message string
+}
+
+// DriftStatusFunc is called on each reconciliation of an accepted Gateway,
+// returning the out of band changes of its dataplane, like the fields of the
+// provisioned resources kept by the provisioner DriftPolicyReport, set on the
+// DataplaneDrifted condition. A nil status removes the condition. The Gateway is
+// not reconciled again by itself, so the implementations are expected to watch
+// their dataplane
+type DriftStatusFunc func(ctx context.Context, gw *gatewayv1.Gateway) (*DriftStatus, error)
+
+// checkDrift calls the DriftStatusFunc, returning the DataplaneDrifted condition,
+// nil when it should be removed. On error the current condition is kept
+func (r *reconciler) checkDrift(ctx context.Context, gw *gatewayv1.Gateway) (*metav1.Condition, error) {
+	drift, err := hooks.Call(ctx, r.hooks.Runner, gw, "DriftStatusFunc", func(ctx context.Context, gw *gatewayv1.Gateway) (*DriftStatus, error) {
+		return r.options.DriftStatusFunc(ctx, gw)
+	})
+	if err != nil {
+		return meta.FindStatusCondition(gw.Status.Conditions, ConditionDataplaneDrifted), fmt.Errorf("error executing drift status function: %w", err)
+	}
+	if drift == nil {
+		return nil, nil
+	}
+	status := metav1.ConditionFalse
+	if drift.Drifted {
+		status = metav1.ConditionTrue
+	}
+	cond := newCondition(ConditionDataplaneDrifted, drift.Reason, status, drift.Message, gw.Generation)
+	return &cond, nil
+}
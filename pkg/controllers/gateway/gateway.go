@@ -2,20 +2,43 @@ package gateway
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"time"
 
 	"github.com/go-logr/logr"
+	"github.com/rikatz/kgame/pkg/conditions"
+	"github.com/rikatz/kgame/pkg/finalizer"
+	"github.com/rikatz/kgame/pkg/health"
+	"github.com/rikatz/kgame/pkg/hooks"
+	"github.com/rikatz/kgame/pkg/indexes"
+	"github.com/rikatz/kgame/pkg/notify"
+	"github.com/rikatz/kgame/pkg/parameters"
+	"github.com/rikatz/kgame/pkg/predicates"
+	"github.com/rikatz/kgame/pkg/ratelimit"
+	"github.com/rikatz/kgame/pkg/reload"
+	"github.com/rikatz/kgame/pkg/sharding"
+	"github.com/rikatz/kgame/pkg/writer"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 )
 
 type reconciler struct {
@@ -23,21 +46,149 @@ type reconciler struct {
 	scheme  *runtime.Scheme
 	logger  logr.Logger
 	options GatewayOptions
+	hooks   hooks.Hooks[*gatewayv1.Gateway]
+	// finalizers manages the FinalizerName
+	finalizers *finalizer.Manager
+	// programmer is the Programmer of the options, or the Translator adapted to
+	// the Programmer interface
+	programmer Programmer
+	// status writes the Gateway status with the configured strategy
+	status *writer.StatusWriter
 }
 
 // AddFinalizerFunc is a function that should be called immediately before adding a
 // finalizer.
 // If empty the finalizer will be added without further check
-type AddFinalizerFunc func(ctx context.Context) error
+type AddFinalizerFunc = hooks.AddFinalizerFunc
 
 // RemoveFinalizerFunc is a function that should be called immediately before removing
 // a finalizer. If empty the finalizer will be removed without any further check
-type RemoveFinalizerFunc func(ctx context.Context) error
+type RemoveFinalizerFunc = hooks.RemoveFinalizerFunc
 
 type GatewayOptions struct {
 	FinalizerName       string
 	AddFinalizerFunc    AddFinalizerFunc
 	RemoveFinalizerFunc RemoveFinalizerFunc
+	AddressProviderFunc AddressProviderFunc
+	// ExposedPortsFunc reports the ports the programmed listeners are reachable on
+	ExposedPortsFunc ExposedPortsFunc
+	// WaitForRoutesOnDelete keeps the Gateway finalizer while any route references
+	// the Gateway, giving the dataplane time to drain and preventing the routes
+	// from silently losing their parent. Requires a FinalizerName
+	WaitForRoutesOnDelete bool
+	// RouteDrainTimeout is the maximum time to wait for the routes, since the
+	// deletion of the Gateway was requested. Zero waits until all of them are
+	// removed
+	RouteDrainTimeout time.Duration
+	// ValidateGatewayFunc rejects the Gateway spec combinations not supported by
+	// the implementation
+	ValidateGatewayFunc ValidateGatewayFunc
+	// AnnotationPrefix is the prefix of the Gateway annotations parsed as
+	// Overrides, like "mylab.tld/", for implementations migrating from annotation
+	// driven controllers. The overrides are passed to the Programmer and
+	// Translator on the Snapshot. If empty the annotations are not parsed
+	AnnotationPrefix string
+	// ValidateOverridesFunc rejects the invalid Overrides
+	ValidateOverridesFunc ValidateOverridesFunc
+	// ValidateInfrastructureParametersFunc validates the object referenced by the
+	// Gateway spec.infrastructure.parametersRef. The validated parameters are
+	// passed to the Programmer and Translator on the Snapshot
+	ValidateInfrastructureParametersFunc ValidateInfrastructureParametersFunc
+	// DataplaneReadyFunc keeps the programmed Gateways as Pending until their
+	// dataplane is serving
+	DataplaneReadyFunc DataplaneReadyFunc
+	// DriftStatusFunc reports the out of band changes of the dataplane of the
+	// Gateway on its DataplaneDrifted condition
+	DriftStatusFunc DriftStatusFunc
+	// Programmer programs the accepted Gateways on the dataplane
+	Programmer Programmer
+	// Translator translates the accepted Gateways to a dataplane configuration.
+	// It is used only when Programmer is empty
+	Translator Translator
+	// ListenerHooks are optional hooks called for each programmed or removed
+	// listener
+	ListenerHooks ListenerHooks
+	// ReconcileHooks are optional hooks called during the Gateway reconciliation
+	ReconcileHooks ReconcileHooks
+	// HookTimeout is the maximum duration of each hook call. Defaults to
+	// hooks.DefaultTimeout
+	HookTimeout time.Duration
+	// Reporter receives the reconciliation results of this controller. It is set
+	// by NewController when the controller status report is enabled
+	Reporter *health.Reporter
+	// Notify receives the notifications of this controller. Defaults to the
+	// NewController ControllerOptions.Notify
+	Notify notify.Func
+	// Trigger receives the objects to be reconciled again on request, like when
+	// the dataplane lost its configuration. A triggered Gateway is programmed
+	// again, even if its inputs did not change. It is set by NewController, see
+	// Controller.TriggerReconcile
+	Trigger <-chan event.GenericEvent
+	// MaxConcurrentReconciles is the number of Gateways reconciled in parallel.
+	// Defaults to 1
+	MaxConcurrentReconciles int
+	// ResyncPeriod reconciles each Gateway again after the given period since its
+	// last successful reconciliation, correcting any drift of the dataplane. With
+	// the InputCache or ProgramParallelism, the resyncs do not program the
+	// Gateways whose inputs did not change. Disabled when zero
+	ResyncPeriod time.Duration
+	// Settings changes the MaxConcurrentReconciles and ResyncPeriod at runtime.
+	// It is set by NewController when the KgameConfig reload is enabled
+	Settings *reload.Settings
+	// Sharding skips the reconciliations owned by other active replicas. It is
+	// set by NewController from ControllerOptions.Sharding
+	Sharding *sharding.Options
+	// RateLimit configures the backoff of the failed reconciliations. Defaults to
+	// the controller-runtime rate limiter
+	RateLimit ratelimit.Options
+	// Predicates are appended to the predicates of the Gateway events, see the
+	// predicates package
+	Predicates []predicate.Predicate
+	// Filter skips the Gateway updates not requiring a reconciliation, like the
+	// status only updates written by the controller itself
+	Filter predicates.Options
+
+	// Writes configures the field manager and the strategy of the Gateway
+	// status writes
+	Writes writer.Options
+
+	// ParametersKinds are the kinds of the GatewayClass and Gateway infrastructure
+	// parameters. Their objects are watched, and their changes reconcile the
+	// Gateways referencing them, directly or through their class. See
+	// ControllerOptions.ParametersKinds
+	ParametersKinds []schema.GroupVersionKind
+	// WatchBackends reconciles the Gateways again when the Services referenced by
+	// the backendRefs of their routes, or the EndpointSlices of those Services,
+	// change, so the Programmer can update the dataplane endpoints. The
+	// EndpointSlices of the watched namespaces are cached
+	WatchBackends bool
+
+	// ClassAPIReaderFallback gets the GatewayClass from the API server when it is
+	// not on the cache, so the Gateways of a class created moments ago are not
+	// ignored. The class read from the API server is managed when its
+	// controllerName is one of ControllerNames
+	ClassAPIReaderFallback bool
+	// ControllerNames are the GatewayClass controllerNames managed by kgame, used
+	// by ClassAPIReaderFallback. It is set by NewController
+	ControllerNames []gatewayv1.GatewayController
+
+	// InputCache only calls the Programmer, or the Translator, when the hash of
+	// the Gateway resolved inputs changed since its last successful call: the
+	// Gateway spec, labels and annotations, its GatewayClass and parameters, the
+	// attached routes, and the backends and TLS certificates they reference. The
+	// last ProgramResult is reused otherwise, also on the ResyncPeriod
+	// reconciliations. The objects received from the Trigger, see
+	// Controller.TriggerReconcile, drop the cached inputs of the Gateway, so it is
+	// programmed again. Programmers reading other resources should not enable it
+	InputCache bool
+	// ProgramParallelism calls the Programmer, or the Translator, on background
+	// lanes when positive: each Gateway is programmed by one lane at a time, and
+	// up to ProgramParallelism Gateways are programmed in parallel, so a slow
+	// dataplane push does not block the reconciliations of the other Gateways.
+	// The Gateway is Pending while its lane runs, and reconciled again once it
+	// finishes. The resolved inputs are hashed like with the InputCache, which is
+	// implied
+	ProgramParallelism int
 }
 
 // matchManagedGatewayClass will check the object Gateway Class to define if it should
@@ -45,8 +196,10 @@ type GatewayOptions struct {
 // Because this controller already ignores caching any non managed GatewayClass,
 // any attempt to Get a gatewayclass that does not exist represents that this is
 // a gatewayClass that this controller does not manage, so we don't need to match
-// the GatewayClass spec.ControllerName
-func matchManagedGatewayClass(kubeclient client.Client, logger logr.Logger) func(obj client.Object) bool {
+// the GatewayClass spec.ControllerName.
+// When apiReader is set, a GatewayClass not found on the cache is read from it
+// and matched against the controllerNames
+func matchManagedGatewayClass(kubeclient client.Client, apiReader client.Reader, controllerNames []gatewayv1.GatewayController, logger logr.Logger) func(obj client.Object) bool {
 	return func(obj client.Object) bool {
 		gw, ok := obj.(*gatewayv1.Gateway)
 		if !ok {
@@ -56,6 +209,12 @@ func matchManagedGatewayClass(kubeclient client.Client, logger logr.Logger) func
 		gatewayclass := &gatewayv1.GatewayClass{}
 		gatewayclass.SetName(string(gw.Spec.GatewayClassName))
 		err := kubeclient.Get(context.Background(), client.ObjectKeyFromObject(gatewayclass), gatewayclass)
+		if apierrors.IsNotFound(err) && apiReader != nil {
+			err = apiReader.Get(context.Background(), client.ObjectKeyFromObject(gatewayclass), gatewayclass)
+			if err == nil && !slices.Contains(controllerNames, gatewayclass.Spec.ControllerName) {
+				err = fmt.Errorf("unmanaged controllerName %q", gatewayclass.Spec.ControllerName)
+			}
+		}
 		if err != nil {
 			logger.Info("gatewayclass not managed by this controller", "gatewayclass", gatewayclass.Name, "gateway", obj.GetName(), "namespace", obj.GetNamespace())
 			return false
@@ -67,23 +226,194 @@ func matchManagedGatewayClass(kubeclient client.Client, logger logr.Logger) func
 // SetupWithManager sets the Gateway controller to be started with the current
 // manager
 // This manager will start the following indexers:
-//   - GatewayClassName - Will be used by GatewayClass controller to define which
-//     Gateway should be reconciled in case of a change
-//   - Listeners - Will be used to define if there are conflicts with other Listeners/ListenersSet
-//   - Services - Will be used to define if a service created by this reconciler has some state change
+//   - GatewayClassName - Will be used by both controllers to define which
+//     Gateway should be reconciled in case of a change of its GatewayClass
+//   - HTTPRouteParentGateway - Will be used to find the routes attached to a
+//     Gateway, and the routes of a Gateway being deleted
+//   - GatewayCertificateSecret - Will be used to find the Gateways using a TLS
+//     certificate
 func SetupWithManager(mgr manager.Manager, options GatewayOptions) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&gatewayv1.Gateway{},
-			builder.WithPredicates(predicate.NewPredicateFuncs(
-				matchManagedGatewayClass(
-					mgr.GetClient(),
-					mgr.GetLogger().WithValues("predicate", "gateway"))))).
-		Complete(&reconciler{
-			options: options,
-			client:  mgr.GetClient(),
-			scheme:  mgr.GetScheme(),
-			logger:  mgr.GetLogger().WithValues("controller", "gateway"),
-		})
+	_, err := BuildWithManager(mgr, options)
+	return err
+}
+
+// BuildWithManager is SetupWithManager returning the controller, so additional
+// watches can be registered on it
+func BuildWithManager(mgr manager.Manager, options GatewayOptions) (controller.Controller, error) {
+	if err := indexes.AddGatewayClassName(context.Background(), mgr.GetFieldIndexer()); err != nil {
+		return nil, err
+	}
+	if err := indexes.AddHTTPRouteParentGateway(context.Background(), mgr.GetFieldIndexer()); err != nil {
+		return nil, err
+	}
+	if err := indexes.AddGatewayCertificateSecret(context.Background(), mgr.GetFieldIndexer()); err != nil {
+		return nil, err
+	}
+
+	runner := hooks.NewRunner(options.HookTimeout, mgr.GetEventRecorderFor("kgame-gateway"))
+	r := &reconciler{
+		options: options,
+		hooks: hooks.Hooks[*gatewayv1.Gateway]{
+			ReconcileHooks: options.ReconcileHooks,
+			Runner:         runner,
+		},
+		programmer: options.Programmer,
+		client:     options.Writes.Client(mgr.GetClient()),
+		scheme:     mgr.GetScheme(),
+		logger:     mgr.GetLogger().WithValues("controller", "gateway"),
+		status:     writer.NewStatusWriter(mgr.GetClient(), mgr.GetScheme(), options.Writes, ownedStatus),
+	}
+	r.finalizers = finalizer.NewManager(r.client, finalizer.Options{
+		Name:                options.FinalizerName,
+		AddFinalizerFunc:    options.AddFinalizerFunc,
+		RemoveFinalizerFunc: options.RemoveFinalizerFunc,
+		ParametersFunc:      r.classParameters,
+		Runner:              runner,
+	})
+	if r.programmer == nil && options.Translator != nil {
+		r.programmer = newTranslatorProgrammer(r, options.Translator)
+	}
+	var lanes *laneProgrammer
+	if r.programmer != nil && options.ProgramParallelism > 0 {
+		lanes = newLaneProgrammer(r, r.programmer, options.ProgramParallelism)
+		r.programmer = lanes
+		if err := mgr.Add(lanes); err != nil {
+			return nil, err
+		}
+	} else if r.programmer != nil && options.InputCache {
+		r.programmer = newInputCacheProgrammer(r, r.programmer)
+	}
+
+	var apiReader client.Reader
+	if options.ClassAPIReaderFallback {
+		apiReader = mgr.GetAPIReader()
+	}
+	predicates := append([]predicate.Predicate{
+		predicate.NewPredicateFuncs(
+			matchManagedGatewayClass(
+				mgr.GetClient(),
+				apiReader,
+				options.ControllerNames,
+				mgr.GetLogger().WithValues("predicate", "gateway"))),
+	}, options.Predicates...)
+	predicates = append(predicates, options.Filter.Predicates()...)
+
+	maxConcurrentReconciles := options.MaxConcurrentReconciles
+	if options.Settings != nil {
+		maxConcurrentReconciles = options.Settings.Ceiling()
+	}
+	b := ctrl.NewControllerManagedBy(mgr).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: maxConcurrentReconciles,
+			RateLimiter:             options.RateLimit.New(),
+			NewQueue:                options.RateLimit.NewQueue(),
+		}).
+		For(&gatewayv1.Gateway{}, builder.WithPredicates(predicates...)).
+		// The Gateways created before their GatewayClass, or waiting for it to be
+		// accepted, are reconciled once it is
+		Watches(&gatewayv1.GatewayClass{},
+			handler.EnqueueRequestsFromMapFunc(gatewaysOfClass(mgr.GetClient())),
+			builder.WithPredicates(classChanged)).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(gatewaysOfSecret(mgr.GetClient()))).
+		Watches(&gatewayv1beta1.ReferenceGrant{}, handler.EnqueueRequestsFromMapFunc(gatewaysOfReferenceGrant(mgr.GetClient())))
+	if options.Trigger != nil {
+		b = b.WatchesRawSource(source.Channel(options.Trigger, triggered(r)))
+	}
+	if lanes != nil {
+		b = b.WatchesRawSource(source.Channel(lanes.events, &handler.EnqueueRequestForObject{}))
+	}
+	if len(options.ParametersKinds) > 0 {
+		if err := indexes.AddGatewayClassParameters(context.Background(), mgr.GetFieldIndexer()); err != nil {
+			return nil, err
+		}
+		if err := indexes.AddGatewayInfrastructureParameters(context.Background(), mgr.GetFieldIndexer()); err != nil {
+			return nil, err
+		}
+	}
+	for _, gvk := range options.ParametersKinds {
+		b = b.Watches(parameters.Metadata(gvk), handler.EnqueueRequestsFromMapFunc(gatewaysOfParameters(mgr.GetClient(), gvk)))
+	}
+	if options.WatchBackends {
+		if err := indexes.AddHTTPRouteBackendService(context.Background(), mgr.GetFieldIndexer()); err != nil {
+			return nil, err
+		}
+		b = b.Watches(&corev1.Service{}, handler.EnqueueRequestsFromMapFunc(gatewaysOfService(mgr.GetClient()))).
+			Watches(&discoveryv1.EndpointSlice{}, handler.EnqueueRequestsFromMapFunc(gatewaysOfEndpointSlice(mgr.GetClient())))
+	}
+	if options.WaitForRoutesOnDelete {
+		// A Gateway being deleted waits for its routes to be removed, or to stop
+		// referencing it
+		b = b.Watches(&gatewayv1.HTTPRoute{},
+			handler.EnqueueRequestsFromMapFunc(deletingParentGatewaysOf(mgr.GetClient())),
+			builder.WithPredicates(predicate.Funcs{
+				CreateFunc:  func(event.CreateEvent) bool { return false },
+				GenericFunc: func(event.GenericEvent) bool { return false },
+			}))
+	}
+	return b.Build(options.Sharding.Wrap(health.ObserveReconciler("Gateway", options.Reporter, options.Settings.Wrap(r))))
+}
+
+// classParameters resolves the parameters of the GatewayClass of the Gateway, for
+// the finalizer hooks. Missing or invalid parameters are returned empty
+func (r *reconciler) classParameters(ctx context.Context, obj client.Object) (any, error) {
+	gw, ok := obj.(*gatewayv1.Gateway)
+	if !ok {
+		return nil, nil
+	}
+	gatewayClass := &gatewayv1.GatewayClass{}
+	if err := r.client.Get(ctx, types.NamespacedName{Name: string(gw.Spec.GatewayClassName)}, gatewayClass); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting gatewayclass: %w", err)
+	}
+	params, err := parameters.Resolve(ctx, r.client, gatewayClass)
+	if err != nil {
+		if errors.Is(err, parameters.ErrInvalidReference) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error resolving gatewayclass parameters: %w", err)
+	}
+	return params, nil
+}
+
+// hookResult converts the error of a hook into the reconciliation result. Terminal
+// errors are surfaced on the Gateway Accepted condition and are not retried
+func (r *reconciler) hookResult(ctx context.Context, gw, originalGw *gatewayv1.Gateway, err error) (reconcile.Result, error) {
+	if !hooks.IsTerminal(err) {
+		return hooks.Result(err)
+	}
+	conditions.Set(&gw.Status.Conditions, newCondition(
+		string(gatewayv1.GatewayConditionAccepted),
+		string(gatewayv1.GatewayReasonInvalid),
+		metav1.ConditionFalse,
+		err.Error(),
+		gw.Generation))
+	if patchErr := r.status.Write(ctx, gw, originalGw); patchErr != nil {
+		return reconcile.Result{}, patchErr
+	}
+	return hooks.Result(err)
+}
+
+// notifyTransitions sends the notifications of the Gateway conditions that became
+// True since originalGw
+func (r *reconciler) notifyTransitions(ctx context.Context, originalGw, gw *gatewayv1.Gateway) {
+	transitions := []struct {
+		condition    gatewayv1.GatewayConditionType
+		notification notify.Type
+	}{
+		{gatewayv1.GatewayConditionAccepted, notify.GatewayAccepted},
+		{gatewayv1.GatewayConditionProgrammed, notify.GatewayProgrammed},
+	}
+	for _, transition := range transitions {
+		if conditions.BecameTrue(originalGw.Status.Conditions, gw.Status.Conditions, string(transition.condition)) {
+			r.options.Notify.Send(ctx, notify.Notification{
+				Type:   transition.notification,
+				Name:   client.ObjectKeyFromObject(gw),
+				Object: gw.DeepCopy(),
+			})
+		}
+	}
 }
 
 // Reconcile executes the reconciliation process of this Gateway
@@ -94,6 +424,8 @@ func (r *reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 	gateway := gatewayv1.Gateway{}
 	if err := r.client.Get(ctx, req.NamespacedName, &gateway); err != nil {
 		if apierrors.IsNotFound(err) {
+			r.forgetConfig(req.NamespacedName)
+			r.options.Notify.Send(ctx, notify.Notification{Type: notify.GatewayRemoved, Name: req.NamespacedName})
 			return reconcile.Result{}, nil
 		}
 		logger.Error(err, "unable to reconcile")
@@ -103,88 +435,321 @@ func (r *reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 	originalGw := gateway.DeepCopy()
 
 	if gateway.GetDeletionTimestamp() != nil && !gateway.GetDeletionTimestamp().IsZero() {
-		if r.options.FinalizerName != "" && controllerutil.RemoveFinalizer(&gateway, r.options.FinalizerName) {
-			if r.options.RemoveFinalizerFunc != nil {
-				if err := r.options.RemoveFinalizerFunc(ctx); err != nil {
-					return reconcile.Result{}, fmt.Errorf("error executing pre-finalizer removal function: %w", err)
-				}
+		if r.options.WaitForRoutesOnDelete && r.finalizers.Contains(&gateway) {
+			wait, result, err := r.waitForRoutes(ctx, logger, &gateway)
+			if err != nil || wait {
+				return result, err
 			}
+		}
 
-			r.logger.Info("removing finalizer", "finalizer", r.options.FinalizerName)
-			return reconcile.Result{}, r.client.Patch(ctx, &gateway, client.MergeFrom(originalGw))
+		proceed, err := r.hooks.OnDelete(ctx, logger, &gateway)
+		if err != nil {
+			return r.hookResult(ctx, &gateway, originalGw, fmt.Errorf("error executing on delete hook: %w", err))
+		}
+		if !proceed {
+			logger.Info("deletion vetoed by hook")
+			return reconcile.Result{}, nil
 		}
+		if r.options.ListenerHooks != nil {
+			if err := r.removeListeners(ctx, logger, originalGw, &gateway, true); err != nil {
+				return hooks.Result(err)
+			}
+		}
+		r.forgetConfig(req.NamespacedName)
+
+		// A finalizer cannot be added to an object being deleted, so the
+		// reconciliation ends here
+		if _, err := r.finalizers.Remove(ctx, logger, &gateway); err != nil {
+			return r.hookResult(ctx, &gateway, originalGw, err)
+		}
+		return reconcile.Result{}, nil
+	}
+
+	proceed, err := r.hooks.PreReconcile(ctx, logger, &gateway)
+	if err != nil {
+		return r.hookResult(ctx, &gateway, originalGw, fmt.Errorf("error executing pre reconcile hook: %w", err))
+	}
+	if !proceed {
+		logger.Info("reconciliation vetoed by hook")
+		return reconcile.Result{}, nil
 	}
 
 	// Normal update, should try to add a finalizer if none exists
-	if r.options.FinalizerName != "" && controllerutil.AddFinalizer(&gateway, r.options.FinalizerName) {
-		if r.options.AddFinalizerFunc != nil {
-			if err := r.options.AddFinalizerFunc(ctx); err != nil {
-				return reconcile.Result{}, fmt.Errorf("error executing pre-finalizer add function: %w", err)
-			}
-		}
+	if _, err := r.finalizers.Add(ctx, logger, &gateway); err != nil {
+		return r.hookResult(ctx, &gateway, originalGw, err)
+	}
 
-		r.logger.Info("adding finalizer", "finalizer", r.options.FinalizerName)
-		if err := r.client.Patch(ctx, &gateway, client.MergeFrom(originalGw)); err != nil {
-			return reconcile.Result{}, err
+	var validationErrs []ValidationError
+	if r.options.ValidateGatewayFunc != nil {
+		errs, err := hooks.Call(ctx, r.hooks.Runner, &gateway, "ValidateGatewayFunc", func(ctx context.Context, gw *gatewayv1.Gateway) ([]ValidationError, error) {
+			return r.options.ValidateGatewayFunc(ctx, gw), nil
+		})
+		if err != nil {
+			return hooks.Result(fmt.Errorf("error executing gateway validation function: %w", err))
 		}
+		validationErrs = append(validationErrs, errs...)
+	}
+	overrides := parseOverrides(&gateway, r.options.AnnotationPrefix)
+	if r.options.ValidateOverridesFunc != nil && len(overrides) > 0 {
+		errs, err := hooks.Call(ctx, r.hooks.Runner, &gateway, "ValidateOverridesFunc", func(ctx context.Context, gw *gatewayv1.Gateway) ([]ValidationError, error) {
+			return r.options.ValidateOverridesFunc(ctx, gw, overrides), nil
+		})
+		if err != nil {
+			return hooks.Result(fmt.Errorf("error executing overrides validation function: %w", err))
+		}
+		validationErrs = append(validationErrs, errs...)
+	}
+	infrastructureParams, errs, err := r.resolveInfrastructureParameters(ctx, &gateway)
+	if err != nil {
+		return hooks.Result(fmt.Errorf("error validating the infrastructure parameters: %w", err))
 	}
+	validationErrs = append(validationErrs, errs...)
+	validation := newValidationResult(validationErrs)
 
-	mutateConditions(gateway.Status.Conditions,
-		gatewayv1.GatewayConditionAccepted,
-		gatewayv1.GatewayReasonAccepted,
+	accepted := newCondition(
+		string(gatewayv1.GatewayConditionAccepted),
+		string(gatewayv1.GatewayReasonAccepted),
 		metav1.ConditionTrue,
 		"Gateway is accepted",
 		gateway.Generation)
+	if validation.gatewayInvalid() {
+		accepted = newCondition(
+			string(gatewayv1.GatewayConditionAccepted),
+			validation.gatewayReason,
+			metav1.ConditionFalse,
+			validation.gatewayMessage,
+			gateway.Generation)
+	} else if len(validation.listeners) > 0 {
+		accepted = newCondition(
+			string(gatewayv1.GatewayConditionAccepted),
+			string(gatewayv1.GatewayReasonListenersNotValid),
+			metav1.ConditionTrue,
+			"Gateway is accepted, but some listeners are not valid",
+			gateway.Generation)
+	}
+
+	// Removed listeners are dropped from the status, so they must be removed from
+	// the dataplane before the status is patched
+	if r.options.ListenerHooks != nil {
+		if err := r.removeListeners(ctx, logger, originalGw, &gateway, false); err != nil {
+			return hooks.Result(err)
+		}
+	}
+
+	certificates, err := r.resolveListenersCertificates(ctx, &gateway)
+	if err != nil {
+		return reconcile.Result{}, fmt.Errorf("error resolving the certificates of %s: %w", req.String(), err)
+	}
+
+	gatewayConditions := conditions.NewTracker(&gateway.Status.Conditions, ownedGatewayConditions...)
+	gatewayConditions.Set(accepted)
 
-	if err := r.client.Status().Patch(ctx, &gateway, client.MergeFrom(originalGw)); err != nil {
+	syncListenersStatus(&gateway)
+	listenerConditions := make([]*conditions.Tracker, len(gateway.Status.Listeners))
+	for i := range gateway.Status.Listeners {
+		listenerConditions[i] = conditions.NewTracker(&gateway.Status.Listeners[i].Conditions, ownedListenerConditions...)
+		if invalid, ok := validation.listeners[gateway.Status.Listeners[i].Name]; ok {
+			listenerConditions[i].Set(newCondition(
+				string(gatewayv1.ListenerConditionAccepted),
+				invalid.Reason,
+				metav1.ConditionFalse,
+				invalid.Message,
+				gateway.Generation))
+		} else {
+			listenerConditions[i].Set(newCondition(
+				string(gatewayv1.ListenerConditionAccepted),
+				string(gatewayv1.ListenerReasonAccepted),
+				metav1.ConditionTrue,
+				"Listener is accepted",
+				gateway.Generation))
+		}
+		// The listeners rejected for a conflict are Conflicted, any other gets the
+		// healthy Conflicted=False
+		if invalid, ok := validation.listeners[gateway.Status.Listeners[i].Name]; ok && conflictReason(invalid.Reason) {
+			listenerConditions[i].Set(newCondition(
+				string(gatewayv1.ListenerConditionConflicted),
+				invalid.Reason,
+				metav1.ConditionTrue,
+				invalid.Message,
+				gateway.Generation))
+		} else {
+			listenerConditions[i].Set(conditions.Healthy(
+				string(gatewayv1.ListenerConditionConflicted),
+				string(gatewayv1.ListenerReasonNoConflicts),
+				"Listener has no conflicts",
+				gateway.Generation))
+		}
+		if certs := certificates[gateway.Status.Listeners[i].Name]; !certs.resolved() {
+			listenerConditions[i].Set(newCondition(
+				string(gatewayv1.ListenerConditionResolvedRefs),
+				certs.reason,
+				metav1.ConditionFalse,
+				certs.message,
+				gateway.Generation))
+		} else {
+			listenerConditions[i].Set(newCondition(
+				string(gatewayv1.ListenerConditionResolvedRefs),
+				string(gatewayv1.ListenerReasonResolvedRefs),
+				metav1.ConditionTrue,
+				"Listener references are resolved",
+				gateway.Generation))
+		}
+	}
+
+	if err := r.status.Write(ctx, &gateway, originalGw); err != nil {
 		return reconcile.Result{}, fmt.Errorf("error adding accepted condition on %s: %w", req.String(), err)
 	}
+	// The programmed conditions are written over, and compared with, the
+	// accepted ones
+	acceptedGw := gateway.DeepCopy()
 
 	// Call the programming logic of the gateway, then mutate the conditions for programmed
 	// TODO: should this be added to a retry on conflict? If something changed probably we
 	// want a full loop here
-	mutateConditions(gateway.Status.Conditions,
-		gatewayv1.GatewayConditionProgrammed,
-		gatewayv1.GatewayReasonProgrammed,
+	programmed := newCondition(
+		string(gatewayv1.GatewayConditionProgrammed),
+		string(gatewayv1.GatewayReasonProgrammed),
 		metav1.ConditionTrue,
 		"Gateway is programmed",
 		gateway.Generation)
 
-	if err := r.client.Status().Patch(ctx, &gateway, client.MergeFrom(originalGw)); err != nil {
-		return reconcile.Result{}, fmt.Errorf("error adding programmed condition on %s: %w", req.String(), err)
+	snapshot, err := r.buildSnapshot(ctx, &gateway)
+	if err != nil {
+		return reconcile.Result{}, fmt.Errorf("error building the snapshot of %s: %w", req.String(), err)
+	}
+	snapshot.Overrides = overrides
+	snapshot.InfrastructureParameters = infrastructureParams
+	for i := range gateway.Status.Listeners {
+		gateway.Status.Listeners[i].AttachedRoutes = int32(len(snapshot.HTTPRoutes[gateway.Status.Listeners[i].Name]))
 	}
 
-	return reconcile.Result{}, nil
-}
+	var listenersProgrammed map[gatewayv1.SectionName]metav1.Condition
+	var addressCondition *metav1.Condition
+	var programErr error
+	if !validation.gatewayInvalid() {
+		addressCondition, programErr = r.resolveAddresses(ctx, &gateway)
+	}
+	if validation.gatewayInvalid() {
+		programmed = newCondition(
+			string(gatewayv1.GatewayConditionProgrammed),
+			string(gatewayv1.GatewayReasonInvalid),
+			metav1.ConditionFalse,
+			"Gateway is not accepted",
+			gateway.Generation)
+	} else if addressCondition != nil {
+		programmed = *addressCondition
+	} else if r.programmer != nil {
+		result, err := hooks.Call(ctx, r.hooks.Runner, &gateway, "Program", func(ctx context.Context, gw *gatewayv1.Gateway) (ProgramResult, error) {
+			return r.programmer.Program(ctx, gw, snapshot)
+		})
+		if _, requeue := hooks.IsRequeue(err); requeue {
+			programmed = newCondition(
+				string(gatewayv1.GatewayConditionProgrammed),
+				string(gatewayv1.GatewayReasonPending),
+				metav1.ConditionFalse,
+				"Gateway is waiting to be programmed",
+				gateway.Generation)
+			programErr = err
+		} else if err != nil {
+			programmed = newCondition(
+				string(gatewayv1.GatewayConditionProgrammed),
+				string(gatewayv1.GatewayReasonInvalid),
+				metav1.ConditionFalse,
+				fmt.Sprintf("error programming the Gateway: %s", err),
+				gateway.Generation)
+			programErr = fmt.Errorf("error executing programmer: %w", err)
+		} else {
+			programmed, listenersProgrammed = applyProgramResult(&gateway, result, programmed, r.options.AddressProviderFunc == nil)
+		}
+	}
+	if r.options.DataplaneReadyFunc != nil && programmed.Status == metav1.ConditionTrue {
+		condition, err := r.checkDataplane(ctx, &gateway)
+		if condition != nil {
+			programmed = *condition
+		}
+		if err != nil && programErr == nil {
+			programErr = err
+		}
+	}
+	gatewayConditions.Set(programmed)
+	if r.options.DriftStatusFunc != nil {
+		condition, err := r.checkDrift(ctx, &gateway)
+		if condition != nil {
+			gatewayConditions.Set(*condition)
+		}
+		if err != nil && programErr == nil {
+			programErr = err
+		}
+	}
 
-// mutateConditions mutates in place conditions.
-func mutateConditions(conditions []metav1.Condition,
-	condtype gatewayv1.GatewayConditionType,
-	reason gatewayv1.GatewayConditionReason,
-	status metav1.ConditionStatus,
-	message string,
-	generation int64) []metav1.Condition {
+	if listenersProgrammed == nil {
+		listenersProgrammed = make(map[gatewayv1.SectionName]metav1.Condition)
+	}
+	for i := range listenerConditions {
+		name := gateway.Status.Listeners[i].Name
+		listenerProgrammed, ok := listenersProgrammed[name]
+		if _, invalid := validation.listeners[name]; invalid || validation.gatewayInvalid() {
+			listenerProgrammed = newCondition(
+				string(gatewayv1.ListenerConditionProgrammed),
+				string(gatewayv1.ListenerReasonInvalid),
+				metav1.ConditionFalse,
+				"Listener is not accepted",
+				gateway.Generation)
+		} else if !certificates[name].resolved() {
+			listenerProgrammed = newCondition(
+				string(gatewayv1.ListenerConditionProgrammed),
+				string(gatewayv1.ListenerReasonInvalid),
+				metav1.ConditionFalse,
+				"Listener certificate references are not resolved",
+				gateway.Generation)
+		} else if !ok {
+			listenerProgrammed = newCondition(
+				string(gatewayv1.ListenerConditionProgrammed),
+				string(gatewayv1.ListenerReasonProgrammed),
+				metav1.ConditionTrue,
+				"Listener is programmed",
+				gateway.Generation)
+		}
+		listenersProgrammed[name] = listenerProgrammed
+	}
+	if r.options.ExposedPortsFunc != nil && programmed.Status == metav1.ConditionTrue {
+		if err := r.reportExposedPorts(ctx, &gateway, listenersProgrammed); err != nil && programErr == nil {
+			programErr = err
+		}
+	}
+	if r.options.ListenerHooks != nil && programmed.Status == metav1.ConditionTrue {
+		if err := r.programListeners(ctx, &gateway, snapshot, listenersProgrammed); err != nil && programErr == nil {
+			programErr = err
+		}
+	}
+	for i := range listenerConditions {
+		listenerConditions[i].Set(listenersProgrammed[gateway.Status.Listeners[i].Name])
+		listenerConditions[i].Prune()
+	}
+	// Conditions owned by kgame that were not set on this loop are stale, and
+	// should be removed before the final patch
+	gatewayConditions.Prune()
 
-	var found bool
+	if err := r.status.Write(ctx, &gateway, acceptedGw); err != nil {
+		return reconcile.Result{}, fmt.Errorf("error adding programmed condition on %s: %w", req.String(), err)
+	}
+	r.notifyTransitions(ctx, originalGw, &gateway)
 
-	newCondition := metav1.Condition{
-		Type:               string(condtype),
-		Status:             status,
-		Reason:             string(reason),
-		Message:            message,
-		LastTransitionTime: metav1.Now(),
-		ObservedGeneration: generation,
+	if programErr != nil {
+		return hooks.Result(programErr)
 	}
 
-	for i := range conditions {
-		if conditions[i].Type == string(condtype) {
-			conditions[i] = newCondition
-			found = true
-			break
-		}
+	if err := r.hooks.PostReconcile(ctx, logger, &gateway); err != nil {
+		return hooks.Result(fmt.Errorf("error executing post reconcile hook: %w", err))
 	}
-	if !found {
-		conditions = append(conditions, newCondition)
+
+	return reconcile.Result{RequeueAfter: r.resyncPeriod()}, nil
+}
+
+// resyncPeriod returns the ResyncPeriod, or the one set at runtime on the Settings
+func (r *reconciler) resyncPeriod() time.Duration {
+	if r.options.Settings != nil {
+		return r.options.Settings.ResyncPeriod()
 	}
-	return conditions
+	return r.options.ResyncPeriod
 }
@@ -0,0 +1,26 @@
+package gateway
+
+import (
+	"context"
+
+	"github.com/rikatz/kgame/pkg/hooks"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// ReconcileHooks can be implemented to observe and extend the reconciliation of
+// the Gateways without forking the reconciler. See hooks.ReconcileHooks
+type ReconcileHooks = hooks.ReconcileHooks[*gatewayv1.Gateway]
+
+// ListenerHooks can be implemented by dataplanes with per-listener objects, like
+// one Envoy listener each, to program every listener on its own
+type ListenerHooks interface {
+	// OnListenerProgrammed is called on each reconciliation of the Gateway, after
+	// the Programmer, for each listener considered programmed. An error sets the
+	// listener Programmed condition as False and the reconciliation is retried,
+	// unless it is a terminal error
+	OnListenerProgrammed(ctx context.Context, gw *gatewayv1.Gateway, listener CompiledListener) error
+	// OnListenerRemoved is called when a listener previously programmed is removed
+	// from the Gateway, and for each programmed listener when the Gateway is
+	// deleted. An error retries the reconciliation
+	OnListenerRemoved(ctx context.Context, gw *gatewayv1.Gateway, listener gatewayv1.SectionName) error
+}
@@ -0,0 +1,79 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rikatz/kgame/pkg/hooks"
+	"github.com/rikatz/kgame/pkg/indexes"
+	"github.com/rikatz/kgame/pkg/parameters"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// ValidateInfrastructureParametersFunc is called with the object referenced by the
+// Gateway spec.infrastructure.parametersRef, once it is resolved. The params are
+// the typed object when its kind is registered on the manager scheme, like a
+// ConfigMap, or an unstructured object otherwise. Returning an error sets the
+// Gateway Accepted condition as False with reason InvalidParameters, unless it is
+// a RequeueAfter error (see hooks.RequeueAfter). If empty the resolved parameters
+// are accepted without further check
+type ValidateInfrastructureParametersFunc func(ctx context.Context, gw *gatewayv1.Gateway, params any) error
+
+// resolveInfrastructureParameters resolves the Gateway infrastructure
+// parametersRef and calls the ValidateInfrastructureParametersFunc. It returns
+// the resolved parameters, and a ValidationError when they are not valid
+func (r *reconciler) resolveInfrastructureParameters(ctx context.Context, gw *gatewayv1.Gateway) (any, []ValidationError, error) {
+	params, err := parameters.ResolveInfrastructure(ctx, r.client, gw)
+	if err != nil {
+		if errors.Is(err, parameters.ErrInvalidReference) {
+			return nil, []ValidationError{invalidParameters(err.Error())}, nil
+		}
+		return nil, nil, fmt.Errorf("error resolving the infrastructure parameters: %w", err)
+	}
+	if params == nil {
+		return nil, nil, nil
+	}
+	if r.options.ValidateInfrastructureParametersFunc == nil {
+		return params, nil, nil
+	}
+
+	if err := hooks.Run(ctx, r.hooks.Runner, gw, "ValidateInfrastructureParametersFunc", func(ctx context.Context, gw *gatewayv1.Gateway) error {
+		return r.options.ValidateInfrastructureParametersFunc(ctx, gw, params)
+	}); err != nil {
+		if _, requeue := hooks.IsRequeue(err); requeue {
+			return nil, nil, err
+		}
+		return nil, []ValidationError{invalidParameters(err.Error())}, nil
+	}
+	return params, nil, nil
+}
+
+// invalidParameters returns the ValidationError of a Gateway whose infrastructure
+// parameters are not valid
+func invalidParameters(message string) ValidationError {
+	return ValidationError{
+		Reason:  string(gatewayv1.GatewayReasonInvalidParameters),
+		Message: message,
+	}
+}
+
+// gatewaysOfInfrastructureParameters returns the reconcile requests of the
+// Gateways referencing the parameters object of the given kind on their
+// spec.infrastructure.parametersRef
+func gatewaysOfInfrastructureParameters(ctx context.Context, kubeclient client.Client, gvk schema.GroupVersionKind, obj client.Object) []reconcile.Request {
+	gateways := &gatewayv1.GatewayList{}
+	if err := kubeclient.List(ctx, gateways, client.MatchingFields{
+		indexes.GatewayInfrastructureParameters: indexes.ParametersKey(gvk.Group, gvk.Kind, obj.GetNamespace(), obj.GetName()),
+	}); err != nil {
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(gateways.Items))
+	for i := range gateways.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&gateways.Items[i])})
+	}
+	return requests
+}
@@ -0,0 +1,159 @@
+package gateway
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// programmedInputs is the last hash of the inputs programmed for a Gateway, and
+// the result of programming them
+type programmedInputs struct {
+	hash   string
+	result ProgramResult
+}
+
+// inputCacheProgrammer only calls the Programmer when the hash of the Gateway
+// resolved inputs changed since the last successful call, see
+// GatewayOptions.InputCache
+type inputCacheProgrammer struct {
+	reconciler *reconciler
+	programmer Programmer
+
+	mu         sync.Mutex
+	programmed map[types.NamespacedName]programmedInputs
+}
+
+func newInputCacheProgrammer(r *reconciler, programmer Programmer) *inputCacheProgrammer {
+	return &inputCacheProgrammer{
+		reconciler: r,
+		programmer: programmer,
+		programmed: make(map[types.NamespacedName]programmedInputs),
+	}
+}
+
+// Program calls the Programmer if the Gateway inputs changed since the last
+// successful call, returning the last result otherwise
+func (p *inputCacheProgrammer) Program(ctx context.Context, gw *gatewayv1.Gateway, snapshot Snapshot) (ProgramResult, error) {
+	key := types.NamespacedName{Namespace: gw.GetNamespace(), Name: gw.GetName()}
+	hash, err := p.reconciler.hashInputs(ctx, gw, snapshot)
+	if err != nil {
+		// The inputs are programmed without caching, as they cannot be compared
+		p.forget(key)
+		p.reconciler.logger.V(4).Info("unable to hash the gateway inputs", "gateway", key, "error", err)
+		return p.programmer.Program(ctx, gw, snapshot)
+	}
+
+	p.mu.Lock()
+	last, ok := p.programmed[key]
+	p.mu.Unlock()
+	if ok && last.hash == hash {
+		return last.result, nil
+	}
+
+	result, err := p.programmer.Program(ctx, gw, snapshot)
+	if err != nil {
+		p.forget(key)
+		return result, err
+	}
+	p.mu.Lock()
+	p.programmed[key] = programmedInputs{hash: hash, result: result}
+	p.mu.Unlock()
+	return result, nil
+}
+
+// forget drops the inputs programmed for a Gateway
+func (p *inputCacheProgrammer) forget(key types.NamespacedName) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.programmed, key)
+}
+
+// gatewayInputs are the resolved inputs of a Gateway. The Gateway API resources
+// are hashed by their spec, so their status writes do not change the hash, and
+// the core resources by their resourceVersion
+type gatewayInputs struct {
+	Spec            gatewayv1.GatewaySpec      `json:"spec"`
+	Labels          map[string]string          `json:"labels,omitempty"`
+	Annotations     map[string]string          `json:"annotations,omitempty"`
+	GatewayClass    gatewayv1.GatewayClassSpec `json:"gatewayClass"`
+	Parameters      any                        `json:"parameters,omitempty"`
+	Infrastructure  any                        `json:"infrastructure,omitempty"`
+	HTTPRoutes      map[string][]routeInputs   `json:"httpRoutes,omitempty"`
+	Backends        map[string]string          `json:"backends,omitempty"`
+	EndpointSlices  map[string]string          `json:"endpointSlices,omitempty"`
+	TLSCertificates map[string]string          `json:"tlsCertificates,omitempty"`
+}
+
+type routeInputs struct {
+	Name string                  `json:"name"`
+	Spec gatewayv1.HTTPRouteSpec `json:"spec"`
+}
+
+// hashInputs returns the hash of the Gateway spec, GatewayClass, parameters,
+// infrastructure parameters, attached routes, backends and TLS certificates.
+// The EndpointSlices of the backends are hashed when WatchBackends is set
+func (r *reconciler) hashInputs(ctx context.Context, gw *gatewayv1.Gateway, snapshot Snapshot) (string, error) {
+	model, err := r.buildModel(ctx, gw, snapshot)
+	if err != nil {
+		return "", err
+	}
+
+	inputs := gatewayInputs{
+		Spec:            gw.Spec,
+		Labels:          gw.GetLabels(),
+		Annotations:     gw.GetAnnotations(),
+		GatewayClass:    snapshot.GatewayClass.Spec,
+		Parameters:      snapshot.Parameters,
+		Infrastructure:  snapshot.InfrastructureParameters,
+		HTTPRoutes:      make(map[string][]routeInputs, len(snapshot.HTTPRoutes)),
+		Backends:        make(map[string]string, len(model.Backends)),
+		EndpointSlices:  make(map[string]string),
+		TLSCertificates: make(map[string]string, len(model.TLSCertificates)),
+	}
+	for listener, routes := range snapshot.HTTPRoutes {
+		attached := make([]routeInputs, 0, len(routes))
+		for _, route := range routes {
+			attached = append(attached, routeInputs{
+				Name: client.ObjectKeyFromObject(&route).String(),
+				Spec: route.Spec,
+			})
+		}
+		slices.SortFunc(attached, func(a, b routeInputs) int { return strings.Compare(a.Name, b.Name) })
+		inputs.HTTPRoutes[string(listener)] = attached
+	}
+	for key, service := range model.Backends {
+		inputs.Backends[key.String()] = service.GetResourceVersion()
+		if !r.options.WatchBackends {
+			continue
+		}
+		endpointSlices := &discoveryv1.EndpointSliceList{}
+		if err := r.client.List(ctx, endpointSlices, client.InNamespace(key.Namespace), client.MatchingLabels{discoveryv1.LabelServiceName: key.Name}); err != nil {
+			return "", fmt.Errorf("error listing the endpointslices of %s: %w", key, err)
+		}
+		for i := range endpointSlices.Items {
+			inputs.EndpointSlices[client.ObjectKeyFromObject(&endpointSlices.Items[i]).String()] = endpointSlices.Items[i].GetResourceVersion()
+		}
+	}
+	for key, secret := range model.TLSCertificates {
+		inputs.TLSCertificates[key.String()] = secret.GetResourceVersion()
+	}
+
+	// The maps are encoded with sorted keys
+	encoded, err := json.Marshal(inputs)
+	if err != nil {
+		return "", fmt.Errorf("error encoding the gateway inputs: %w", err)
+	}
+	hash := sha256.Sum256(encoded)
+	return hex.EncodeToString(hash[:]), nil
+}
@@ -0,0 +1,202 @@
+package gateway
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-logr/logr"
+	"github.com/rikatz/kgame/pkg/hooks"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// errProgramming is returned while a Gateway is programmed on its lane. The
+// Gateway is set as Pending, and reconciled again once its lane finishes
+var errProgramming = hooks.RequeueAfter(0)
+
+// lane is the programming state of a Gateway
+type lane struct {
+	// running is the hash of the inputs being programmed, empty when idle
+	running string
+	// next are the inputs to program once the running ones finish, when they
+	// changed meanwhile
+	next *laneInputs
+	// hash, result and err are the outcome of the last programmed inputs
+	hash   string
+	result ProgramResult
+	err    error
+	done   bool
+	// reprogram programs the running inputs again once they finish, see
+	// invalidate
+	reprogram bool
+}
+
+type laneInputs struct {
+	hash     string
+	gw       *gatewayv1.Gateway
+	snapshot Snapshot
+}
+
+// laneProgrammer calls the Programmer on background lanes, see
+// GatewayOptions.ProgramParallelism. Each Gateway is programmed by one lane at a
+// time, and up to the parallelism Gateways are programmed at once, so a slow
+// dataplane push does not block the reconciliations of the other Gateways. It is
+// a manager Runnable: the lanes run until the manager stops, which waits for the
+// in-flight ones before the OnShutdown hooks are called
+type laneProgrammer struct {
+	reconciler *reconciler
+	programmer Programmer
+	// slots bounds the Gateways programmed in parallel
+	slots chan struct{}
+	// events reconciles the Gateways once their lane finishes
+	events chan event.GenericEvent
+	// ctx is the context of the lanes, cancelled when the manager stops
+	ctx    context.Context
+	cancel context.CancelFunc
+	// running are the in-flight lanes
+	running sync.WaitGroup
+
+	mu      sync.Mutex
+	lanes   map[types.NamespacedName]*lane
+	stopped bool
+}
+
+func newLaneProgrammer(r *reconciler, programmer Programmer, parallelism int) *laneProgrammer {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &laneProgrammer{
+		reconciler: r,
+		programmer: programmer,
+		slots:      make(chan struct{}, parallelism),
+		events:     make(chan event.GenericEvent, parallelism),
+		ctx:        ctx,
+		cancel:     cancel,
+		lanes:      make(map[types.NamespacedName]*lane),
+	}
+}
+
+// Start blocks until ctx is done, then cancels the lanes and waits for the
+// in-flight ones to return
+func (p *laneProgrammer) Start(ctx context.Context) error {
+	<-ctx.Done()
+	p.mu.Lock()
+	p.stopped = true
+	p.mu.Unlock()
+	p.cancel()
+	p.running.Wait()
+	return nil
+}
+
+// Program returns the outcome of the last programmed inputs when they did not
+// change, and otherwise starts programming them on the Gateway lane, returning
+// errProgramming. Failures are returned once, so the next reconciliation
+// programs the Gateway again
+func (p *laneProgrammer) Program(ctx context.Context, gw *gatewayv1.Gateway, snapshot Snapshot) (ProgramResult, error) {
+	key := types.NamespacedName{Namespace: gw.GetNamespace(), Name: gw.GetName()}
+	hash, err := p.reconciler.hashInputs(ctx, gw, snapshot)
+	if err != nil {
+		return ProgramResult{}, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	l, ok := p.lanes[key]
+	if !ok {
+		l = &lane{}
+		p.lanes[key] = l
+	}
+	inputs := &laneInputs{hash: hash, gw: gw.DeepCopy(), snapshot: snapshot}
+	switch {
+	case l.running != "":
+		if l.running != hash {
+			l.next = inputs
+		} else {
+			l.next = nil
+		}
+		return ProgramResult{}, errProgramming
+	case l.done && l.hash == hash:
+		if l.err != nil {
+			err := l.err
+			l.done = false
+			return ProgramResult{}, err
+		}
+		return l.result, nil
+	}
+	p.start(ctx, key, l, inputs)
+	return ProgramResult{}, errProgramming
+}
+
+// start programs the inputs on the lane. It is called with the lock held. No
+// lane is started once the manager stops
+func (p *laneProgrammer) start(ctx context.Context, key types.NamespacedName, l *lane, inputs *laneInputs) {
+	if p.stopped {
+		return
+	}
+	l.running = inputs.hash
+	l.next = nil
+	l.reprogram = false
+	// The lane outlives the reconciliation, keeping its logger
+	ctx = logr.NewContext(p.ctx, logr.FromContextOrDiscard(ctx))
+	p.running.Add(1)
+	go func() {
+		defer p.running.Done()
+		select {
+		case p.slots <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+		result, err := hooks.Call(ctx, p.reconciler.hooks.Runner, inputs.gw, "Program", func(ctx context.Context, gw *gatewayv1.Gateway) (ProgramResult, error) {
+			return p.programmer.Program(ctx, gw, inputs.snapshot)
+		})
+		<-p.slots
+
+		p.mu.Lock()
+		if p.lanes[key] != l {
+			// The Gateway was forgotten meanwhile
+			p.mu.Unlock()
+			return
+		}
+		l.running = ""
+		next := l.next
+		if next == nil && l.reprogram {
+			next = inputs
+		}
+		if next != nil {
+			p.start(ctx, key, l, next)
+			p.mu.Unlock()
+			return
+		}
+		l.hash, l.result, l.err, l.done = inputs.hash, result, err, true
+		p.mu.Unlock()
+
+		select {
+		case p.events <- event.GenericEvent{Object: inputs.gw}:
+		case <-ctx.Done():
+		}
+	}()
+}
+
+// forget drops the lane of a Gateway. A running lane finishes, but its outcome
+// is discarded
+func (p *laneProgrammer) forget(key types.NamespacedName) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.lanes, key)
+}
+
+// invalidate programs the Gateway again, even if its inputs did not change. The
+// inputs of a running lane are programmed again once it finishes, so the
+// Gateway is still programmed by one lane at a time
+func (p *laneProgrammer) invalidate(key types.NamespacedName) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	l, ok := p.lanes[key]
+	if !ok {
+		return
+	}
+	if l.running != "" {
+		l.reprogram = true
+		return
+	}
+	l.done = false
+}
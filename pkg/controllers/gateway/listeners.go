@@ -0,0 +1,110 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/rikatz/kgame/pkg/hooks"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// CompiledListener is a Gateway listener with its resolved references, as passed
+// to the ListenerHooks
+type CompiledListener struct {
+	// Name of the listener
+	Name gatewayv1.SectionName
+	// Hostname of the listener. Empty matches all the hostnames
+	Hostname *gatewayv1.Hostname
+	Port     gatewayv1.PortNumber
+	Protocol gatewayv1.ProtocolType
+	// TLS is the listener TLS configuration, if any
+	TLS *gatewayv1.GatewayTLSConfig
+	// Certificates are the Secrets referenced by the TLS configuration. The
+	// references not resolved, reported on the listener ResolvedRefs condition,
+	// are not present
+	Certificates []*corev1.Secret
+	// HTTPRoutes are the HTTPRoutes attached to the listener
+	HTTPRoutes []gatewayv1.HTTPRoute
+}
+
+// compileListener resolves the references of the listener
+func (r *reconciler) compileListener(ctx context.Context, gw *gatewayv1.Gateway, listener gatewayv1.Listener, snapshot Snapshot) (CompiledListener, error) {
+	compiled := CompiledListener{
+		Name:       listener.Name,
+		Hostname:   listener.Hostname,
+		Port:       listener.Port,
+		Protocol:   listener.Protocol,
+		TLS:        listener.TLS,
+		HTTPRoutes: snapshot.HTTPRoutes[listener.Name],
+	}
+	certificates, err := r.resolveCertificates(ctx, gw, listener)
+	if err != nil {
+		return compiled, err
+	}
+	compiled.Certificates = certificates.secrets
+	return compiled, nil
+}
+
+// programListeners calls OnListenerProgrammed for the listeners with a True
+// Programmed condition, on listenersProgrammed. A failed listener has its
+// condition replaced, and the first error is returned
+func (r *reconciler) programListeners(ctx context.Context, gw *gatewayv1.Gateway, snapshot Snapshot, listenersProgrammed map[gatewayv1.SectionName]metav1.Condition) error {
+	var firstErr error
+	for _, listener := range gw.Spec.Listeners {
+		condition, ok := listenersProgrammed[listener.Name]
+		if !ok || condition.Status != metav1.ConditionTrue {
+			continue
+		}
+		compiled, err := r.compileListener(ctx, gw, listener, snapshot)
+		if err == nil {
+			err = hooks.Run(ctx, r.hooks.Runner, gw, "OnListenerProgrammed", func(ctx context.Context, gw *gatewayv1.Gateway) error {
+				return r.options.ListenerHooks.OnListenerProgrammed(ctx, gw, compiled)
+			})
+		}
+		if err == nil {
+			continue
+		}
+		reason := gatewayv1.ListenerReasonInvalid
+		if _, requeue := hooks.IsRequeue(err); requeue {
+			reason = gatewayv1.ListenerReasonPending
+		}
+		listenersProgrammed[listener.Name] = newCondition(
+			string(gatewayv1.ListenerConditionProgrammed),
+			string(reason),
+			metav1.ConditionFalse,
+			fmt.Sprintf("error programming the listener: %s", err),
+			gw.Generation)
+		if firstErr == nil {
+			firstErr = fmt.Errorf("error executing listener %s programmed hook: %w", listener.Name, err)
+		}
+	}
+	return firstErr
+}
+
+// removeListeners calls OnListenerRemoved for the listeners programmed on
+// originalGw that are not on the spec of gw. When all is true every programmed
+// listener is removed, as when the Gateway is deleted
+func (r *reconciler) removeListeners(ctx context.Context, logger logr.Logger, originalGw, gw *gatewayv1.Gateway, all bool) error {
+	current := make(map[gatewayv1.SectionName]bool, len(gw.Spec.Listeners))
+	if !all {
+		for _, listener := range gw.Spec.Listeners {
+			current[listener.Name] = true
+		}
+	}
+	for _, status := range originalGw.Status.Listeners {
+		if current[status.Name] || !meta.IsStatusConditionTrue(status.Conditions, string(gatewayv1.ListenerConditionProgrammed)) {
+			continue
+		}
+		logger.Info("removing listener", "listener", status.Name)
+		if err := hooks.Run(ctx, r.hooks.Runner, gw, "OnListenerRemoved", func(ctx context.Context, gw *gatewayv1.Gateway) error {
+			return r.options.ListenerHooks.OnListenerRemoved(ctx, gw, status.Name)
+		}); err != nil {
+			return fmt.Errorf("error executing listener %s removed hook: %w", status.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package gateway
+
+import (
+	"context"
+	"strings"
+
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// Overrides are the per-Gateway settings set through the annotations with the
+// GatewayOptions AnnotationPrefix, by annotation name without the prefix. For
+// the prefix "mylab.tld/", the annotation "mylab.tld/proxy-timeout" is the
+// override "proxy-timeout"
+type Overrides map[string]string
+
+// ValidateOverridesFunc is called with the overrides of a Gateway before it is
+// accepted. The errors are handled as the ValidateGatewayFunc errors, so an
+// invalid override sets the Gateway Accepted condition as False.
+// If empty all the overrides are accepted
+type ValidateOverridesFunc func(ctx context.Context, gw *gatewayv1.Gateway, overrides Overrides) []ValidationError
+
+// parseOverrides returns the overrides of the Gateway annotations with prefix. A
+// prefix without a trailing slash is considered a domain, so "mylab.tld" is the
+// same as "mylab.tld/"
+func parseOverrides(gw *gatewayv1.Gateway, prefix string) Overrides {
+	if prefix == "" {
+		return nil
+	}
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	var overrides Overrides
+	for key, value := range gw.GetAnnotations() {
+		name, ok := strings.CutPrefix(key, prefix)
+		if !ok || name == "" {
+			continue
+		}
+		if overrides == nil {
+			overrides = make(Overrides)
+		}
+		overrides[name] = value
+	}
+	return overrides
+}
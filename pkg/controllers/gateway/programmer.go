@@ -0,0 +1,187 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rikatz/kgame/pkg/attachment"
+	"github.com/rikatz/kgame/pkg/hooks"
+	"github.com/rikatz/kgame/pkg/indexes"
+	"github.com/rikatz/kgame/pkg/parameters"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// Programmer is the primary extension point for dataplane implementations. It is
+// called on each reconciliation of an accepted Gateway, and the returned
+// ProgramResult is converted by kgame to the Gateway status.
+// An error sets the Gateway Programmed condition as False and the reconciliation
+// is retried, unless it is a terminal error (see hooks.ErrTerminal)
+type Programmer interface {
+	Program(ctx context.Context, gw *gatewayv1.Gateway, snapshot Snapshot) (ProgramResult, error)
+}
+
+// ClassProgrammers programs each Gateway with the Programmer of its GatewayClass
+// controllerName, for controllers managing more than one class with different
+// dataplanes. A Gateway of a class without a Programmer is not programmed, with a
+// terminal error
+type ClassProgrammers map[gatewayv1.GatewayController]Programmer
+
+func (p ClassProgrammers) Program(ctx context.Context, gw *gatewayv1.Gateway, snapshot Snapshot) (ProgramResult, error) {
+	programmer, ok := p[snapshot.GatewayClass.Spec.ControllerName]
+	if !ok || programmer == nil {
+		return ProgramResult{}, hooks.Terminal(fmt.Errorf("no programmer for the controllerName %q", snapshot.GatewayClass.Spec.ControllerName))
+	}
+	return programmer.Program(ctx, gw, snapshot)
+}
+
+// Snapshot contains the resources related to a Gateway at the moment it is
+// programmed, so the Programmer does not need to fetch them again
+type Snapshot struct {
+	// GatewayClass of the Gateway
+	GatewayClass *gatewayv1.GatewayClass
+	// Parameters is the object referenced by the GatewayClass parametersRef. It is
+	// only resolved, not validated: the GatewayClass ValidateParametersFunc result
+	// is reflected on the GatewayClass Accepted condition instead. Empty when the
+	// GatewayClass has no parameters, or they cannot be resolved
+	Parameters any
+	// HTTPRoutes are the HTTPRoutes attached to each listener of the Gateway, by
+	// listener name
+	HTTPRoutes map[gatewayv1.SectionName][]gatewayv1.HTTPRoute
+	// Overrides are the Gateway annotation overrides, as validated by the
+	// ValidateOverridesFunc. Empty when the AnnotationPrefix is not set
+	Overrides Overrides
+	// InfrastructureParameters is the object referenced by the Gateway
+	// spec.infrastructure.parametersRef, as validated by the
+	// ValidateInfrastructureParametersFunc. Empty when the Gateway has no
+	// infrastructure parameters
+	InfrastructureParameters any
+}
+
+// ProgramResult is the outcome of programming a Gateway on the dataplane
+type ProgramResult struct {
+	// Addresses assigned to the Gateway by the dataplane. When not empty they take
+	// precedence over the addresses returned by the AddressProviderFunc. Without
+	// an AddressProviderFunc, the Gateway is not programmed until they include
+	// the requested spec.addresses
+	Addresses []gatewayv1.GatewayStatusAddress
+	// Listeners contains the outcome of each listener, by listener name. Listeners
+	// not present are considered programmed
+	Listeners map[gatewayv1.SectionName]ListenerResult
+	// Message is an optional message for the Gateway Programmed condition
+	Message string
+}
+
+// ListenerResult is the outcome of programming a Gateway listener
+type ListenerResult struct {
+	// Programmed defines if the listener was programmed on the dataplane
+	Programmed bool
+	// Reason of the listener Programmed condition. Defaults to Programmed or
+	// Invalid depending on the outcome
+	Reason gatewayv1.ListenerConditionReason
+	// Message of the listener Programmed condition
+	Message string
+}
+
+// buildSnapshot builds the Snapshot of the Gateway, resolving the HTTPRoutes
+// attached to each of its listeners
+func (r *reconciler) buildSnapshot(ctx context.Context, gw *gatewayv1.Gateway) (Snapshot, error) {
+	snapshot := Snapshot{
+		HTTPRoutes: make(map[gatewayv1.SectionName][]gatewayv1.HTTPRoute),
+	}
+
+	gatewayClass := &gatewayv1.GatewayClass{}
+	if err := r.client.Get(ctx, types.NamespacedName{Name: string(gw.Spec.GatewayClassName)}, gatewayClass); err != nil {
+		return snapshot, fmt.Errorf("error getting gatewayclass: %w", err)
+	}
+	snapshot.GatewayClass = gatewayClass
+
+	params, err := parameters.Resolve(ctx, r.client, gatewayClass)
+	if err != nil && !errors.Is(err, parameters.ErrInvalidReference) {
+		return snapshot, fmt.Errorf("error resolving gatewayclass parameters: %w", err)
+	}
+	snapshot.Parameters = params
+
+	routes := &gatewayv1.HTTPRouteList{}
+	if err := r.client.List(ctx, routes, client.MatchingFields{
+		indexes.HTTPRouteParentGateway: indexes.GatewayKey(gw.GetNamespace(), gw.GetName()),
+	}); err != nil {
+		return snapshot, fmt.Errorf("error listing httproutes: %w", err)
+	}
+
+	namespaceLabels := make(map[string]map[string]string)
+	for _, route := range routes.Items {
+		for _, parentRef := range route.Spec.ParentRefs {
+			if !attachment.ParentRefersTo(parentRef, route.GetNamespace(), gw) {
+				continue
+			}
+
+			labels, ok := namespaceLabels[route.GetNamespace()]
+			if !ok {
+				var err error
+				labels, err = attachment.NamespaceLabels(ctx, r.client, route.GetNamespace())
+				if err != nil {
+					return snapshot, fmt.Errorf("error getting namespace %s: %w", route.GetNamespace(), err)
+				}
+				namespaceLabels[route.GetNamespace()] = labels
+			}
+
+			result := attachment.Resolve(attachment.Route{
+				Kind:            "HTTPRoute",
+				Namespace:       route.GetNamespace(),
+				NamespaceLabels: labels,
+				Hostnames:       route.Spec.Hostnames,
+			}, parentRef, gw)
+			for _, listener := range result.Listeners {
+				snapshot.HTTPRoutes[listener] = append(snapshot.HTTPRoutes[listener], route)
+			}
+		}
+	}
+	return snapshot, nil
+}
+
+// applyProgramResult converts the ProgramResult to the Gateway status. It returns
+// the Gateway Programmed condition, and the Programmed conditions of the
+// listeners present on the result, by listener name. When assignsAddresses, the
+// Programmer is expected to assign the requested addresses, as there is no
+// AddressProviderFunc
+func applyProgramResult(gw *gatewayv1.Gateway, result ProgramResult, programmed metav1.Condition, assignsAddresses bool) (metav1.Condition, map[gatewayv1.SectionName]metav1.Condition) {
+	var addressCondition *metav1.Condition
+	if len(result.Addresses) > 0 || (assignsAddresses && len(gw.Spec.Addresses) > 0) {
+		addressCondition = applyAddresses(gw, result.Addresses)
+	}
+	switch {
+	case addressCondition != nil:
+		programmed = *addressCondition
+	case result.Message != "":
+		programmed.Message = result.Message
+	}
+
+	listeners := make(map[gatewayv1.SectionName]metav1.Condition, len(result.Listeners))
+	for name, listener := range result.Listeners {
+		status := metav1.ConditionTrue
+		reason := gatewayv1.ListenerReasonProgrammed
+		message := "Listener is programmed"
+		if !listener.Programmed {
+			status = metav1.ConditionFalse
+			reason = gatewayv1.ListenerReasonInvalid
+			message = "Listener is not programmed"
+		}
+		if listener.Reason != "" {
+			reason = listener.Reason
+		}
+		if listener.Message != "" {
+			message = listener.Message
+		}
+		listeners[name] = newCondition(
+			string(gatewayv1.ListenerConditionProgrammed),
+			string(reason),
+			status,
+			message,
+			gw.Generation)
+	}
+	return programmed, listeners
+}
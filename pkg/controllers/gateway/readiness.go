@@ -0,0 +1,55 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rikatz/kgame/pkg/hooks"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// DataplaneReadyFunc is called once the Gateway is programmed, to check if its
+// dataplane is serving, like when the rollout of its pods finished. Until it is
+// ready the Gateway Programmed condition is False, with the Pending reason and
+// the returned message. The Gateway is not reconciled again by itself, so the
+// implementations are expected to watch their dataplane
+type DataplaneReadyFunc func(ctx context.Context, gw *gatewayv1.Gateway) (bool, string, error)
+
+// checkDataplane calls the DataplaneReadyFunc, returning a non nil condition
+// when the dataplane is not ready, which should be set as the Gateway Programmed
+// condition. An error is returned when the check failed, and the reconciliation
+// should be retried
+func (r *reconciler) checkDataplane(ctx context.Context, gw *gatewayv1.Gateway) (*metav1.Condition, error) {
+	type readiness struct {
+		ready   bool
+		message string
+	}
+	result, err := hooks.Call(ctx, r.hooks.Runner, gw, "DataplaneReadyFunc", func(ctx context.Context, gw *gatewayv1.Gateway) (readiness, error) {
+		ready, message, err := r.options.DataplaneReadyFunc(ctx, gw)
+		return readiness{ready: ready, message: message}, err
+	})
+	ready, message := result.ready, result.message
+	if err != nil {
+		cond := newCondition(
+			string(gatewayv1.GatewayConditionProgrammed),
+			string(gatewayv1.GatewayReasonPending),
+			metav1.ConditionFalse,
+			fmt.Sprintf("error checking the dataplane: %s", err),
+			gw.Generation)
+		return &cond, fmt.Errorf("error executing dataplane ready function: %w", err)
+	}
+	if ready {
+		return nil, nil
+	}
+	if message == "" {
+		message = "Gateway is waiting for the dataplane"
+	}
+	cond := newCondition(
+		string(gatewayv1.GatewayConditionProgrammed),
+		string(gatewayv1.GatewayReasonPending),
+		metav1.ConditionFalse,
+		message,
+		gw.Generation)
+	return &cond, nil
+}
@@ -0,0 +1,83 @@
+package gateway
+
+import (
+	"github.com/rikatz/kgame/pkg/attachment"
+	"github.com/rikatz/kgame/pkg/conditions"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+var (
+	// ownedGatewayConditions are the Gateway conditions managed by kgame. Any of
+	// them not set during a reconciliation is considered stale and is pruned
+	ownedGatewayConditions = []string{
+		string(gatewayv1.GatewayConditionAccepted),
+		string(gatewayv1.GatewayConditionProgrammed),
+		ConditionDataplaneDrifted,
+	}
+
+	// ownedListenerConditions are the Listener conditions managed by kgame. Any of
+	// them not set during a reconciliation is considered stale and is pruned
+	ownedListenerConditions = []string{
+		string(gatewayv1.ListenerConditionAccepted),
+		string(gatewayv1.ListenerConditionProgrammed),
+		string(gatewayv1.ListenerConditionResolvedRefs),
+		string(gatewayv1.ListenerConditionConflicted),
+	}
+)
+
+// ownedStatus keeps the owned conditions of the Gateway and of its listeners, see
+// writer.OwnedStatusFunc
+func ownedStatus(obj client.Object) {
+	gw, ok := obj.(*gatewayv1.Gateway)
+	if !ok {
+		return
+	}
+	gw.Status.Conditions = conditions.Owned(gw.Status.Conditions, ownedGatewayConditions...)
+	for i := range gw.Status.Listeners {
+		gw.Status.Listeners[i].Conditions = conditions.Owned(gw.Status.Listeners[i].Conditions, ownedListenerConditions...)
+	}
+}
+
+// conflictReason returns true if the listener reason is one of the Conflicted
+// condition, HostnameConflict or ProtocolConflict
+func conflictReason(reason string) bool {
+	return reason == string(gatewayv1.ListenerReasonHostnameConflict) || reason == string(gatewayv1.ListenerReasonProtocolConflict)
+}
+
+// newCondition returns a condition to be set on the Gateway or on its listeners
+func newCondition(condtype string, reason string, status metav1.ConditionStatus, message string, generation int64) metav1.Condition {
+	return metav1.Condition{
+		Type:               condtype,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		LastTransitionTime: metav1.Now(),
+		ObservedGeneration: generation,
+	}
+}
+
+// syncListenersStatus makes sure the Gateway status contains exactly one entry
+// per listener of the spec, in the same order. Entries of listeners that were
+// removed from the spec are dropped, while the conditions of the remaining ones
+// are kept so their transition times are preserved
+func syncListenersStatus(gw *gatewayv1.Gateway) {
+	current := make(map[gatewayv1.SectionName]gatewayv1.ListenerStatus, len(gw.Status.Listeners))
+	for _, listener := range gw.Status.Listeners {
+		current[listener.Name] = listener
+	}
+
+	listeners := make([]gatewayv1.ListenerStatus, 0, len(gw.Spec.Listeners))
+	for _, listener := range gw.Spec.Listeners {
+		status, ok := current[listener.Name]
+		if !ok {
+			status = gatewayv1.ListenerStatus{
+				Name: listener.Name,
+			}
+		}
+		status.SupportedKinds = attachment.SupportedKinds(listener)
+		listeners = append(listeners, status)
+	}
+	gw.Status.Listeners = listeners
+}
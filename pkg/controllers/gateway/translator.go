@@ -0,0 +1,221 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/rikatz/kgame/pkg/ir"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// DataplaneConfig is the dataplane specific configuration produced by a
+// Translator. kgame does not inspect it, other than comparing it with the last
+// configuration applied for the same Gateway
+type DataplaneConfig any
+
+// Translator is an alternative extension point to the Programmer, for dataplanes
+// driven by a declarative configuration. kgame builds the Model of each accepted
+// Gateway and calls Translate, and only calls Apply when the configuration differs
+// from the last one applied for the same Gateway.
+// Errors of both are handled as Programmer errors: the Gateway Programmed
+// condition is set as False and the reconciliation is retried, unless it is a
+// terminal error (see hooks.ErrTerminal).
+// The Programmer takes precedence when both are configured
+type Translator interface {
+	// Translate converts the model to the dataplane configuration
+	Translate(ctx context.Context, model Model) (DataplaneConfig, error)
+	// Apply programs the configuration on the dataplane. The result is kept, and
+	// reused while the configuration does not change
+	Apply(ctx context.Context, gw *gatewayv1.Gateway, config DataplaneConfig) (ProgramResult, error)
+}
+
+// Model is the resolved state of a Gateway passed to the Translator
+type Model struct {
+	Snapshot
+	// Gateway being translated
+	Gateway *gatewayv1.Gateway
+	// Backends are the Services referenced by the attached routes, by name. Missing
+	// Services are not present
+	Backends map[types.NamespacedName]*corev1.Service
+	// TLSCertificates are the Secrets referenced by the Gateway listeners, by
+	// name. Missing Secrets are not present
+	TLSCertificates map[types.NamespacedName]*corev1.Secret
+}
+
+// appliedConfig is the last configuration applied for a Gateway
+type appliedConfig struct {
+	config DataplaneConfig
+	result ProgramResult
+}
+
+// translatorProgrammer adapts a Translator to the Programmer interface
+type translatorProgrammer struct {
+	reconciler *reconciler
+	translator Translator
+
+	mu      sync.Mutex
+	applied map[types.NamespacedName]appliedConfig
+}
+
+func newTranslatorProgrammer(r *reconciler, translator Translator) *translatorProgrammer {
+	return &translatorProgrammer{
+		reconciler: r,
+		translator: translator,
+		applied:    make(map[types.NamespacedName]appliedConfig),
+	}
+}
+
+// Program builds the Gateway model, translates it and applies the configuration
+// if it changed since the last successful apply
+func (t *translatorProgrammer) Program(ctx context.Context, gw *gatewayv1.Gateway, snapshot Snapshot) (ProgramResult, error) {
+	model, err := t.reconciler.buildModel(ctx, gw, snapshot)
+	if err != nil {
+		return ProgramResult{}, err
+	}
+
+	config, err := t.translator.Translate(ctx, model)
+	if err != nil {
+		return ProgramResult{}, fmt.Errorf("error translating the gateway: %w", err)
+	}
+
+	key := types.NamespacedName{Namespace: gw.GetNamespace(), Name: gw.GetName()}
+	t.mu.Lock()
+	last, ok := t.applied[key]
+	t.mu.Unlock()
+	if ok && equality.Semantic.DeepEqual(last.config, config) {
+		return last.result, nil
+	}
+
+	result, err := t.translator.Apply(ctx, gw, config)
+	if err != nil {
+		// The configuration may be partially applied, so it must be applied again
+		t.forget(key)
+		return ProgramResult{}, fmt.Errorf("error applying the dataplane configuration: %w", err)
+	}
+
+	t.mu.Lock()
+	t.applied[key] = appliedConfig{config: config, result: result}
+	t.mu.Unlock()
+	return result, nil
+}
+
+// forget drops the configuration applied for a Gateway
+func (t *translatorProgrammer) forget(key types.NamespacedName) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.applied, key)
+}
+
+// forgetConfig drops the configuration applied for a Gateway that is being
+// deleted, when the Gateways are programmed by a Translator, and its programmed
+// inputs when the InputCache or the ProgramParallelism are enabled
+func (r *reconciler) forgetConfig(key types.NamespacedName) {
+	programmer := r.programmer
+	switch p := programmer.(type) {
+	case *laneProgrammer:
+		p.forget(key)
+		programmer = p.programmer
+	case *inputCacheProgrammer:
+		p.forget(key)
+		programmer = p.programmer
+	}
+	if t, ok := programmer.(*translatorProgrammer); ok {
+		t.forget(key)
+	}
+}
+
+// invalidateConfig programs a Gateway again on its next reconciliation, even if
+// its inputs and configuration did not change, like when the dataplane lost it
+func (r *reconciler) invalidateConfig(key types.NamespacedName) {
+	programmer := r.programmer
+	switch p := programmer.(type) {
+	case *laneProgrammer:
+		p.invalidate(key)
+		programmer = p.programmer
+	case *inputCacheProgrammer:
+		p.forget(key)
+		programmer = p.programmer
+	}
+	if t, ok := programmer.(*translatorProgrammer); ok {
+		t.forget(key)
+	}
+}
+
+// triggered enqueues the Gateways of the Trigger, invalidating their programmed
+// configuration first, see invalidateConfig
+func triggered(r *reconciler) handler.EventHandler {
+	return handler.Funcs{
+		GenericFunc: func(ctx context.Context, e event.GenericEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			key := client.ObjectKeyFromObject(e.Object)
+			r.invalidateConfig(key)
+			q.Add(reconcile.Request{NamespacedName: key})
+		},
+	}
+}
+
+// buildModel resolves the backends and TLS certificates referenced by the Gateway
+// and its attached routes.
+// Only backends on the same namespace of the route are resolved, as the route
+// ReferenceGrants are not supported yet. The certificates on other namespaces
+// are resolved when a ReferenceGrant allows them
+func (r *reconciler) buildModel(ctx context.Context, gw *gatewayv1.Gateway, snapshot Snapshot) (Model, error) {
+	model := Model{
+		Snapshot:        snapshot,
+		Gateway:         gw,
+		Backends:        make(map[types.NamespacedName]*corev1.Service),
+		TLSCertificates: make(map[types.NamespacedName]*corev1.Secret),
+	}
+
+	for _, routes := range snapshot.HTTPRoutes {
+		for _, route := range routes {
+			for _, rule := range route.Spec.Rules {
+				for _, backendRef := range rule.BackendRefs {
+					ref := backendRef.BackendObjectReference
+					if !ir.IsLocalRef(ref.Group, ref.Kind, ref.Namespace, "Service", route.GetNamespace()) {
+						continue
+					}
+					key := types.NamespacedName{Namespace: route.GetNamespace(), Name: string(ref.Name)}
+					if _, ok := model.Backends[key]; ok {
+						continue
+					}
+					service := &corev1.Service{}
+					if err := r.getOptional(ctx, key, service); err != nil {
+						return model, fmt.Errorf("error getting backend %s: %w", key, err)
+					}
+					if service.GetName() != "" {
+						model.Backends[key] = service
+					}
+				}
+			}
+		}
+	}
+
+	for _, listener := range gw.Spec.Listeners {
+		certificates, err := r.resolveCertificates(ctx, gw, listener)
+		if err != nil {
+			return model, err
+		}
+		for _, secret := range certificates.secrets {
+			model.TLSCertificates[client.ObjectKeyFromObject(secret)] = secret
+		}
+	}
+	return model, nil
+}
+
+// getOptional gets the object, leaving it empty if it does not exist
+func (r *reconciler) getOptional(ctx context.Context, key types.NamespacedName, obj client.Object) error {
+	if err := r.client.Get(ctx, key, obj); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
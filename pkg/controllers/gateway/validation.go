@@ -0,0 +1,99 @@
+package gateway
+
+import (
+	"context"
+	"strings"
+
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// ValidateGatewayFunc is called before accepting a Gateway, so implementers can
+// reject the spec combinations they do not support, like address types or
+// protocols. Errors on the Gateway set its Accepted condition as False and the
+// Gateway is not programmed. Errors on listeners set the listener Accepted
+// condition as False, and the Gateway is accepted with reason ListenersNotValid.
+// If empty the Gateway is accepted without further check
+type ValidateGatewayFunc func(ctx context.Context, gw *gatewayv1.Gateway) []ValidationError
+
+// ValidationError is a spec combination not supported by the implementation
+type ValidationError struct {
+	// Listener is the name of the invalid listener. Empty when the error is on the
+	// Gateway
+	Listener gatewayv1.SectionName
+	// Reason of the Accepted condition. Defaults to UnsupportedProtocol for
+	// listeners, and Invalid for the Gateway. The listener HostnameConflict and
+	// ProtocolConflict reasons also set its Conflicted condition as True
+	Reason string
+	// Message of the Accepted condition
+	Message string
+}
+
+// UnsupportedProtocol returns a ValidationError for a listener using a protocol not
+// supported by the implementation
+func UnsupportedProtocol(listener gatewayv1.SectionName, message string) ValidationError {
+	return ValidationError{
+		Listener: listener,
+		Reason:   string(gatewayv1.ListenerReasonUnsupportedProtocol),
+		Message:  message,
+	}
+}
+
+// UnsupportedAddress returns a ValidationError for a Gateway requesting addresses
+// not supported by the implementation
+func UnsupportedAddress(message string) ValidationError {
+	return ValidationError{
+		Reason:  string(gatewayv1.GatewayReasonUnsupportedAddress),
+		Message: message,
+	}
+}
+
+// validationResult groups the validation errors by target
+type validationResult struct {
+	// gateway is the Gateway Accepted condition reason and message, when the
+	// Gateway is not valid
+	gatewayReason  string
+	gatewayMessage string
+	// listeners are the listener Accepted condition reason and message, by
+	// listener name
+	listeners map[gatewayv1.SectionName]ValidationError
+}
+
+// newValidationResult groups the errors, applying the default reasons. Multiple
+// errors for the same target are joined on the same message, using the reason of
+// the first one
+func newValidationResult(errs []ValidationError) validationResult {
+	result := validationResult{
+		listeners: make(map[gatewayv1.SectionName]ValidationError),
+	}
+	var gatewayMessages []string
+	for _, err := range errs {
+		if err.Listener == "" {
+			if result.gatewayReason == "" {
+				result.gatewayReason = err.Reason
+				if result.gatewayReason == "" {
+					result.gatewayReason = string(gatewayv1.GatewayReasonInvalid)
+				}
+			}
+			gatewayMessages = append(gatewayMessages, err.Message)
+			continue
+		}
+
+		existing, ok := result.listeners[err.Listener]
+		if !ok {
+			if err.Reason == "" {
+				err.Reason = string(gatewayv1.ListenerReasonUnsupportedProtocol)
+			}
+			result.listeners[err.Listener] = err
+			continue
+		}
+		existing.Message = strings.Join([]string{existing.Message, err.Message}, "; ")
+		result.listeners[err.Listener] = existing
+	}
+	result.gatewayMessage = strings.Join(gatewayMessages, "; ")
+	return result
+}
+
+// gatewayInvalid returns true if the Gateway itself is not valid
+func (v validationResult) gatewayInvalid() bool {
+	return v.gatewayReason != ""
+}
@@ -18,16 +18,40 @@ package gatewayclass
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
+	"github.com/rikatz/kgame/pkg/conditions"
+	"github.com/rikatz/kgame/pkg/finalizer"
+	"github.com/rikatz/kgame/pkg/health"
+	"github.com/rikatz/kgame/pkg/hooks"
+	"github.com/rikatz/kgame/pkg/indexes"
+	"github.com/rikatz/kgame/pkg/notify"
+	"github.com/rikatz/kgame/pkg/parameters"
+	"github.com/rikatz/kgame/pkg/ratelimit"
+	"github.com/rikatz/kgame/pkg/reload"
+	"github.com/rikatz/kgame/pkg/sharding"
+	"github.com/rikatz/kgame/pkg/writer"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 )
 
@@ -36,21 +60,135 @@ type reconciler struct {
 	scheme  *runtime.Scheme
 	logger  logr.Logger
 	options GatewayClassOptions
+	hooks   hooks.Hooks[*gatewayv1.GatewayClass]
+	// finalizers manages the FinalizerName
+	finalizers *finalizer.Manager
+	recorder   record.EventRecorder
+	// status writes the GatewayClass status with the configured strategy
+	status *writer.StatusWriter
 }
 
 // AddFinalizerFunc is a function that should be called immediately before adding a
 // finalizer.
 // If empty the finalizer will be added without further check
-type AddFinalizerFunc func(ctx context.Context) error
+type AddFinalizerFunc = hooks.AddFinalizerFunc
 
 // RemoveFinalizerFunc is a function that should be called immediately before removing
 // a finalizer. If empty the finalizer will be removed without any further check
-type RemoveFinalizerFunc func(ctx context.Context) error
+type RemoveFinalizerFunc = hooks.RemoveFinalizerFunc
+
+// ErrPending can be returned, or wrapped, by an AcceptFunc to signal that the
+// GatewayClass cannot be accepted yet, like when the dataplane image is still
+// being validated
+var ErrPending = errors.New("gatewayclass is pending")
+
+// defaultPendingRequeueInterval is the interval used to requeue a Pending
+// GatewayClass when none is configured
+const defaultPendingRequeueInterval = 10 * time.Second
+
+// AcceptFunc is a function called before marking the GatewayClass as accepted.
+// Returning an error wrapping ErrPending will set the Accepted condition as Unknown
+// with reason Pending, and the GatewayClass will be reconciled again after the
+// PendingRequeueInterval. Any other error will be retried.
+// If empty the GatewayClass will be accepted without further check
+type AcceptFunc func(ctx context.Context, gatewayClass *gatewayv1.GatewayClass) error
+
+// ValidateParametersFunc is called with the object referenced by the GatewayClass
+// parametersRef, once it is resolved. The params are the typed object when its
+// kind is registered on the manager scheme, or an unstructured object otherwise.
+// Returning an error sets the Accepted condition as False with reason
+// InvalidParameters, unless it is a RequeueAfter error (see hooks.RequeueAfter).
+// If empty the resolved parameters are accepted without further check
+type ValidateParametersFunc func(ctx context.Context, params any) error
+
+// ConditionDataplaneRolledOut is the GatewayClass condition reporting the
+// progress of the rollout of the dataplane of its Gateways, see RolloutStatusFunc
+const ConditionDataplaneRolledOut = "DataplaneRolledOut"
+
+// ConditionDeletionBlocked is the GatewayClass condition set while its deletion
+// waits for the Gateways referencing it to be removed, with their names. It is
+// removed with the finalizer
+const ConditionDeletionBlocked = "DeletionBlocked"
+
+// maxBlockingGateways is the number of Gateways named on the DeletionBlocked
+// message
+const maxBlockingGateways = 10
+
+// RolloutStatus is the progress of the rollout of the dataplane of the Gateways
+// of a GatewayClass
+type RolloutStatus struct {
+	// RolledOut is true once all the Gateways run the current dataplane
+	RolledOut bool
+	// Reason and Message of the DataplaneRolledOut condition
+	Reason  string
+	Message string
+}
+
+// RolloutStatusFunc is called once the GatewayClass is accepted, returning the
+// progress of the rollout of its dataplane, set on the DataplaneRolledOut
+// condition. The GatewayClass is reconciled again after the
+// PendingRequeueInterval until the rollout finishes. A nil status removes the
+// condition, like when no rollout is tracked for the class
+type RolloutStatusFunc func(ctx context.Context, gatewayClass *gatewayv1.GatewayClass) (*RolloutStatus, error)
 
 type GatewayClassOptions struct {
 	FinalizerName       string
 	AddFinalizerFunc    AddFinalizerFunc
 	RemoveFinalizerFunc RemoveFinalizerFunc
+	AcceptFunc          AcceptFunc
+	// ValidateParametersFunc validates the GatewayClass parameters. The Gateway
+	// controller resolves them again, without validating them, for the Snapshot of
+	// the Gateway Programmer and Translator
+	ValidateParametersFunc ValidateParametersFunc
+	// RolloutStatusFunc reports the rollout of the dataplane of the Gateways of
+	// the class, like the canary rollout of the provisioner
+	RolloutStatusFunc RolloutStatusFunc
+	// PendingRequeueInterval is the interval to reconcile a Pending GatewayClass
+	// again. Defaults to 10 seconds
+	PendingRequeueInterval time.Duration
+	// ReconcileHooks are optional hooks called during the GatewayClass reconciliation
+	ReconcileHooks ReconcileHooks
+	// HookTimeout is the maximum duration of each hook call. Defaults to
+	// hooks.DefaultTimeout
+	HookTimeout time.Duration
+	// Reporter receives the reconciliation results of this controller. It is set
+	// by NewController when the controller status report is enabled
+	Reporter *health.Reporter
+	// Notify receives the notifications of this controller. Defaults to the
+	// NewController ControllerOptions.Notify
+	Notify notify.Func
+	// Trigger receives the objects to be reconciled again on request, like when
+	// the dataplane lost its configuration. It is set by NewController, see
+	// Controller.TriggerReconcile
+	Trigger <-chan event.GenericEvent
+	// MaxConcurrentReconciles is the number of GatewayClasses reconciled in parallel.
+	// Defaults to 1
+	MaxConcurrentReconciles int
+	// ResyncPeriod reconciles each GatewayClass again after the given period since its
+	// last successful reconciliation, correcting any drift of the dataplane.
+	// Disabled when zero
+	ResyncPeriod time.Duration
+	// Settings changes the MaxConcurrentReconciles and ResyncPeriod at runtime.
+	// It is set by NewController when the KgameConfig reload is enabled
+	Settings *reload.Settings
+	// Sharding skips the reconciliations owned by other active replicas. It is
+	// set by NewController from ControllerOptions.Sharding
+	Sharding *sharding.Options
+	// RateLimit configures the backoff of the failed reconciliations. Defaults to
+	// the controller-runtime rate limiter
+	RateLimit ratelimit.Options
+	// Predicates are appended to the predicates of the GatewayClass events, see the
+	// predicates package
+	Predicates []predicate.Predicate
+
+	// Writes configures the field manager and the strategy of the GatewayClass
+	// status writes
+	Writes writer.Options
+
+	// ParametersKinds are the kinds of the GatewayClass parameters. Their objects
+	// are watched, and their changes validate the parameters of the classes
+	// referencing them again. See ControllerOptions.ParametersKinds
+	ParametersKinds []schema.GroupVersionKind
 }
 
 // SetupWithManager sets the GatewayClass controller to be started with the current
@@ -58,14 +196,104 @@ type GatewayClassOptions struct {
 // We don't add any predicate here to check the GatewayClass, because we drop the
 // undesired GatewayClass already on controller-runtime cache level (see tunables)
 func SetupWithManager(mgr manager.Manager, options GatewayClassOptions) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&gatewayv1.GatewayClass{}).
-		Complete(&reconciler{
-			options: options,
-			client:  mgr.GetClient(),
-			scheme:  mgr.GetScheme(),
-			logger:  mgr.GetLogger().WithValues("controller", "gatewayclass"),
-		})
+	_, err := BuildWithManager(mgr, options)
+	return err
+}
+
+// BuildWithManager is SetupWithManager returning the controller, so additional
+// watches can be registered on it
+func BuildWithManager(mgr manager.Manager, options GatewayClassOptions) (controller.Controller, error) {
+	if err := indexes.AddGatewayClassName(context.Background(), mgr.GetFieldIndexer()); err != nil {
+		return nil, err
+	}
+
+	recorder := mgr.GetEventRecorderFor("kgame-gatewayclass")
+	maxConcurrentReconciles := options.MaxConcurrentReconciles
+	if options.Settings != nil {
+		maxConcurrentReconciles = options.Settings.Ceiling()
+	}
+	b := ctrl.NewControllerManagedBy(mgr).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: maxConcurrentReconciles,
+			RateLimiter:             options.RateLimit.New(),
+			NewQueue:                options.RateLimit.NewQueue(),
+		}).
+		For(&gatewayv1.GatewayClass{}, builder.WithPredicates(options.Predicates...)).
+		// A GatewayClass being deleted waits for its Gateways to be removed
+		Watches(&gatewayv1.Gateway{},
+			handler.EnqueueRequestsFromMapFunc(deletingGatewayClassOf(mgr.GetClient())),
+			builder.WithPredicates(predicate.Funcs{
+				CreateFunc:  func(event.CreateEvent) bool { return false },
+				UpdateFunc:  func(event.UpdateEvent) bool { return false },
+				GenericFunc: func(event.GenericEvent) bool { return false },
+			}))
+	if options.Trigger != nil {
+		b = b.WatchesRawSource(source.Channel(options.Trigger, &handler.EnqueueRequestForObject{}))
+	}
+	if len(options.ParametersKinds) > 0 {
+		if err := indexes.AddGatewayClassParameters(context.Background(), mgr.GetFieldIndexer()); err != nil {
+			return nil, err
+		}
+	}
+	for _, gvk := range options.ParametersKinds {
+		b = b.Watches(parameters.Metadata(gvk), handler.EnqueueRequestsFromMapFunc(gatewayClassesOfParameters(mgr.GetClient(), gvk)))
+	}
+	runner := hooks.NewRunner(options.HookTimeout, recorder)
+	r := &reconciler{
+		options: options,
+		hooks: hooks.Hooks[*gatewayv1.GatewayClass]{
+			ReconcileHooks: options.ReconcileHooks,
+			Runner:         runner,
+		},
+		recorder: recorder,
+		client:   options.Writes.Client(mgr.GetClient()),
+		scheme:   mgr.GetScheme(),
+		logger:   mgr.GetLogger().WithValues("controller", "gatewayclass"),
+		status:   writer.NewStatusWriter(mgr.GetClient(), mgr.GetScheme(), options.Writes, ownedStatus),
+	}
+	r.finalizers = finalizer.NewManager(r.client, finalizer.Options{
+		Name:                options.FinalizerName,
+		AddFinalizerFunc:    options.AddFinalizerFunc,
+		RemoveFinalizerFunc: options.RemoveFinalizerFunc,
+		ParametersFunc:      r.finalizerParameters,
+		Runner:              runner,
+	})
+	return b.Build(options.Sharding.Wrap(health.ObserveReconciler("GatewayClass", options.Reporter, options.Settings.Wrap(r))))
+}
+
+// deletingGatewayClassOf maps a Gateway to the request of its GatewayClass, when
+// the GatewayClass is managed and being deleted
+func deletingGatewayClassOf(kubeclient client.Client) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		gw, ok := obj.(*gatewayv1.Gateway)
+		if !ok {
+			return nil
+		}
+		gatewayClass := &gatewayv1.GatewayClass{}
+		if err := kubeclient.Get(ctx, types.NamespacedName{Name: string(gw.Spec.GatewayClassName)}, gatewayClass); err != nil {
+			return nil
+		}
+		if gatewayClass.GetDeletionTimestamp().IsZero() {
+			return nil
+		}
+		return []reconcile.Request{{NamespacedName: client.ObjectKeyFromObject(gatewayClass)}}
+	}
+}
+
+// gatewayClassesOfParameters maps a parameters object of the kind gvk to the
+// requests of the GatewayClasses referencing it
+func gatewayClassesOfParameters(kubeclient client.Client, gvk schema.GroupVersionKind) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		classes, err := parameters.GatewayClassesOf(ctx, kubeclient, gvk, obj)
+		if err != nil {
+			return nil
+		}
+		requests := make([]reconcile.Request, 0, len(classes))
+		for i := range classes {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&classes[i])})
+		}
+		return requests
+	}
 }
 
 // Reconcile executes the reconciliation process of this GatewayClass
@@ -76,6 +304,7 @@ func (r *reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 	gatewayClass := gatewayv1.GatewayClass{}
 	if err := r.client.Get(ctx, req.NamespacedName, &gatewayClass); err != nil {
 		if client.IgnoreNotFound(err) == nil {
+			r.options.Notify.Send(ctx, notify.Notification{Type: notify.ClassRemoved, Name: req.NamespacedName})
 			return reconcile.Result{}, nil
 		}
 		logger.Error(err, "unable to reconcile")
@@ -86,43 +315,281 @@ func (r *reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 	originalResource := gatewayClass.DeepCopy()
 
 	if gatewayClass.GetDeletionTimestamp() != nil && !gatewayClass.GetDeletionTimestamp().IsZero() {
-		if r.options.FinalizerName != "" && controllerutil.RemoveFinalizer(&gatewayClass, r.options.FinalizerName) {
-			if r.options.RemoveFinalizerFunc != nil {
-				if err := r.options.RemoveFinalizerFunc(ctx); err != nil {
-					return reconcile.Result{}, fmt.Errorf("error executing pre-finalizer removal function: %w", err)
+		proceed, err := r.hooks.OnDelete(ctx, logger, &gatewayClass)
+		if err != nil {
+			return r.hookResult(ctx, &gatewayClass, originalResource, fmt.Errorf("error executing on delete hook: %w", err))
+		}
+		if !proceed {
+			logger.Info("deletion vetoed by hook")
+			return reconcile.Result{}, nil
+		}
+
+		if r.finalizers.Contains(&gatewayClass) {
+			// As defined by the gateway-exists-finalizer semantics, the GatewayClass
+			// is kept while it is referenced by any Gateway
+			gateways := &gatewayv1.GatewayList{}
+			if err := r.client.List(ctx, gateways, client.MatchingFields{indexes.GatewayClassName: gatewayClass.GetName()}); err != nil {
+				return reconcile.Result{}, fmt.Errorf("error listing the gateways of %s: %w", req.String(), err)
+			}
+			if len(gateways.Items) > 0 {
+				message := blockingGatewaysMessage(gateways.Items)
+				logger.Info("deletion blocked", "reason", message)
+				r.recorder.Event(&gatewayClass, corev1.EventTypeWarning, "GatewaysExist", message)
+				conditions.Set(&gatewayClass.Status.Conditions, metav1.Condition{
+					Type:               ConditionDeletionBlocked,
+					Status:             metav1.ConditionTrue,
+					Reason:             "GatewaysExist",
+					Message:            message,
+					LastTransitionTime: metav1.Now(),
+					ObservedGeneration: gatewayClass.Generation,
+				})
+				return reconcile.Result{}, r.status.Write(ctx, &gatewayClass, originalResource)
+			}
+			// The condition is removed before the finalizer, as the GatewayClass may
+			// be gone right after it
+			if conditions.Remove(&gatewayClass.Status.Conditions, ConditionDeletionBlocked) {
+				if err := r.status.Write(ctx, &gatewayClass, originalResource); err != nil {
+					return reconcile.Result{}, err
 				}
 			}
 
-			r.logger.Info("removing finalizer", "finalizer", r.options.FinalizerName)
-			return reconcile.Result{}, r.client.Patch(ctx, &gatewayClass, client.MergeFrom(originalResource))
+			if _, err := r.finalizers.Remove(ctx, logger, &gatewayClass); err != nil {
+				return r.hookResult(ctx, &gatewayClass, originalResource, err)
+			}
+			return reconcile.Result{}, nil
+		}
+		// A finalizer cannot be added to an object being deleted
+		return reconcile.Result{}, nil
+	}
+
+	proceed, err := r.hooks.PreReconcile(ctx, logger, &gatewayClass)
+	if err != nil {
+		return r.hookResult(ctx, &gatewayClass, originalResource, fmt.Errorf("error executing pre reconcile hook: %w", err))
+	}
+	if !proceed {
+		logger.Info("reconciliation vetoed by hook")
+		return reconcile.Result{}, nil
+	}
+
+	if added, err := r.finalizers.Add(ctx, logger, &gatewayClass); err != nil || added {
+		if err != nil {
+			return r.hookResult(ctx, &gatewayClass, originalResource, err)
+		}
+		return reconcile.Result{}, nil
+	}
+
+	if invalid, err := r.validateParameters(ctx, &gatewayClass); err != nil || invalid != "" {
+		if err != nil {
+			return hooks.Result(err)
 		}
+		logger.Info("gatewayclass has invalid parameters", "reason", invalid)
+		markAsInvalidParameters(&gatewayClass, invalid)
+		return reconcile.Result{}, r.status.Write(ctx, &gatewayClass, originalResource)
 	}
 
-	if r.options.FinalizerName != "" && controllerutil.AddFinalizer(&gatewayClass, r.options.FinalizerName) {
-		if r.options.AddFinalizerFunc != nil {
-			if err := r.options.AddFinalizerFunc(ctx); err != nil {
-				return reconcile.Result{}, fmt.Errorf("error executing pre-finalizer add function: %w", err)
+	if r.options.AcceptFunc != nil {
+		if err := hooks.Run(ctx, r.hooks.Runner, &gatewayClass, "AcceptFunc", func(ctx context.Context, gatewayClass *gatewayv1.GatewayClass) error {
+			return r.options.AcceptFunc(ctx, gatewayClass)
+		}); err != nil {
+			if !errors.Is(err, ErrPending) {
+				return r.hookResult(ctx, &gatewayClass, originalResource, fmt.Errorf("error executing accept function: %w", err))
 			}
+
+			logger.Info("gatewayclass is pending", "reason", err.Error())
+			markAsPending(&gatewayClass, err.Error())
+			if err := r.status.Write(ctx, &gatewayClass, originalResource); err != nil {
+				return reconcile.Result{}, err
+			}
+			return reconcile.Result{RequeueAfter: r.pendingRequeueInterval()}, nil
 		}
-		r.logger.Info("adding finalizer", "finalizer", r.options.FinalizerName)
-		return reconcile.Result{}, r.client.Patch(ctx, &gatewayClass, client.MergeFrom(originalResource))
 	}
 
-	markAsAccepted(gatewayClass.Status.Conditions, gatewayClass.Generation)
-	return reconcile.Result{}, r.client.Status().Patch(ctx, &gatewayClass, client.MergeFrom(originalResource))
+	var rollout *RolloutStatus
+	if r.options.RolloutStatusFunc != nil {
+		rollout, err = hooks.Call(ctx, r.hooks.Runner, &gatewayClass, "RolloutStatusFunc", func(ctx context.Context, gatewayClass *gatewayv1.GatewayClass) (*RolloutStatus, error) {
+			return r.options.RolloutStatusFunc(ctx, gatewayClass)
+		})
+		if err != nil {
+			return r.hookResult(ctx, &gatewayClass, originalResource, fmt.Errorf("error executing rollout status function: %w", err))
+		}
+	}
+
+	markAsAccepted(&gatewayClass, rollout)
+	if err := r.status.Write(ctx, &gatewayClass, originalResource); err != nil {
+		return reconcile.Result{}, err
+	}
+	if conditions.BecameTrue(originalResource.Status.Conditions, gatewayClass.Status.Conditions, string(gatewayv1.GatewayClassConditionStatusAccepted)) {
+		r.options.Notify.Send(ctx, notify.Notification{Type: notify.ClassAccepted, Name: req.NamespacedName, Object: gatewayClass.DeepCopy()})
+	}
+
+	if err := r.hooks.PostReconcile(ctx, logger, &gatewayClass); err != nil {
+		return hooks.Result(fmt.Errorf("error executing post reconcile hook: %w", err))
+	}
+
+	if rollout != nil && !rollout.RolledOut {
+		return reconcile.Result{RequeueAfter: r.pendingRequeueInterval()}, nil
+	}
+	return reconcile.Result{RequeueAfter: r.resyncPeriod()}, nil
 }
 
-func markAsAccepted(conditions []metav1.Condition, generation int64) {
-	for i, cond := range conditions {
-		if cond.Type == string(gatewayv1.GatewayClassConditionStatusAccepted) {
-			conditions[i] = metav1.Condition{
-				Type:               string(gatewayv1.GatewayClassConditionStatusAccepted),
-				Status:             metav1.ConditionTrue,
-				Reason:             string(gatewayv1.GatewayClassReasonAccepted),
-				Message:            "GatewayClass is accepted",
-				LastTransitionTime: metav1.Now(),
-				ObservedGeneration: generation,
-			}
+// hookResult converts the error of a hook into the reconciliation result. Terminal
+// errors are surfaced on the GatewayClass Accepted condition and are not retried
+func (r *reconciler) hookResult(ctx context.Context, gatewayClass, originalResource *gatewayv1.GatewayClass, err error) (reconcile.Result, error) {
+	if !hooks.IsTerminal(err) {
+		return hooks.Result(err)
+	}
+	conditions.Set(&gatewayClass.Status.Conditions, metav1.Condition{
+		Type:               string(gatewayv1.GatewayClassConditionStatusAccepted),
+		Status:             metav1.ConditionFalse,
+		Reason:             string(gatewayv1.GatewayClassReasonUnsupported),
+		Message:            err.Error(),
+		LastTransitionTime: metav1.Now(),
+		ObservedGeneration: gatewayClass.Generation,
+	})
+	if patchErr := r.status.Write(ctx, gatewayClass, originalResource); patchErr != nil {
+		return reconcile.Result{}, patchErr
+	}
+	return hooks.Result(err)
+}
+
+// validateParameters resolves the GatewayClass parametersRef and calls the
+// ValidateParametersFunc. It returns the message of the InvalidParameters
+// condition when the parameters are not valid, or an error when the validation
+// should be retried
+func (r *reconciler) validateParameters(ctx context.Context, gatewayClass *gatewayv1.GatewayClass) (string, error) {
+	params, err := parameters.Resolve(ctx, r.client, gatewayClass)
+	if err != nil {
+		if errors.Is(err, parameters.ErrInvalidReference) {
+			return err.Error(), nil
+		}
+		return "", err
+	}
+	if params == nil || r.options.ValidateParametersFunc == nil {
+		return "", nil
+	}
+
+	if err := hooks.Run(ctx, r.hooks.Runner, gatewayClass, "ValidateParametersFunc", func(ctx context.Context, _ *gatewayv1.GatewayClass) error {
+		return r.options.ValidateParametersFunc(ctx, params)
+	}); err != nil {
+		if _, requeue := hooks.IsRequeue(err); requeue {
+			return "", err
+		}
+		return err.Error(), nil
+	}
+	return "", nil
+}
+
+// finalizerParameters resolves the GatewayClass parameters for the finalizer
+// hooks. Parameters that cannot be resolved are returned empty, as they are
+// reported by the validation
+func (r *reconciler) finalizerParameters(ctx context.Context, obj client.Object) (any, error) {
+	gatewayClass, ok := obj.(*gatewayv1.GatewayClass)
+	if !ok {
+		return nil, nil
+	}
+	params, err := parameters.Resolve(ctx, r.client, gatewayClass)
+	if err != nil {
+		return nil, nil
+	}
+	return params, nil
+}
+
+func (r *reconciler) pendingRequeueInterval() time.Duration {
+	if r.options.PendingRequeueInterval > 0 {
+		return r.options.PendingRequeueInterval
+	}
+	return defaultPendingRequeueInterval
+}
+
+// ownedConditions are the GatewayClass conditions managed by kgame. Any of them
+// not set during a reconciliation is considered stale and is pruned
+var ownedConditions = []string{
+	string(gatewayv1.GatewayClassConditionStatusAccepted),
+	ConditionDataplaneRolledOut,
+	ConditionDeletionBlocked,
+}
+
+// ownedStatus keeps the ownedConditions of the GatewayClass status, see
+// writer.OwnedStatusFunc
+func ownedStatus(obj client.Object) {
+	if gatewayClass, ok := obj.(*gatewayv1.GatewayClass); ok {
+		gatewayClass.Status.Conditions = conditions.Owned(gatewayClass.Status.Conditions, ownedConditions...)
+	}
+}
+
+// markAsAccepted sets the Accepted condition and, when rollout is set, the
+// DataplaneRolledOut condition
+func markAsAccepted(gatewayClass *gatewayv1.GatewayClass, rollout *RolloutStatus) {
+	tracker := conditions.NewTracker(&gatewayClass.Status.Conditions, ownedConditions...)
+	tracker.Set(metav1.Condition{
+		Type:               string(gatewayv1.GatewayClassConditionStatusAccepted),
+		Status:             metav1.ConditionTrue,
+		Reason:             string(gatewayv1.GatewayClassReasonAccepted),
+		Message:            "GatewayClass is accepted",
+		LastTransitionTime: metav1.Now(),
+		ObservedGeneration: gatewayClass.Generation,
+	})
+	if rollout != nil {
+		status := metav1.ConditionFalse
+		if rollout.RolledOut {
+			status = metav1.ConditionTrue
 		}
+		tracker.Set(metav1.Condition{
+			Type:               ConditionDataplaneRolledOut,
+			Status:             status,
+			Reason:             rollout.Reason,
+			Message:            rollout.Message,
+			LastTransitionTime: metav1.Now(),
+			ObservedGeneration: gatewayClass.Generation,
+		})
+	}
+	tracker.Prune()
+}
+
+// blockingGatewaysMessage returns the DeletionBlocked message, with the count and
+// the names of the Gateways, up to maxBlockingGateways
+func blockingGatewaysMessage(gateways []gatewayv1.Gateway) string {
+	names := make([]string, 0, len(gateways))
+	for i := range gateways {
+		names = append(names, client.ObjectKeyFromObject(&gateways[i]).String())
+	}
+	slices.Sort(names)
+	if len(names) > maxBlockingGateways {
+		names = append(names[:maxBlockingGateways], fmt.Sprintf("and %d more", len(names)-maxBlockingGateways))
+	}
+	return fmt.Sprintf("GatewayClass is still referenced by %d Gateways: %s", len(gateways), strings.Join(names, ", "))
+}
+
+func markAsInvalidParameters(gatewayClass *gatewayv1.GatewayClass, message string) {
+	tracker := conditions.NewTracker(&gatewayClass.Status.Conditions, ownedConditions...)
+	tracker.Set(metav1.Condition{
+		Type:               string(gatewayv1.GatewayClassConditionStatusAccepted),
+		Status:             metav1.ConditionFalse,
+		Reason:             string(gatewayv1.GatewayClassReasonInvalidParameters),
+		Message:            message,
+		LastTransitionTime: metav1.Now(),
+		ObservedGeneration: gatewayClass.Generation,
+	})
+	tracker.Prune()
+}
+
+func markAsPending(gatewayClass *gatewayv1.GatewayClass, message string) {
+	tracker := conditions.NewTracker(&gatewayClass.Status.Conditions, ownedConditions...)
+	tracker.Set(metav1.Condition{
+		Type:               string(gatewayv1.GatewayClassConditionStatusAccepted),
+		Status:             metav1.ConditionUnknown,
+		Reason:             string(gatewayv1.GatewayClassReasonPending),
+		Message:            message,
+		LastTransitionTime: metav1.Now(),
+		ObservedGeneration: gatewayClass.Generation,
+	})
+	tracker.Prune()
+}
+
+// resyncPeriod returns the ResyncPeriod, or the one set at runtime on the Settings
+func (r *reconciler) resyncPeriod() time.Duration {
+	if r.options.Settings != nil {
+		return r.options.Settings.ResyncPeriod()
 	}
+	return r.options.ResyncPeriod
 }
@@ -0,0 +1,26 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gatewayclass
+
+import (
+	"github.com/rikatz/kgame/pkg/hooks"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// ReconcileHooks can be implemented to observe and extend the reconciliation of
+// the GatewayClasses without forking the reconciler. See hooks.ReconcileHooks
+type ReconcileHooks = hooks.ReconcileHooks[*gatewayv1.GatewayClass]
@@ -0,0 +1,62 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httproute
+
+import (
+	"context"
+
+	"github.com/rikatz/kgame/pkg/indexes"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// routesOfService enqueues the HTTPRoutes referencing a Service on their
+// backendRefs, found through the HTTPRouteBackendService index
+func routesOfService(kubeclient client.Client) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		return routesOfBackend(ctx, kubeclient, obj.GetNamespace(), obj.GetName())
+	}
+}
+
+// routesOfEndpointSlice enqueues the HTTPRoutes referencing the Service of an
+// EndpointSlice, so the dataplane endpoints follow the Service endpoints
+func routesOfEndpointSlice(kubeclient client.Client) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		service, ok := obj.GetLabels()[discoveryv1.LabelServiceName]
+		if !ok {
+			return nil
+		}
+		return routesOfBackend(ctx, kubeclient, obj.GetNamespace(), service)
+	}
+}
+
+func routesOfBackend(ctx context.Context, kubeclient client.Client, namespace, name string) []reconcile.Request {
+	routes := &gatewayv1.HTTPRouteList{}
+	if err := kubeclient.List(ctx, routes, client.MatchingFields{
+		indexes.HTTPRouteBackendService: indexes.ServiceKey(namespace, name),
+	}); err != nil {
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(routes.Items))
+	for _, route := range routes.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&route)})
+	}
+	return requests
+}
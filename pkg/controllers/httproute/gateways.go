@@ -0,0 +1,57 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httproute
+
+import (
+	"context"
+
+	"github.com/rikatz/kgame/pkg/indexes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// routesOfGateway enqueues the HTTPRoutes referencing a Gateway, found through
+// the HTTPRouteParentGateway index, so their parent status follows the changes
+// of the Gateway listeners
+func routesOfGateway(kubeclient client.Client) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		routes := &gatewayv1.HTTPRouteList{}
+		if err := kubeclient.List(ctx, routes, client.MatchingFields{
+			indexes.HTTPRouteParentGateway: indexes.GatewayKey(obj.GetNamespace(), obj.GetName()),
+		}); err != nil {
+			return nil
+		}
+		requests := make([]reconcile.Request, 0, len(routes.Items))
+		for _, route := range routes.Items {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&route)})
+		}
+		return requests
+	}
+}
+
+// gatewayChanged filters the Gateway events affecting the attachment of its
+// routes: its creation and deletion, and the changes of its spec
+var gatewayChanged = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		return e.ObjectOld.GetGeneration() != e.ObjectNew.GetGeneration()
+	},
+	GenericFunc: func(event.GenericEvent) bool { return false },
+}
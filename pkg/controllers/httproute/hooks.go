@@ -0,0 +1,54 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httproute
+
+import (
+	"context"
+
+	"github.com/rikatz/kgame/pkg/hooks"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// ReconcileHooks can be implemented to observe and extend the reconciliation of
+// the HTTPRoutes without forking the reconciler. See hooks.ReconcileHooks.
+// As kgame does not add finalizers to routes, OnDelete may be called after the
+// route is already gone, and in this case only its name and namespace are set.
+// Returning false from OnDelete skips the reconciliation of a route that still
+// exists. A terminal error, see hooks.Terminal, rejects the route on each managed
+// parent with the error as the Accepted message
+type ReconcileHooks = hooks.ReconcileHooks[*gatewayv1.HTTPRoute]
+
+// RouteHooks can be implemented to program the dataplane route configuration at
+// the moment kgame accepts or stops accepting a route on a managed Gateway
+type RouteHooks interface {
+	// ValidateRoute is called before resolving the route attachment. Returning a
+	// terminal error, see hooks.Terminal, rejects the route on all of its managed
+	// parents with Accepted=False and reason UnsupportedValue, using the error as
+	// the condition message. Any other error is retried
+	ValidateRoute(ctx context.Context, route *gatewayv1.HTTPRoute) error
+	// OnRouteAttached is called when the route becomes accepted by the Gateway, with
+	// the listeners it is attached to. An error keeps the route parent status
+	// unchanged, and the attachment is retried, while a terminal error, see
+	// hooks.Terminal, rejects the route on the parent
+	OnRouteAttached(ctx context.Context, route *gatewayv1.HTTPRoute, gw *gatewayv1.Gateway, listeners []gatewayv1.SectionName) error
+	// OnRouteDetached is called when a route previously accepted by the parent is
+	// not accepted anymore, or the parent is removed from the route. It is also
+	// called when the route is deleted, with only its name and namespace set, for
+	// the parents accepting it since the controller started. A terminal error is
+	// added to the Accepted message of the parent, and is not retried
+	OnRouteDetached(ctx context.Context, route *gatewayv1.HTTPRoute, parentRef gatewayv1.ParentReference) error
+}
@@ -0,0 +1,572 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httproute
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/rikatz/kgame/pkg/attachment"
+	"github.com/rikatz/kgame/pkg/conditions"
+	"github.com/rikatz/kgame/pkg/health"
+	"github.com/rikatz/kgame/pkg/hooks"
+	"github.com/rikatz/kgame/pkg/indexes"
+	"github.com/rikatz/kgame/pkg/notify"
+	"github.com/rikatz/kgame/pkg/predicates"
+	"github.com/rikatz/kgame/pkg/ratelimit"
+	"github.com/rikatz/kgame/pkg/reload"
+	"github.com/rikatz/kgame/pkg/sharding"
+	"github.com/rikatz/kgame/pkg/writer"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+const routeKind = "HTTPRoute"
+
+// ownedConditions are the route parent conditions managed by kgame. Any of them
+// not set during a reconciliation is considered stale and is pruned
+var ownedConditions = []string{
+	string(gatewayv1.RouteConditionAccepted),
+}
+
+type reconciler struct {
+	client          client.Client
+	scheme          *runtime.Scheme
+	logger          logr.Logger
+	controllerNames []gatewayv1.GatewayController
+	options         HTTPRouteOptions
+	hooks           hooks.Hooks[*gatewayv1.HTTPRoute]
+	// status writes the HTTPRoute status with the configured strategy
+	status *writer.StatusWriter
+
+	mu sync.Mutex
+	// attached are the managed parents accepting each route on its last
+	// reconciliation, so the route is detached from them when it is deleted
+	attached map[types.NamespacedName][]gatewayv1.RouteParentStatus
+}
+
+type HTTPRouteOptions struct {
+	// AdditionalControllerNames are the controllerNames managed by kgame besides
+	// the one passed to BuildWithManager, see ControllerOptions.ControllerClasses.
+	// The parent status of each Gateway is owned by the controllerName of its
+	// GatewayClass
+	AdditionalControllerNames []gatewayv1.GatewayController
+	// ReconcileHooks are optional hooks called during the HTTPRoute reconciliation
+	ReconcileHooks ReconcileHooks
+	// RouteHooks are optional hooks called when routes are attached to or detached
+	// from the managed Gateways
+	RouteHooks RouteHooks
+	// HookTimeout is the maximum duration of each hook call. Defaults to
+	// hooks.DefaultTimeout
+	HookTimeout time.Duration
+	// Reporter receives the reconciliation results of this controller. It is set
+	// by NewController when the controller status report is enabled
+	Reporter *health.Reporter
+	// Notify receives the notifications of this controller. Defaults to the
+	// NewController ControllerOptions.Notify
+	Notify notify.Func
+	// Trigger receives the objects to be reconciled again on request, like when
+	// the dataplane lost its configuration. It is set by NewController, see
+	// Controller.TriggerReconcile
+	Trigger <-chan event.GenericEvent
+	// WatchBackends reconciles the HTTPRoutes again when the Services of their
+	// backendRefs, or the EndpointSlices of those Services, change, so the route
+	// hooks can update the dataplane endpoints. The EndpointSlices of the watched
+	// namespaces are cached
+	WatchBackends bool
+	// MaxConcurrentReconciles is the number of HTTPRoutes reconciled in parallel.
+	// Defaults to 1
+	MaxConcurrentReconciles int
+	// ResyncPeriod reconciles each HTTPRoute again after the given period since its
+	// last successful reconciliation, correcting any drift of the dataplane.
+	// Disabled when zero
+	ResyncPeriod time.Duration
+	// Settings changes the MaxConcurrentReconciles and ResyncPeriod at runtime.
+	// It is set by NewController when the KgameConfig reload is enabled
+	Settings *reload.Settings
+	// Sharding skips the reconciliations owned by other active replicas. It is
+	// set by NewController from ControllerOptions.Sharding
+	Sharding *sharding.Options
+	// RateLimit configures the backoff of the failed reconciliations. Defaults to
+	// the controller-runtime rate limiter
+	RateLimit ratelimit.Options
+	// Predicates are appended to the predicates of the HTTPRoute events, see the
+	// predicates package
+	Predicates []predicate.Predicate
+	// Filter skips the HTTPRoute updates not requiring a reconciliation, like the
+	// status only updates written by the controller itself
+	Filter predicates.Options
+
+	// Writes configures the field manager and the strategy of the HTTPRoute
+	// status writes
+	Writes writer.Options
+}
+
+// SetupWithManager sets the HTTPRoute controller to be started with the current
+// manager.
+// The controllerName is the GatewayClass controllerName managed by kgame, and is
+// used to own the route parent status entries
+func SetupWithManager(mgr manager.Manager, controllerName gatewayv1.GatewayController, options HTTPRouteOptions) error {
+	_, err := BuildWithManager(mgr, controllerName, options)
+	return err
+}
+
+// BuildWithManager is SetupWithManager returning the controller, so additional
+// watches can be registered on it
+func BuildWithManager(mgr manager.Manager, controllerName gatewayv1.GatewayController, options HTTPRouteOptions) (controller.Controller, error) {
+	if err := indexes.AddHTTPRouteParentGateway(context.Background(), mgr.GetFieldIndexer()); err != nil {
+		return nil, err
+	}
+
+	maxConcurrentReconciles := options.MaxConcurrentReconciles
+	if options.Settings != nil {
+		maxConcurrentReconciles = options.Settings.Ceiling()
+	}
+	b := ctrl.NewControllerManagedBy(mgr).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: maxConcurrentReconciles,
+			RateLimiter:             options.RateLimit.New(),
+			NewQueue:                options.RateLimit.NewQueue(),
+		}).
+		For(&gatewayv1.HTTPRoute{}, builder.WithPredicates(slices.Concat(options.Predicates, options.Filter.Predicates())...)).
+		Watches(&gatewayv1.Gateway{},
+			handler.EnqueueRequestsFromMapFunc(routesOfGateway(mgr.GetClient())),
+			builder.WithPredicates(gatewayChanged))
+	if options.Trigger != nil {
+		b = b.WatchesRawSource(source.Channel(options.Trigger, &handler.EnqueueRequestForObject{}))
+	}
+	if options.WatchBackends {
+		if err := indexes.AddHTTPRouteBackendService(context.Background(), mgr.GetFieldIndexer()); err != nil {
+			return nil, err
+		}
+		b = b.Watches(&corev1.Service{}, handler.EnqueueRequestsFromMapFunc(routesOfService(mgr.GetClient()))).
+			Watches(&discoveryv1.EndpointSlice{}, handler.EnqueueRequestsFromMapFunc(routesOfEndpointSlice(mgr.GetClient())))
+	}
+	controllerNames := append([]gatewayv1.GatewayController{controllerName}, options.AdditionalControllerNames...)
+	return b.Build(options.Sharding.Wrap(health.ObserveReconciler("HTTPRoute", options.Reporter, options.Settings.Wrap(&reconciler{
+		options: options,
+		hooks: hooks.Hooks[*gatewayv1.HTTPRoute]{
+			ReconcileHooks: options.ReconcileHooks,
+			Runner:         hooks.NewRunner(options.HookTimeout, mgr.GetEventRecorderFor("kgame-httproute")),
+		},
+		controllerNames: controllerNames,
+		client:          options.Writes.Client(mgr.GetClient()),
+		scheme:          mgr.GetScheme(),
+		logger:          mgr.GetLogger().WithValues("controller", "httproute"),
+		status:          writer.NewStatusWriter(mgr.GetClient(), mgr.GetScheme(), options.Writes, ownedStatus(controllerNames)),
+		attached:        make(map[types.NamespacedName][]gatewayv1.RouteParentStatus),
+	}))))
+}
+
+// ownedStatus keeps the route parents of the controllerNames, see
+// writer.OwnedStatusFunc. Their conditions are kept, as the parents are an atomic
+// list
+func ownedStatus(controllerNames []gatewayv1.GatewayController) writer.OwnedStatusFunc {
+	return func(obj client.Object) {
+		route, ok := obj.(*gatewayv1.HTTPRoute)
+		if !ok {
+			return
+		}
+		route.Status.Parents = slices.DeleteFunc(route.Status.Parents, func(parent gatewayv1.RouteParentStatus) bool {
+			return !slices.Contains(controllerNames, parent.ControllerName)
+		})
+	}
+}
+
+// Reconcile executes the reconciliation process of this HTTPRoute, resolving its
+// attachment to each of the managed parent Gateways and reflecting the result on
+// the route parent status
+func (r *reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	logger := r.logger.WithValues("name", req.Name, "namespace", req.Namespace)
+	logger.Info("reconciling")
+
+	route := gatewayv1.HTTPRoute{}
+	if err := r.client.Get(ctx, req.NamespacedName, &route); err != nil {
+		if apierrors.IsNotFound(err) {
+			route.SetName(req.Name)
+			route.SetNamespace(req.Namespace)
+			if err := r.detachDeleted(ctx, &route); err != nil {
+				return hooks.Result(err)
+			}
+			if _, err := r.hooks.OnDelete(ctx, logger, &route); err != nil {
+				return hooks.Result(fmt.Errorf("error executing on delete hook: %w", err))
+			}
+			return reconcile.Result{}, nil
+		}
+		logger.Error(err, "unable to reconcile")
+		return reconcile.Result{}, err
+	}
+
+	var proceed bool
+	var err error
+	if route.GetDeletionTimestamp() != nil && !route.GetDeletionTimestamp().IsZero() {
+		proceed, err = r.hooks.OnDelete(ctx, logger, &route)
+	} else {
+		proceed, err = r.hooks.PreReconcile(ctx, logger, &route)
+	}
+	if err != nil {
+		return r.hookError(ctx, &route, fmt.Errorf("error executing reconcile hook: %w", err))
+	}
+	if !proceed {
+		logger.Info("reconciliation vetoed by hook")
+		return reconcile.Result{}, nil
+	}
+
+	originalRoute := route.DeepCopy()
+
+	namespaceLabels, err := attachment.NamespaceLabels(ctx, r.client, route.GetNamespace())
+	if err != nil {
+		return reconcile.Result{}, fmt.Errorf("error getting namespace of %s: %w", req.String(), err)
+	}
+
+	attachRoute := attachment.Route{
+		Kind:            routeKind,
+		Namespace:       route.GetNamespace(),
+		NamespaceLabels: namespaceLabels,
+		Hostnames:       route.Spec.Hostnames,
+	}
+
+	var validationErr error
+	if r.options.RouteHooks != nil {
+		validationErr = hooks.Run(ctx, r.hooks.Runner, &route, "ValidateRoute", func(ctx context.Context, route *gatewayv1.HTTPRoute) error {
+			return r.options.RouteHooks.ValidateRoute(ctx, route)
+		})
+		// Only a terminal error rejects the route, any other is retried
+		if validationErr != nil && !hooks.IsTerminal(validationErr) {
+			return hooks.Result(fmt.Errorf("error executing validate route hook: %w", validationErr))
+		}
+	}
+
+	// The notifications are only sent once the status is persisted
+	var notifications []notify.Notification
+	parents := make([]gatewayv1.RouteParentStatus, 0, len(route.Spec.ParentRefs))
+	for _, parentRef := range route.Spec.ParentRefs {
+		gw, controllerName, err := r.managedGateway(ctx, route.GetNamespace(), parentRef)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if gw == nil {
+			continue
+		}
+
+		var result attachment.Result
+		if validationErr != nil {
+			result = attachment.Result{
+				Reason:  gatewayv1.RouteReasonUnsupportedValue,
+				Message: validationErr.Error(),
+			}
+		} else {
+			result = attachment.Resolve(attachRoute, parentRef, gw)
+		}
+
+		parent := r.parentStatus(originalRoute, parentRef, controllerName)
+		wasAccepted := isAccepted(parent)
+		if r.options.RouteHooks != nil && result.Accepted && !wasAccepted {
+			if err := hooks.Run(ctx, r.hooks.Runner, &route, "OnRouteAttached", func(ctx context.Context, route *gatewayv1.HTTPRoute) error {
+				return r.options.RouteHooks.OnRouteAttached(ctx, route, gw, result.Listeners)
+			}); err != nil {
+				if !hooks.IsTerminal(err) {
+					return hooks.Result(fmt.Errorf("error executing on route attached hook: %w", err))
+				}
+				// A terminal error rejects the route on this parent, without retrying
+				result = attachment.Result{
+					Reason:  gatewayv1.RouteReasonUnsupportedValue,
+					Message: err.Error(),
+				}
+			}
+		}
+
+		status := metav1.ConditionFalse
+		if result.Accepted {
+			status = metav1.ConditionTrue
+			if !wasAccepted {
+				notifications = append(notifications, notify.Notification{
+					Type:      notify.RouteAttached,
+					Name:      req.NamespacedName,
+					ParentRef: parentRef.DeepCopy(),
+					Listeners: result.Listeners,
+				})
+			}
+		}
+		tracker := conditions.NewTracker(&parent.Conditions, ownedConditions...)
+		tracker.Set(metav1.Condition{
+			Type:               string(gatewayv1.RouteConditionAccepted),
+			Status:             status,
+			Reason:             string(result.Reason),
+			Message:            result.Message,
+			LastTransitionTime: metav1.Now(),
+			ObservedGeneration: route.Generation,
+		})
+		tracker.Prune()
+		parents = append(parents, parent)
+	}
+
+	// Keep the parent status owned by other controllers, and drop the ones owned by
+	// kgame for parents that were removed from the route
+	for _, parent := range route.Status.Parents {
+		if !slices.Contains(r.controllerNames, parent.ControllerName) {
+			parents = append(parents, parent)
+		}
+	}
+	route.Status.Parents = parents
+
+	detached, err := r.detach(ctx, &route, originalRoute)
+	if err != nil {
+		return hooks.Result(err)
+	}
+	notifications = append(notifications, detached...)
+
+	if err := r.writeStatus(ctx, &route, originalRoute, notifications); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if err := r.hooks.PostReconcile(ctx, logger, &route); err != nil {
+		return r.hookError(ctx, &route, fmt.Errorf("error executing post reconcile hook: %w", err))
+	}
+
+	return reconcile.Result{RequeueAfter: r.resyncPeriod()}, nil
+}
+
+// hookError converts the error of a reconcile hook into the reconciliation
+// result. Terminal errors reject the route on each managed parent with reason
+// UnsupportedValue, using the error as the Accepted message, and are not retried
+func (r *reconciler) hookError(ctx context.Context, route *gatewayv1.HTTPRoute, err error) (reconcile.Result, error) {
+	if !hooks.IsTerminal(err) {
+		return hooks.Result(err)
+	}
+	originalRoute := route.DeepCopy()
+	parents := make([]gatewayv1.RouteParentStatus, 0, len(route.Spec.ParentRefs))
+	for _, parentRef := range route.Spec.ParentRefs {
+		gw, controllerName, getErr := r.managedGateway(ctx, route.GetNamespace(), parentRef)
+		if getErr != nil {
+			return reconcile.Result{}, getErr
+		}
+		if gw == nil {
+			continue
+		}
+		parent := r.parentStatus(originalRoute, parentRef, controllerName)
+		conditions.NewTracker(&parent.Conditions, ownedConditions...).Set(metav1.Condition{
+			Type:               string(gatewayv1.RouteConditionAccepted),
+			Status:             metav1.ConditionFalse,
+			Reason:             string(gatewayv1.RouteReasonUnsupportedValue),
+			Message:            err.Error(),
+			LastTransitionTime: metav1.Now(),
+			ObservedGeneration: route.Generation,
+		})
+		parents = append(parents, parent)
+	}
+	for _, parent := range originalRoute.Status.Parents {
+		if !slices.Contains(r.controllerNames, parent.ControllerName) {
+			parents = append(parents, parent)
+		}
+	}
+	route.Status.Parents = parents
+
+	notifications, detachErr := r.detach(ctx, route, originalRoute)
+	if detachErr != nil {
+		return hooks.Result(detachErr)
+	}
+	if writeErr := r.writeStatus(ctx, route, originalRoute, notifications); writeErr != nil {
+		return reconcile.Result{}, writeErr
+	}
+	return hooks.Result(err)
+}
+
+// detach calls the OnRouteDetached hook for each managed parent accepted on
+// originalRoute and not on route, returning the RouteDetached notifications
+func (r *reconciler) detach(ctx context.Context, route, originalRoute *gatewayv1.HTTPRoute) ([]notify.Notification, error) {
+	var notifications []notify.Notification
+	for _, parent := range originalRoute.Status.Parents {
+		if !slices.Contains(r.controllerNames, parent.ControllerName) || !isAccepted(parent) {
+			continue
+		}
+		if current := r.parentStatus(route, parent.ParentRef, parent.ControllerName); isAccepted(current) {
+			continue
+		}
+		if r.options.RouteHooks != nil {
+			if err := hooks.Run(ctx, r.hooks.Runner, route, "OnRouteDetached", func(ctx context.Context, route *gatewayv1.HTTPRoute) error {
+				return r.options.RouteHooks.OnRouteDetached(ctx, route, parent.ParentRef)
+			}); err != nil {
+				if !hooks.IsTerminal(err) {
+					return nil, fmt.Errorf("error executing on route detached hook: %w", err)
+				}
+				// A terminal error is not retried, as the route is already detached
+				// from the parent. It is added to the Accepted message of the parent,
+				// if the route still references it
+				if idx := parentIndex(route, parent.ParentRef, parent.ControllerName); idx >= 0 {
+					if condition := meta.FindStatusCondition(route.Status.Parents[idx].Conditions, string(gatewayv1.RouteConditionAccepted)); condition != nil {
+						condition.Message = fmt.Sprintf("%s: %v", condition.Message, err)
+					}
+				}
+			}
+		}
+		notifications = append(notifications, notify.Notification{
+			Type:      notify.RouteDetached,
+			Name:      client.ObjectKeyFromObject(route),
+			ParentRef: parent.ParentRef.DeepCopy(),
+		})
+	}
+	return notifications, nil
+}
+
+// detachDeleted detaches a deleted route from the parents accepting it on its
+// last reconciliation. The RouteDetached notifications have no Object, as the
+// removal notifications
+func (r *reconciler) detachDeleted(ctx context.Context, route *gatewayv1.HTTPRoute) error {
+	key := client.ObjectKeyFromObject(route)
+	r.mu.Lock()
+	parents := r.attached[key]
+	r.mu.Unlock()
+	if len(parents) == 0 {
+		return nil
+	}
+	originalRoute := route.DeepCopy()
+	originalRoute.Status.Parents = parents
+	notifications, err := r.detach(ctx, route, originalRoute)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	delete(r.attached, key)
+	r.mu.Unlock()
+	for _, notification := range notifications {
+		r.options.Notify.Send(ctx, notification)
+	}
+	return nil
+}
+
+// track keeps the managed parents accepting the route, see detachDeleted
+func (r *reconciler) track(route *gatewayv1.HTTPRoute) {
+	var parents []gatewayv1.RouteParentStatus
+	for _, parent := range route.Status.Parents {
+		if slices.Contains(r.controllerNames, parent.ControllerName) && isAccepted(parent) {
+			parents = append(parents, *parent.DeepCopy())
+		}
+	}
+	key := client.ObjectKeyFromObject(route)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(parents) == 0 {
+		delete(r.attached, key)
+		return
+	}
+	r.attached[key] = parents
+}
+
+// writeStatus writes the route status, if changed, and then sends the
+// notifications, as they are only sent once the status is persisted
+func (r *reconciler) writeStatus(ctx context.Context, route, originalRoute *gatewayv1.HTTPRoute, notifications []notify.Notification) error {
+	if !equality.Semantic.DeepEqual(originalRoute.Status, route.Status) {
+		if err := r.status.Write(ctx, route, originalRoute); err != nil {
+			return fmt.Errorf("error patching the parent status of %s: %w", client.ObjectKeyFromObject(route), err)
+		}
+	}
+	r.track(route)
+	for _, notification := range notifications {
+		notification.Object = route.DeepCopy()
+		r.options.Notify.Send(ctx, notification)
+	}
+	return nil
+}
+
+// managedGateway returns the Gateway referenced by parentRef and the
+// controllerName of its GatewayClass, or nil if the parentRef is not a Gateway,
+// does not exist, or is not managed by this controller.
+// As the GatewayClass cache only contains managed classes, a Gateway whose class
+// cannot be found is not managed
+func (r *reconciler) managedGateway(ctx context.Context, routeNamespace string, parentRef gatewayv1.ParentReference) (*gatewayv1.Gateway, gatewayv1.GatewayController, error) {
+	if parentRef.Group != nil && *parentRef.Group != gatewayv1.GroupName {
+		return nil, "", nil
+	}
+	if parentRef.Kind != nil && *parentRef.Kind != "Gateway" {
+		return nil, "", nil
+	}
+
+	namespace := routeNamespace
+	if parentRef.Namespace != nil {
+		namespace = string(*parentRef.Namespace)
+	}
+
+	gw := &gatewayv1.Gateway{}
+	if err := r.client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: string(parentRef.Name)}, gw); err != nil {
+		return nil, "", client.IgnoreNotFound(err)
+	}
+
+	gatewayClass := &gatewayv1.GatewayClass{}
+	if err := r.client.Get(ctx, types.NamespacedName{Name: string(gw.Spec.GatewayClassName)}, gatewayClass); err != nil {
+		return nil, "", client.IgnoreNotFound(err)
+	}
+	if !slices.Contains(r.controllerNames, gatewayClass.Spec.ControllerName) {
+		return nil, "", nil
+	}
+	return gw, gatewayClass.Spec.ControllerName, nil
+}
+
+// parentStatus returns a copy of the existing parent status owned by
+// controllerName for parentRef, or a new one if none exists
+func (r *reconciler) parentStatus(route *gatewayv1.HTTPRoute, parentRef gatewayv1.ParentReference, controllerName gatewayv1.GatewayController) gatewayv1.RouteParentStatus {
+	if idx := parentIndex(route, parentRef, controllerName); idx >= 0 {
+		return *route.Status.Parents[idx].DeepCopy()
+	}
+	return gatewayv1.RouteParentStatus{
+		ParentRef:      parentRef,
+		ControllerName: controllerName,
+	}
+}
+
+// parentIndex returns the index of the parent status owned by controllerName for
+// parentRef, or -1 if none exists
+func parentIndex(route *gatewayv1.HTTPRoute, parentRef gatewayv1.ParentReference, controllerName gatewayv1.GatewayController) int {
+	return slices.IndexFunc(route.Status.Parents, func(p gatewayv1.RouteParentStatus) bool {
+		return p.ControllerName == controllerName && reflect.DeepEqual(p.ParentRef, parentRef)
+	})
+}
+
+// isAccepted returns true if the parent status has the Accepted condition as True
+func isAccepted(parent gatewayv1.RouteParentStatus) bool {
+	return meta.IsStatusConditionTrue(parent.Conditions, string(gatewayv1.RouteConditionAccepted))
+}
+
+// resyncPeriod returns the ResyncPeriod, or the one set at runtime on the Settings
+func (r *reconciler) resyncPeriod() time.Duration {
+	if r.options.Settings != nil {
+		return r.options.Settings.ResyncPeriod()
+	}
+	return r.options.ResyncPeriod
+}
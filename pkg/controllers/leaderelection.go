@@ -0,0 +1,106 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// LeaderElectionOptions configures the leader election of the manager, so only
+// one of the replicas of the controller programs the dataplanes
+type LeaderElectionOptions struct {
+	// Enabled enables the leader election. Disabled by default
+	Enabled bool
+	// Namespace of the Lease. Defaults to the namespace kgame runs on, when
+	// running in cluster
+	Namespace string
+	// Name of the Lease. Defaults to the ControllerName followed by
+	// "-leader-election"
+	Name string
+	// LeaseDuration, RenewDeadline and RetryPeriod are the timings of the leader
+	// election. Default to the controller-runtime ones, of 15, 10 and 2 seconds
+	LeaseDuration time.Duration
+	RenewDeadline time.Duration
+	RetryPeriod   time.Duration
+	// ReleaseOnCancel releases the Lease when the manager stops, so a new leader is
+	// elected without waiting for the LeaseDuration. The program must exit once the
+	// manager stops
+	ReleaseOnCancel bool
+}
+
+// WithLeaderElection enables the leader election with the given options
+func WithLeaderElection(options LeaderElectionOptions) Option {
+	return OptionFunc(func(opts *ControllerOptions) {
+		opts.LeaderElection = options
+		opts.LeaderElection.Enabled = true
+	})
+}
+
+// leaderElectionName returns the Lease name of the options
+func (o LeaderElectionOptions) leaderElectionName(controllerName string) string {
+	if o.Name != "" {
+		return o.Name
+	}
+	return strings.ToLower(controllerName) + "-leader-election"
+}
+
+// apply sets the leader election on the manager options
+func (o LeaderElectionOptions) apply(controllerName string, options *ctrl.Options) {
+	if !o.Enabled {
+		return
+	}
+	options.LeaderElection = true
+	options.LeaderElectionID = o.leaderElectionName(controllerName)
+	options.LeaderElectionNamespace = o.Namespace
+	options.LeaderElectionReleaseOnCancel = o.ReleaseOnCancel
+	if o.LeaseDuration > 0 {
+		options.LeaseDuration = &o.LeaseDuration
+	}
+	if o.RenewDeadline > 0 {
+		options.RenewDeadline = &o.RenewDeadline
+	}
+	if o.RetryPeriod > 0 {
+		options.RetryPeriod = &o.RetryPeriod
+	}
+}
+
+// validate returns the errors of the leader election options
+func (o LeaderElectionOptions) validate(controllerName string) []error {
+	if !o.Enabled {
+		return nil
+	}
+	var errs []error
+	if msgs := validation.IsDNS1123Subdomain(o.leaderElectionName(controllerName)); len(msgs) > 0 {
+		errs = append(errs, fmt.Errorf("LeaderElection.Name %q is not a valid Lease name: %s", o.leaderElectionName(controllerName), strings.Join(msgs, ", ")))
+	}
+	if o.LeaseDuration < 0 || o.RenewDeadline < 0 || o.RetryPeriod < 0 {
+		errs = append(errs, errors.New("LeaderElection durations must not be negative"))
+	}
+	if o.LeaseDuration > 0 && o.RenewDeadline > 0 && o.RenewDeadline >= o.LeaseDuration {
+		errs = append(errs, fmt.Errorf("LeaderElection.RenewDeadline (%s) must be lower than LeaseDuration (%s)", o.RenewDeadline, o.LeaseDuration))
+	}
+	if o.RenewDeadline > 0 && o.RetryPeriod > 0 && o.RetryPeriod >= o.RenewDeadline {
+		errs = append(errs, fmt.Errorf("LeaderElection.RetryPeriod (%s) must be lower than RenewDeadline (%s)", o.RetryPeriod, o.RenewDeadline))
+	}
+	return errs
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"github.com/rikatz/kgame/pkg/controllers/gateway"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// validateListenerConflicts rejects the listeners returned by conflicts, like the
+// ones that cannot be merged on the shared dataplane of the Provisioner
+// MergeGateways, after the validation of next, so each Gateway reports the
+// listeners served by its dataplane
+func validateListenerConflicts(conflicts func(ctx context.Context, gw *gatewayv1.Gateway) (map[gatewayv1.SectionName]string, error), next gateway.ValidateGatewayFunc, logger logr.Logger) gateway.ValidateGatewayFunc {
+	return func(ctx context.Context, gw *gatewayv1.Gateway) []gateway.ValidationError {
+		var errs []gateway.ValidationError
+		if next != nil {
+			errs = next(ctx, gw)
+		}
+		listeners, err := conflicts(ctx, gw)
+		if err != nil {
+			logger.Error(err, "unable to check the listener conflicts of the dataplane", "gateway", gw.GetNamespace()+"/"+gw.GetName())
+			return errs
+		}
+		for _, listener := range gw.Spec.Listeners {
+			if message, ok := listeners[listener.Name]; ok {
+				errs = append(errs, gateway.ValidationError{
+					Listener: listener.Name,
+					Reason:   string(gatewayv1.ListenerReasonPortUnavailable),
+					Message:  message,
+				})
+			}
+		}
+		return errs
+	}
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/rikatz/kgame/pkg/attachment"
+	"github.com/rikatz/kgame/pkg/tunables"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// MetricsOptions configures the metrics server of the manager created by
+// NewController
+type MetricsOptions struct {
+	// BindAddress is the address of the metrics server, like ":8443". "0"
+	// disables the server. Defaults to the controller-runtime one, ":8080"
+	BindAddress string
+	// SecureServing serves the metrics over HTTPS, with a self signed certificate
+	// unless CertDir is set
+	SecureServing bool
+	// CertDir, CertName and KeyName are the certificate used when SecureServing is
+	// enabled. CertName and KeyName default to tls.crt and tls.key
+	CertDir  string
+	CertName string
+	KeyName  string
+	// FilterProvider protects the metrics endpoint, like
+	// sigs.k8s.io/controller-runtime/pkg/metrics/filters.WithAuthenticationAndAuthorization
+	FilterProvider func(c *rest.Config, httpClient *http.Client) (metricsserver.Filter, error)
+	// Registry is the registry of the implementer metrics, like the dataplane
+	// ones. When set the metrics server also exposes the metrics of Registry on
+	// RegistryPath, behind the same FilterProvider. The kgame and
+	// controller-runtime metrics stay on the controller-runtime metrics.Registry,
+	// served on /metrics
+	Registry ctrlmetrics.RegistererGatherer
+	// RegistryPath is the path of the Registry metrics. Defaults to
+	// /metrics/implementer
+	RegistryPath string
+	// CacheInterval is the interval of the measure of the cached objects of each
+	// kind, exported as kgame_cache_objects and kgame_cache_bytes. Encoding all
+	// the cached objects is costly on large clusters, so it is disabled when zero
+	CacheInterval time.Duration
+}
+
+// defaultRegistryPath is the path of the implementer metrics
+const defaultRegistryPath = "/metrics/implementer"
+
+// serverOptions returns the metrics server options, serving the Registry on its
+// own path when it is set
+func (o MetricsOptions) serverOptions() metricsserver.Options {
+	options := metricsserver.Options{
+		BindAddress:    o.BindAddress,
+		SecureServing:  o.SecureServing,
+		CertDir:        o.CertDir,
+		CertName:       o.CertName,
+		KeyName:        o.KeyName,
+		FilterProvider: o.FilterProvider,
+	}
+	if o.Registry != nil {
+		path := o.RegistryPath
+		if path == "" {
+			path = defaultRegistryPath
+		}
+		options.ExtraHandlers = map[string]http.Handler{
+			path: promhttp.HandlerFor(o.Registry, promhttp.HandlerOpts{ErrorHandling: promhttp.HTTPErrorOnError}),
+		}
+	}
+	return options
+}
+
+// addCacheMetrics measures the cache of the kinds read by kgame, when the
+// CacheInterval is set
+func (o MetricsOptions) addCacheMetrics(mgr manager.Manager) error {
+	if o.CacheInterval <= 0 {
+		return nil
+	}
+	return mgr.Add(tunables.NewCacheMetrics(mgr.GetCache(), mgr.GetScheme(), mgr.GetLogger(), o.CacheInterval,
+		&gatewayv1.GatewayClass{},
+		&gatewayv1.Gateway{},
+		&gatewayv1.HTTPRoute{},
+		attachment.NamespaceMetadata(),
+		&corev1.Service{},
+		&corev1.Secret{},
+	))
+}
+
+// WithMetrics sets the metrics server options
+func WithMetrics(options MetricsOptions) Option {
+	return OptionFunc(func(opts *ControllerOptions) {
+		opts.Metrics = options
+	})
+}
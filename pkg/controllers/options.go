@@ -0,0 +1,403 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/rikatz/kgame/pkg/controllers/gateway"
+	"github.com/rikatz/kgame/pkg/controllers/gatewayclass"
+	"github.com/rikatz/kgame/pkg/controllers/httproute"
+	"github.com/rikatz/kgame/pkg/health"
+	"github.com/rikatz/kgame/pkg/logging"
+	"github.com/rikatz/kgame/pkg/notify"
+	"github.com/rikatz/kgame/pkg/provisioner"
+	"github.com/rikatz/kgame/pkg/sharding"
+	"github.com/rikatz/kgame/pkg/tunables"
+	"github.com/rikatz/kgame/pkg/webhooks"
+	"github.com/rikatz/kgame/pkg/writer"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+)
+
+// Option configures the NewController and SetupAllWithManager ControllerOptions.
+// A *ControllerOptions is also an Option, replacing all the options set before it
+type Option interface {
+	apply(opts *ControllerOptions)
+}
+
+// OptionFunc is an Option changing the ControllerOptions in place, like to set
+// options loaded by another package
+type OptionFunc func(opts *ControllerOptions)
+
+func (f OptionFunc) apply(opts *ControllerOptions) {
+	f(opts)
+}
+
+func (o *ControllerOptions) apply(opts *ControllerOptions) {
+	if o != nil {
+		*opts = *o
+	}
+}
+
+// WithControllerClass sets the GatewayClass controllerName managed by kgame
+func WithControllerClass(class string) Option {
+	return OptionFunc(func(opts *ControllerOptions) {
+		opts.ControllerClass = class
+	})
+}
+
+// WithControllerClasses adds GatewayClass controllerNames managed by kgame,
+// besides the ControllerClass
+func WithControllerClasses(classes ...string) Option {
+	return OptionFunc(func(opts *ControllerOptions) {
+		opts.ControllerClasses = append(opts.ControllerClasses, classes...)
+	})
+}
+
+// WithControllerName sets the name of the controller, used on its logs
+func WithControllerName(name string) Option {
+	return OptionFunc(func(opts *ControllerOptions) {
+		opts.ControllerName = name
+	})
+}
+
+// WithRestConfig sets the configuration of the cluster kgame connects to
+func WithRestConfig(config *rest.Config) Option {
+	return OptionFunc(func(opts *ControllerOptions) {
+		opts.RestConfig = config
+	})
+}
+
+// WithProvisioner enables the provisioning of the dataplane of each accepted
+// Gateway
+func WithProvisioner(options provisioner.Options) Option {
+	return OptionFunc(func(opts *ControllerOptions) {
+		opts.Provisioner = &options
+	})
+}
+
+// WithClient sets the throttling of the requests to the API server
+func WithClient(options ClientOptions) Option {
+	return OptionFunc(func(opts *ControllerOptions) {
+		opts.Client = options
+	})
+}
+
+// WithGatewayClassOptions sets the options of the GatewayClass controller
+func WithGatewayClassOptions(options gatewayclass.GatewayClassOptions) Option {
+	return OptionFunc(func(opts *ControllerOptions) {
+		opts.GatewayClassOptions = options
+	})
+}
+
+// WithGatewayOptions sets the options of the Gateway controller
+func WithGatewayOptions(options gateway.GatewayOptions) Option {
+	return OptionFunc(func(opts *ControllerOptions) {
+		opts.GatewayOptions = options
+	})
+}
+
+// WithHTTPRouteOptions sets the options of the HTTPRoute controller
+func WithHTTPRouteOptions(options httproute.HTTPRouteOptions) Option {
+	return OptionFunc(func(opts *ControllerOptions) {
+		opts.HTTPRouteOptions = options
+	})
+}
+
+// WithGatewayClassHooks sets the reconcile hooks of the GatewayClass controller
+func WithGatewayClassHooks(hooks gatewayclass.ReconcileHooks) Option {
+	return OptionFunc(func(opts *ControllerOptions) {
+		opts.GatewayClassOptions.ReconcileHooks = hooks
+	})
+}
+
+// WithGatewayHooks sets the reconcile hooks of the Gateway controller
+func WithGatewayHooks(hooks gateway.ReconcileHooks) Option {
+	return OptionFunc(func(opts *ControllerOptions) {
+		opts.GatewayOptions.ReconcileHooks = hooks
+	})
+}
+
+// WithHTTPRouteHooks sets the reconcile hooks of the HTTPRoute controller
+func WithHTTPRouteHooks(hooks httproute.ReconcileHooks) Option {
+	return OptionFunc(func(opts *ControllerOptions) {
+		opts.HTTPRouteOptions.ReconcileHooks = hooks
+	})
+}
+
+// WithProgrammer sets the Programmer of the Gateways
+func WithProgrammer(programmer gateway.Programmer) Option {
+	return OptionFunc(func(opts *ControllerOptions) {
+		opts.GatewayOptions.Programmer = programmer
+	})
+}
+
+// WithStatusReport enables the controller health report
+func WithStatusReport(options health.StatusOptions) Option {
+	return OptionFunc(func(opts *ControllerOptions) {
+		opts.StatusReport = options
+	})
+}
+
+// WithNotify sets the receiver of the notifications of all the controllers
+func WithNotify(f notify.Func) Option {
+	return OptionFunc(func(opts *ControllerOptions) {
+		opts.Notify = f
+	})
+}
+
+// WithWebhooks sets the admission webhooks options
+func WithWebhooks(options webhooks.Options) Option {
+	return OptionFunc(func(opts *ControllerOptions) {
+		opts.Webhooks = options
+	})
+}
+
+// WithParametersKinds adds kinds of the GatewayClass and Gateway infrastructure
+// parameters, watched to reconcile the classes and Gateways using them
+func WithParametersKinds(kinds ...schema.GroupVersionKind) Option {
+	return OptionFunc(func(opts *ControllerOptions) {
+		opts.ParametersKinds = append(opts.ParametersKinds, kinds...)
+	})
+}
+
+// WithPolicyKinds adds policy kinds attached to the nodes of the Snapshot
+func WithPolicyKinds(kinds ...schema.GroupVersionKind) Option {
+	return OptionFunc(func(opts *ControllerOptions) {
+		opts.PolicyKinds = append(opts.PolicyKinds, kinds...)
+	})
+}
+
+// WithNamespaces limits the namespaced resources watched by kgame to namespaces
+func WithNamespaces(namespaces ...string) Option {
+	return OptionFunc(func(opts *ControllerOptions) {
+		opts.Namespaces = append(opts.Namespaces, namespaces...)
+	})
+}
+
+// WithSelectors limits the cached resources to the ones matching the selectors
+func WithSelectors(selectors ResourceSelectors) Option {
+	return OptionFunc(func(opts *ControllerOptions) {
+		opts.Selectors = selectors
+	})
+}
+
+// WithMaxConcurrentReconciles sets the number of parallel reconciliations of all
+// the controllers
+func WithMaxConcurrentReconciles(n int) Option {
+	return OptionFunc(func(opts *ControllerOptions) {
+		opts.GatewayClassOptions.MaxConcurrentReconciles = n
+		opts.GatewayOptions.MaxConcurrentReconciles = n
+		opts.HTTPRouteOptions.MaxConcurrentReconciles = n
+	})
+}
+
+// WithSyncPeriod sets the period of the full resync of the informer caches
+func WithSyncPeriod(period time.Duration) Option {
+	return OptionFunc(func(opts *ControllerOptions) {
+		opts.SyncPeriod = period
+	})
+}
+
+// WithoutControllers disables the controllers of the given kinds
+func WithoutControllers(kinds ...Kind) Option {
+	return OptionFunc(func(opts *ControllerOptions) {
+		opts.DisabledControllers = append(opts.DisabledControllers, kinds...)
+	})
+}
+
+// WithScheme registers additional types on the manager scheme
+func WithScheme(addToScheme ...func(*runtime.Scheme) error) Option {
+	return OptionFunc(func(opts *ControllerOptions) {
+		opts.AddToScheme = append(opts.AddToScheme, addToScheme...)
+	})
+}
+
+// WithPprof serves the pprof endpoints on address
+func WithPprof(address string) Option {
+	return OptionFunc(func(opts *ControllerOptions) {
+		opts.PprofBindAddress = address
+	})
+}
+
+// WithLogger sets the logger of the kgame controllers. The controller-runtime
+// global logger is set too when global is true
+func WithLogger(logger logr.Logger, global bool) Option {
+	return OptionFunc(func(opts *ControllerOptions) {
+		opts.Logger = logger
+		opts.SetGlobalLogger = global
+	})
+}
+
+// WithLogging creates the logger of the kgame controllers with the given format
+// and verbosities
+func WithLogging(options logging.Options) Option {
+	return OptionFunc(func(opts *ControllerOptions) {
+		opts.Logging = &options
+	})
+}
+
+// WithOnShutdown adds hooks called by Start once the manager is stopped
+func WithOnShutdown(hooks ...func(ctx context.Context)) Option {
+	return OptionFunc(func(opts *ControllerOptions) {
+		opts.OnShutdown = append(opts.OnShutdown, hooks...)
+	})
+}
+
+// WithWaitForCacheSync makes Start return once the caches are synced
+func WithWaitForCacheSync() Option {
+	return OptionFunc(func(opts *ControllerOptions) {
+		opts.WaitForCacheSync = true
+	})
+}
+
+// WithRouteCache sets the routes dropped from the cache, like
+// WithRouteCache(tunables.RouteTransformOptions{DropNonGatewayParents: true})
+func WithRouteCache(options tunables.RouteTransformOptions) Option {
+	return OptionFunc(func(opts *ControllerOptions) {
+		opts.RouteCache = options
+	})
+}
+
+// WithSharding partitions the reconciliations across the given number of active
+// replicas, this one being the ordinal, like
+// WithSharding(sharding.Options{Replicas: 3, Ordinal: 1})
+func WithSharding(options sharding.Options) Option {
+	return OptionFunc(func(opts *ControllerOptions) {
+		opts.Sharding = options
+	})
+}
+
+// WithWrites sets the field manager and the status write strategy of all the
+// controllers, like WithWrites(writer.Options{FieldManager: "mylab",
+// Strategy: writer.StrategyServerSideApply})
+func WithWrites(options writer.Options) Option {
+	return OptionFunc(func(opts *ControllerOptions) {
+		opts.Writes = options
+	})
+}
+
+// buildOptions applies the options over the defaults, and validates the result
+func buildOptions(options []Option) (*ControllerOptions, error) {
+	opts := &ControllerOptions{}
+	for _, option := range options {
+		if option != nil {
+			option.apply(opts)
+		}
+	}
+	setDefaults(opts)
+	if err := validate(opts); err != nil {
+		return nil, err
+	}
+	return opts, nil
+}
+
+// validate returns all the invalid options, joined
+func validate(opts *ControllerOptions) error {
+	var errs []error
+	if opts.GatewayOptions.WaitForRoutesOnDelete && opts.GatewayOptions.FinalizerName == "" {
+		errs = append(errs, errors.New("GatewayOptions.WaitForRoutesOnDelete requires GatewayOptions.FinalizerName to be set"))
+	}
+	if opts.GatewayOptions.ValidateOverridesFunc != nil && opts.GatewayOptions.AnnotationPrefix == "" {
+		errs = append(errs, errors.New("GatewayOptions.ValidateOverridesFunc requires GatewayOptions.AnnotationPrefix to be set"))
+	}
+	for i, class := range opts.ControllerClasses {
+		if class == "" || class == opts.ControllerClass || slices.Contains(opts.ControllerClasses[:i], class) {
+			errs = append(errs, fmt.Errorf("ControllerClasses must be unique and not empty, and not contain the ControllerClass, got %q", class))
+		}
+	}
+	writes := []struct {
+		name  string
+		value writer.Options
+	}{
+		{"Writes", opts.Writes},
+		{"GatewayClassOptions.Writes", opts.GatewayClassOptions.Writes},
+		{"GatewayOptions.Writes", opts.GatewayOptions.Writes},
+		{"HTTPRouteOptions.Writes", opts.HTTPRouteOptions.Writes},
+	}
+	for _, w := range writes {
+		if err := w.value.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("invalid %s: %w", w.name, err))
+		}
+	}
+	durations := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"GatewayClassOptions.HookTimeout", opts.GatewayClassOptions.HookTimeout},
+		{"GatewayClassOptions.PendingRequeueInterval", opts.GatewayClassOptions.PendingRequeueInterval},
+		{"GatewayOptions.HookTimeout", opts.GatewayOptions.HookTimeout},
+		{"GatewayOptions.RouteDrainTimeout", opts.GatewayOptions.RouteDrainTimeout},
+		{"HTTPRouteOptions.HookTimeout", opts.HTTPRouteOptions.HookTimeout},
+		{"GatewayClassOptions.ResyncPeriod", opts.GatewayClassOptions.ResyncPeriod},
+		{"GatewayOptions.ResyncPeriod", opts.GatewayOptions.ResyncPeriod},
+		{"HTTPRouteOptions.ResyncPeriod", opts.HTTPRouteOptions.ResyncPeriod},
+		{"SyncPeriod", opts.SyncPeriod},
+		{"ShutdownTimeout", opts.ShutdownTimeout},
+		{"Metrics.CacheInterval", opts.Metrics.CacheInterval},
+		{"GatewayClassOptions.RateLimit.Debounce", opts.GatewayClassOptions.RateLimit.Debounce},
+		{"GatewayOptions.RateLimit.Debounce", opts.GatewayOptions.RateLimit.Debounce},
+		{"HTTPRouteOptions.RateLimit.Debounce", opts.HTTPRouteOptions.RateLimit.Debounce},
+	}
+	for _, duration := range durations {
+		if duration.value < 0 {
+			errs = append(errs, fmt.Errorf("%s must not be negative, got %s", duration.name, duration.value))
+		}
+	}
+	if opts.GatewayOptions.ProgramParallelism < 0 {
+		errs = append(errs, errors.New("GatewayOptions.ProgramParallelism must not be negative"))
+	}
+	if opts.GatewayClassOptions.MaxConcurrentReconciles < 0 || opts.GatewayOptions.MaxConcurrentReconciles < 0 || opts.HTTPRouteOptions.MaxConcurrentReconciles < 0 {
+		errs = append(errs, errors.New("MaxConcurrentReconciles must not be negative"))
+	}
+	for _, kind := range opts.DisabledControllers {
+		if kind != KindGatewayClass && kind != KindGateway && kind != KindHTTPRoute {
+			errs = append(errs, fmt.Errorf("DisabledControllers contains the unsupported kind %q", kind))
+		}
+	}
+	if bootstrap := opts.Webhooks.CertBootstrap; opts.Webhooks.Enabled && bootstrap != nil {
+		if bootstrap.ServiceName == "" || bootstrap.ServiceNamespace == "" || bootstrap.SecretName == "" {
+			errs = append(errs, errors.New("Webhooks.CertBootstrap requires ServiceName, ServiceNamespace and SecretName to be set"))
+		}
+	}
+	if opts.ConfigReload.MaxConcurrentReconciles < 0 {
+		errs = append(errs, errors.New("ConfigReload.MaxConcurrentReconciles must not be negative"))
+	}
+	errs = append(errs, opts.LeaderElection.validate(opts.ControllerName)...)
+	if opts.Provisioner != nil {
+		if err := opts.Provisioner.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("invalid Provisioner: %w", err))
+		}
+	}
+	if err := opts.Client.validate(); err != nil {
+		errs = append(errs, fmt.Errorf("invalid Client: %w", err))
+	}
+	if err := opts.Sharding.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("invalid Sharding: %w", err))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("invalid controller options: %w", err)
+	}
+	return nil
+}
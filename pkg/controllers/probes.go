@@ -0,0 +1,124 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+)
+
+// cacheSyncTimeout is the maximum time the readiness check waits for the caches
+const cacheSyncTimeout = time.Second
+
+// ProbeOptions configures the health and readiness probes. The kgame checks are
+// registered with the "kgame-" prefix, so they do not conflict with the checks of
+// managers passed to SetupAllWithManager:
+//   - kgame-ping, on healthz, is healthy while the probes server is up
+//   - kgame-cache-sync, on readyz, is ready once the informer caches are synced
+//   - kgame-webhook, on readyz, is ready once the webhook server is serving, when
+//     the webhooks are enabled
+//   - kgame-leader, on readyz, is ready once this replica is the leader, when
+//     RequireLeader is set
+//   - kgame-watch-errors, on readyz, is not ready while any informer fails to
+//     list or watch, when ControllerOptions.WatchErrors has a window
+type ProbeOptions struct {
+	// BindAddress is the address of the probes server of the manager created by
+	// NewController, like ":8081". Disabled when empty
+	BindAddress string
+	// RequireLeader reports the replicas that are not the leader as not ready
+	RequireLeader bool
+	// HealthCheckers are additional healthz checks, by name
+	HealthCheckers map[string]healthz.Checker
+	// ReadyCheckers are additional readyz checks, by name, like the dataplane
+	// connectivity
+	ReadyCheckers map[string]healthz.Checker
+}
+
+// WithProbes sets the health and readiness probes options
+func WithProbes(options ProbeOptions) Option {
+	return OptionFunc(func(opts *ControllerOptions) {
+		opts.Probes = options
+	})
+}
+
+// addProbes registers the kgame and the additional checks on the manager
+func addProbes(mgr ctrl.Manager, opts *ControllerOptions) error {
+	if err := mgr.AddHealthzCheck("kgame-ping", healthz.Ping); err != nil {
+		return fmt.Errorf("unable to add the ping check: %w", err)
+	}
+	if err := mgr.AddReadyzCheck("kgame-cache-sync", cacheSyncChecker(mgr)); err != nil {
+		return fmt.Errorf("unable to add the cache sync check: %w", err)
+	}
+	if opts.Webhooks.Enabled {
+		if err := mgr.AddReadyzCheck("kgame-webhook", mgr.GetWebhookServer().StartedChecker()); err != nil {
+			return fmt.Errorf("unable to add the webhook check: %w", err)
+		}
+	}
+	if opts.Probes.RequireLeader {
+		if err := mgr.AddReadyzCheck("kgame-leader", leaderChecker(mgr)); err != nil {
+			return fmt.Errorf("unable to add the leader check: %w", err)
+		}
+	}
+
+	if opts.WatchErrors != nil && opts.WatchErrors.window > 0 {
+		if err := mgr.AddReadyzCheck("kgame-watch-errors", opts.WatchErrors.checker()); err != nil {
+			return fmt.Errorf("unable to add the watch errors check: %w", err)
+		}
+	}
+
+	for name, checker := range opts.Probes.HealthCheckers {
+		if err := mgr.AddHealthzCheck(name, checker); err != nil {
+			return fmt.Errorf("unable to add the %s health check: %w", name, err)
+		}
+	}
+	for name, checker := range opts.Probes.ReadyCheckers {
+		if err := mgr.AddReadyzCheck(name, checker); err != nil {
+			return fmt.Errorf("unable to add the %s ready check: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// cacheSyncChecker is ready once the manager caches are synced
+func cacheSyncChecker(mgr ctrl.Manager) healthz.Checker {
+	return func(req *http.Request) error {
+		ctx, cancel := context.WithTimeout(req.Context(), cacheSyncTimeout)
+		defer cancel()
+		if !mgr.GetCache().WaitForCacheSync(ctx) {
+			return errors.New("informer caches are not synced")
+		}
+		return nil
+	}
+}
+
+// leaderChecker is ready once the manager is elected
+func leaderChecker(mgr ctrl.Manager) healthz.Checker {
+	return func(*http.Request) error {
+		select {
+		case <-mgr.Elected():
+			return nil
+		default:
+			return errors.New("this replica is not the leader")
+		}
+	}
+}
@@ -0,0 +1,268 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	configv1alpha1 "github.com/rikatz/kgame/pkg/apis/config/v1alpha1"
+	"github.com/rikatz/kgame/pkg/logging"
+	"github.com/rikatz/kgame/pkg/reload"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// defaultReloadCeiling is the number of workers of each controller when the
+// KgameConfig reload is enabled and no ceiling is configured
+const defaultReloadCeiling = 10
+
+// ConfigReloadOptions configures the reload of the KgameConfig resource, the
+// cluster scoped configuration of the settings that are safe to change at
+// runtime: the log levels, the concurrency and resync period of each controller,
+// and the feature gates. Every replica applies the configuration, not only the
+// leader. A KgameConfig with invalid values is ignored with a Warning event, and
+// the previous configuration is kept. Removing the KgameConfig restores the
+// startup configuration, except for the log level that keeps its last value
+type ConfigReloadOptions struct {
+	// Enabled watches the KgameConfig. Its CRD must be installed on the cluster
+	Enabled bool
+	// Name of the KgameConfig. Defaults to the ControllerName
+	Name string
+	// MaxConcurrentReconciles is the number of workers of each controller, the
+	// maximum concurrency the KgameConfig can set. Defaults to 10, or to the
+	// MaxConcurrentReconciles of the controller when higher
+	MaxConcurrentReconciles int
+}
+
+// WithConfigReload sets the KgameConfig reload options
+func WithConfigReload(options ConfigReloadOptions) Option {
+	return OptionFunc(func(opts *ControllerOptions) {
+		opts.ConfigReload = options
+	})
+}
+
+// WithFeatureGates sets the feature gates read through Controller.FeatureEnabled
+func WithFeatureGates(gates map[string]bool) Option {
+	return OptionFunc(func(opts *ControllerOptions) {
+		opts.FeatureGates = gates
+	})
+}
+
+// FeatureEnabled returns true if the feature gate is enabled, on the KgameConfig
+// or on the ControllerOptions FeatureGates. Unknown gates are disabled
+func (k *Controller) FeatureEnabled(name string) bool {
+	return k.featureGates.enabled(name)
+}
+
+// featureGates are the startup feature gates, overridden by the KgameConfig ones
+type featureGates struct {
+	mu      sync.RWMutex
+	startup map[string]bool
+	current map[string]bool
+}
+
+func newFeatureGates(startup map[string]bool) *featureGates {
+	return &featureGates{startup: startup, current: startup}
+}
+
+func (g *featureGates) enabled(name string) bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.current[name]
+}
+
+// set overrides the startup gates with gates
+func (g *featureGates) set(gates map[string]bool) {
+	current := maps.Clone(g.startup)
+	if current == nil {
+		current = make(map[string]bool, len(gates))
+	}
+	maps.Copy(current, gates)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.current = current
+}
+
+// reloadableController are the startup settings of a controller, restored when
+// the KgameConfig does not configure them
+type reloadableController struct {
+	settings                *reload.Settings
+	maxConcurrentReconciles int
+	resyncPeriod            time.Duration
+}
+
+// newReloadSettings returns the Settings of a controller, starting with its
+// configured MaxConcurrentReconciles and ResyncPeriod
+func newReloadSettings(options ConfigReloadOptions, maxConcurrentReconciles int, resyncPeriod time.Duration) *reload.Settings {
+	ceiling := options.MaxConcurrentReconciles
+	if ceiling <= 0 {
+		ceiling = defaultReloadCeiling
+	}
+	return reload.NewSettings(max(ceiling, maxConcurrentReconciles), maxConcurrentReconciles, resyncPeriod)
+}
+
+// configReconciler applies the KgameConfig to the running controllers
+type configReconciler struct {
+	client       client.Client
+	recorder     record.EventRecorder
+	logger       logr.Logger
+	name         string
+	controllers  map[Kind]reloadableController
+	featureGates *featureGates
+}
+
+// setupConfigReload watches the KgameConfig of the controller
+func setupConfigReload(mgr ctrl.Manager, opts *ControllerOptions, controllers map[Kind]reloadableController, gates *featureGates) error {
+	name := opts.ConfigReload.Name
+	if name == "" {
+		name = opts.ControllerName
+	}
+	r := &configReconciler{
+		client:       mgr.GetClient(),
+		recorder:     mgr.GetEventRecorderFor("kgame-config"),
+		logger:       mgr.GetLogger().WithValues("controller", "kgameconfig"),
+		name:         name,
+		controllers:  controllers,
+		featureGates: gates,
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("kgameconfig").
+		WithOptions(controller.Options{
+			// Every replica applies the configuration to its own controllers
+			NeedLeaderElection: ptr.To(false),
+		}).
+		For(&configv1alpha1.KgameConfig{}, builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+			return obj.GetName() == name
+		}))).
+		Complete(r)
+}
+
+// Reconcile applies the KgameConfig, or restores the startup configuration when
+// it does not exist
+func (r *configReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	logger := r.logger.WithValues("kgameconfig", req.Name)
+
+	config := &configv1alpha1.KgameConfig{}
+	if err := r.client.Get(ctx, req.NamespacedName, config); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+		logger.Info("kgameconfig not found, restoring the startup configuration")
+		r.apply(configv1alpha1.KgameConfigSpec{})
+		return reconcile.Result{}, nil
+	}
+
+	if err := validateConfigSpec(config.Spec); err != nil {
+		logger.Error(err, "invalid kgameconfig, keeping the previous configuration")
+		r.recorder.Event(config, corev1.EventTypeWarning, "InvalidConfiguration", err.Error())
+		return reconcile.Result{}, nil
+	}
+	r.apply(config.Spec)
+	logger.Info("kgameconfig applied", "generation", config.GetGeneration())
+	return reconcile.Result{}, nil
+}
+
+// apply applies the spec, restoring the startup settings of the controllers it
+// does not configure
+func (r *configReconciler) apply(spec configv1alpha1.KgameConfigSpec) {
+	if levels := logging.LevelsOf(r.logger); levels != nil {
+		if spec.LogLevel != nil {
+			levels.SetLevel(int(*spec.LogLevel))
+		}
+		subsystems := make(map[string]int, len(spec.LogLevels))
+		for subsystem, level := range spec.LogLevels {
+			subsystems[subsystem] = int(level)
+		}
+		levels.SetSubsystems(subsystems)
+	} else if spec.LogLevel != nil {
+		var level klog.Level
+		// The level is validated, so it is always a valid number
+		_ = level.Set(strconv.Itoa(int(*spec.LogLevel)))
+	}
+
+	configs := map[Kind]*configv1alpha1.ControllerConfig{
+		KindGatewayClass: spec.GatewayClass,
+		KindGateway:      spec.Gateway,
+		KindHTTPRoute:    spec.HTTPRoute,
+	}
+	for kind, ctr := range r.controllers {
+		maxConcurrentReconciles, resyncPeriod := ctr.maxConcurrentReconciles, ctr.resyncPeriod
+		if config := configs[kind]; config != nil {
+			if config.MaxConcurrentReconciles != nil {
+				maxConcurrentReconciles = int(*config.MaxConcurrentReconciles)
+			}
+			if config.ResyncPeriod != nil {
+				resyncPeriod = config.ResyncPeriod.Duration
+			}
+		}
+		ctr.settings.SetMaxConcurrentReconciles(maxConcurrentReconciles)
+		ctr.settings.SetResyncPeriod(resyncPeriod)
+	}
+
+	r.featureGates.set(spec.FeatureGates)
+}
+
+// validateConfigSpec returns all the invalid values of the spec, joined. The CRD
+// validates them too, but it may be installed without the validation
+func validateConfigSpec(spec configv1alpha1.KgameConfigSpec) error {
+	var errs []error
+	if spec.LogLevel != nil && *spec.LogLevel < 0 {
+		errs = append(errs, fmt.Errorf("logLevel must not be negative, got %d", *spec.LogLevel))
+	}
+	for _, subsystem := range slices.Sorted(maps.Keys(spec.LogLevels)) {
+		if level := spec.LogLevels[subsystem]; level < 0 {
+			errs = append(errs, fmt.Errorf("logLevels[%s] must not be negative, got %d", subsystem, level))
+		}
+	}
+	configs := []struct {
+		field  string
+		config *configv1alpha1.ControllerConfig
+	}{
+		{"gatewayClass", spec.GatewayClass},
+		{"gateway", spec.Gateway},
+		{"httpRoute", spec.HTTPRoute},
+	}
+	for _, c := range configs {
+		if c.config == nil {
+			continue
+		}
+		if c.config.MaxConcurrentReconciles != nil && *c.config.MaxConcurrentReconciles < 1 {
+			errs = append(errs, fmt.Errorf("%s.maxConcurrentReconciles must be at least 1, got %d", c.field, *c.config.MaxConcurrentReconciles))
+		}
+		if c.config.ResyncPeriod != nil && c.config.ResyncPeriod.Duration < 0 {
+			errs = append(errs, fmt.Errorf("%s.resyncPeriod must not be negative, got %s", c.field, c.config.ResyncPeriod.Duration))
+		}
+	}
+	return errors.Join(errs...)
+}
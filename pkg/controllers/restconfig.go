@@ -0,0 +1,114 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// RestConfigFromKubeconfig returns the rest.Config of the kubeconfig file on path,
+// using the given context. An empty path uses the default loading rules, like the
+// KUBECONFIG environment variable and ~/.kube/config, and an empty context uses
+// the kubeconfig current context
+func RestConfigFromKubeconfig(path, context string) (*rest.Config, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if path != "" {
+		rules.ExplicitPath = path
+	}
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: context}
+
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("error loading the kubeconfig: %w", err)
+	}
+	return config, nil
+}
+
+// ContentType is the encoding of the requests to the API server
+type ContentType string
+
+const (
+	// ContentTypeProtobuf negotiates protobuf for the built-in kinds, like
+	// Services, Secrets, Namespaces and EndpointSlices, and JSON for the CRD based
+	// kinds, like the Gateway API ones, which do not support protobuf
+	ContentTypeProtobuf ContentType = "Protobuf"
+	// ContentTypeJSON uses JSON for all the kinds
+	ContentTypeJSON ContentType = "JSON"
+)
+
+// ClientOptions configures the client-go throttling of the requests to the API
+// server. The client-go defaults, 5 QPS with a burst of 10, throttle the status
+// writes of large installations. The server side API Priority and Fairness still
+// applies, and is configured with FlowSchemas matching the kgame ServiceAccount
+type ClientOptions struct {
+	// QPS is the maximum number of queries per second to the API server. Zero
+	// keeps the RestConfig one, and a negative value disables the client side
+	// throttling, leaving it to the API Priority and Fairness
+	QPS float32
+	// Burst is the maximum burst of queries to the API server. Zero keeps the
+	// RestConfig one
+	Burst int
+	// UserAgent of the requests, shown on the API server audit logs. Zero keeps
+	// the RestConfig one
+	UserAgent string
+
+	// ContentType of the requests. Empty keeps the RestConfig one, and a
+	// RestConfig without content type already negotiates protobuf for the
+	// built-in kinds
+	ContentType ContentType
+}
+
+// apply returns a copy of config with the options set
+func (o ClientOptions) apply(config *rest.Config) *rest.Config {
+	config = rest.CopyConfig(config)
+	if o.QPS != 0 {
+		config.QPS = o.QPS
+	}
+	if o.Burst != 0 {
+		config.Burst = o.Burst
+	}
+	if o.UserAgent != "" {
+		config.UserAgent = o.UserAgent
+	}
+	switch o.ContentType {
+	case ContentTypeProtobuf:
+		// The controller-runtime clients negotiate protobuf for the kinds it
+		// supports when the content type is empty, and JSON for the others
+		config.ContentType = ""
+		config.AcceptContentTypes = ""
+	case ContentTypeJSON:
+		config.ContentType = runtime.ContentTypeJSON
+		config.AcceptContentTypes = runtime.ContentTypeJSON
+	}
+	return config
+}
+
+func (o ClientOptions) validate() error {
+	if o.Burst < 0 {
+		return fmt.Errorf("Burst must not be negative, got %d", o.Burst)
+	}
+	switch o.ContentType {
+	case "", ContentTypeProtobuf, ContentTypeJSON:
+	default:
+		return fmt.Errorf("unsupported ContentType %q", o.ContentType)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"github.com/rikatz/kgame/pkg/tunables"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// SecretCacheOptions configures the cache of the Secrets read by kgame, like the
+// listener certificates. Caching all the Secrets of the cluster is a memory and
+// security cost, so only the TLS data can be kept. The Secrets read by the
+// implementer through the manager client share the same cache
+type SecretCacheOptions struct {
+	// TLSOnly strips the data of the cached Secrets, except the tls.crt, tls.key
+	// and ca.crt keys
+	TLSOnly bool
+	// DropNonTLS drops from the cache the Secrets not of type kubernetes.io/tls,
+	// so they are never found. Implies TLSOnly
+	DropNonTLS bool
+
+	// Label and Field restrict the Secrets listed and watched by the cache, like
+	// tunables.TLSSecretFieldSelector. Unlike DropNonTLS, the Secrets filtered
+	// out are never sent by the API server
+	Label labels.Selector
+	Field fields.Selector
+}
+
+// WithSecretCache sets how the Secrets are cached
+func WithSecretCache(options SecretCacheOptions) Option {
+	return OptionFunc(func(opts *ControllerOptions) {
+		opts.SecretCache = options
+	})
+}
+
+// addByObject adds the Secret cache configuration to byObject, when it is set
+func (o SecretCacheOptions) addByObject(byObject map[client.Object]cache.ByObject) {
+	if !o.TLSOnly && !o.DropNonTLS && o.Label == nil && o.Field == nil {
+		return
+	}
+	secrets := cache.ByObject{
+		Label: o.Label,
+		Field: o.Field,
+	}
+	if o.TLSOnly || o.DropNonTLS {
+		secrets.Transform = tunables.TransformTLSSecret(o.DropNonTLS)
+	}
+	byObject[&corev1.Secret{}] = secrets
+}
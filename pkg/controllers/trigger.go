@@ -0,0 +1,79 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// Kind is a kind of resource reconciled by kgame
+type Kind string
+
+const (
+	KindGatewayClass Kind = "GatewayClass"
+	KindGateway      Kind = "Gateway"
+	KindHTTPRoute    Kind = "HTTPRoute"
+)
+
+// triggers are the sources of requested reconciliations of each controller
+type triggers map[Kind]chan event.GenericEvent
+
+func newTriggers() triggers {
+	return triggers{
+		KindGatewayClass: make(chan event.GenericEvent),
+		KindGateway:      make(chan event.GenericEvent),
+		KindHTTPRoute:    make(chan event.GenericEvent),
+	}
+}
+
+// TriggerReconcile requests the object of kind with the given name to be
+// reconciled again, like when something outside of Kubernetes changed and the
+// dataplane lost its configuration. A triggered Gateway is programmed again,
+// even if its inputs did not change, as its cached configuration and inputs are
+// dropped. The call blocks until the request is queued, which only happens once
+// the controller is started, or ctx is done
+func (k *Controller) TriggerReconcile(ctx context.Context, kind Kind, name types.NamespacedName) error {
+	trigger, ok := k.triggers[kind]
+	if !ok {
+		return fmt.Errorf("unsupported or disabled kind %q", kind)
+	}
+
+	var obj client.Object
+	switch kind {
+	case KindGatewayClass:
+		obj = &gatewayv1.GatewayClass{}
+	case KindGateway:
+		obj = &gatewayv1.Gateway{}
+	case KindHTTPRoute:
+		obj = &gatewayv1.HTTPRoute{}
+	}
+	obj.SetName(name.Name)
+	obj.SetNamespace(name.Namespace)
+
+	select {
+	case trigger <- event.GenericEvent{Object: obj}:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("error triggering the reconciliation of %s %s: %w", kind, name, ctx.Err())
+	}
+}
@@ -0,0 +1,43 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// WatchAdditional registers an additional watch on the controller of kind, like
+// to requeue the Gateways when a vendor CRD referenced by them changes. The
+// handler maps the events of obj to the requests of the controller, like
+// handler.EnqueueRequestsFromMapFunc. The object kind must be part of the
+// controller scheme.
+// Watches should be registered before the controller is started
+func (k *Controller) WatchAdditional(kind Kind, obj client.Object, eventHandler handler.EventHandler, predicates ...predicate.Predicate) error {
+	ctrl, ok := k.controllers[kind]
+	if !ok {
+		return fmt.Errorf("unsupported or disabled kind %q", kind)
+	}
+	if err := ctrl.Watch(source.Kind(k.mgr.GetCache(), obj, eventHandler, predicates...)); err != nil {
+		return fmt.Errorf("error adding watch to the %s controller: %w", kind, err)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var watchErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "kgame_watch_errors_total",
+	Help: "Number of list and watch failures of the kgame informers, by type",
+}, []string{"type"})
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(watchErrors)
+}
+
+// WatchErrorFunc is called when an informer fails to list or watch its type,
+// like on RBAC gaps or deleted CRDs. The type is the informer type description,
+// like "*v1.Gateway"
+type WatchErrorFunc func(ctx context.Context, typ string, err error)
+
+// WatchErrors tracks the list and watch failures of the informers. The failures
+// are still logged by the client-go handler, counted on the
+// kgame_watch_errors_total metric and passed to the WatchErrorFunc. The same
+// WatchErrors must be passed to CacheOptions and SetupAllWithManager, see
+// WithWatchErrors
+type WatchErrors struct {
+	handler WatchErrorFunc
+	window  time.Duration
+
+	mu       sync.Mutex
+	failures map[string]time.Time
+}
+
+// NewWatchErrors returns the WatchErrors calling handler on each failure, which
+// may be empty. When window is positive, the kgame-watch-errors readyz check
+// reports the replica not ready while any informer failed within the window
+func NewWatchErrors(handler WatchErrorFunc, window time.Duration) *WatchErrors {
+	return &WatchErrors{
+		handler:  handler,
+		window:   window,
+		failures: make(map[string]time.Time),
+	}
+}
+
+// handle is the informers WatchErrorHandler
+func (w *WatchErrors) handle(ctx context.Context, r *toolscache.Reflector, err error) {
+	toolscache.DefaultWatchErrorHandler(ctx, r, err)
+	// Closed or expired watches are restarted by the informer, and are not
+	// failures
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || apierrors.IsResourceExpired(err) || apierrors.IsGone(err) {
+		return
+	}
+
+	typ := r.TypeDescription()
+	watchErrors.WithLabelValues(typ).Inc()
+	w.mu.Lock()
+	w.failures[typ] = time.Now()
+	w.mu.Unlock()
+	if w.handler != nil {
+		w.handler(ctx, typ, err)
+	}
+}
+
+// checker is not ready while any informer failed within the window
+func (w *WatchErrors) checker() healthz.Checker {
+	return func(*http.Request) error {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		var failing []string
+		for typ, last := range w.failures {
+			if time.Since(last) < w.window {
+				failing = append(failing, typ)
+			}
+		}
+		if len(failing) == 0 {
+			return nil
+		}
+		sort.Strings(failing)
+		return fmt.Errorf("informers failing to list or watch: %s", strings.Join(failing, ", "))
+	}
+}
+
+// WithWatchErrors sets the tracking of the informers list and watch failures
+func WithWatchErrors(w *WatchErrors) Option {
+	return OptionFunc(func(opts *ControllerOptions) {
+		opts.WatchErrors = w
+	})
+}
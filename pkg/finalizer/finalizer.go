@@ -0,0 +1,180 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The package finalizer manages a finalizer on any object, calling the finalizer
+// hooks with the same semantics of the kgame controllers, so implementers can
+// reuse it on their own resources.
+package finalizer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/rikatz/kgame/pkg/hooks"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// ParametersFunc returns the parameters passed to the finalizer hooks on the
+// hooks.FinalizerContext. It is only called when a hook is going to be called
+type ParametersFunc func(ctx context.Context, obj client.Object) (any, error)
+
+// Options configures a Manager
+type Options struct {
+	// Name of the finalizer
+	Name string
+	// AddFinalizerFunc is called before the finalizer is added
+	AddFinalizerFunc hooks.AddFinalizerFunc
+	// RemoveFinalizerFunc is called before the finalizer is removed
+	RemoveFinalizerFunc hooks.RemoveFinalizerFunc
+	// ParametersFunc resolves the parameters of the hooks. If empty the hooks
+	// receive no parameters
+	ParametersFunc ParametersFunc
+	// Runner calls the hooks. Defaults to a Runner with hooks.DefaultTimeout
+	// and no event recorder
+	Runner hooks.Runner
+}
+
+// Manager adds and removes a finalizer. The hooks are called once per change, and
+// an error on them, or on the patch, leaves the object finalizers unchanged so the
+// change can be retried on the next reconciliation
+type Manager struct {
+	client  client.Client
+	options Options
+}
+
+// NewManager returns a Manager of the options finalizer, using c to patch the
+// objects
+func NewManager(c client.Client, options Options) *Manager {
+	return &Manager{
+		client:  c,
+		options: options,
+	}
+}
+
+// Name returns the name of the managed finalizer
+func (m *Manager) Name() string {
+	return m.options.Name
+}
+
+// Contains returns true if obj has the finalizer
+func (m *Manager) Contains(obj client.Object) bool {
+	return m.options.Name != "" && controllerutil.ContainsFinalizer(obj, m.options.Name)
+}
+
+// Add adds the finalizer to obj, if missing, after calling the AddFinalizerFunc.
+// It returns true if the finalizer was added. Errors of the hook are returned
+// wrapped, so they can still be checked with hooks.IsTerminal and hooks.IsRequeue
+func (m *Manager) Add(ctx context.Context, logger logr.Logger, obj client.Object) (bool, error) {
+	if m.options.Name == "" || controllerutil.ContainsFinalizer(obj, m.options.Name) {
+		return false, nil
+	}
+
+	if m.options.AddFinalizerFunc != nil {
+		fctx, err := m.finalizerContext(ctx, logger, obj, hooks.FinalizerAdd, hooks.ReasonAdoption)
+		if err != nil {
+			return false, err
+		}
+		if err := hooks.Run(ctx, m.options.Runner, obj, "AddFinalizerFunc", func(ctx context.Context, obj client.Object) error {
+			fctx.Object = obj
+			return m.options.AddFinalizerFunc(ctx, fctx)
+		}); err != nil {
+			return false, fmt.Errorf("error executing pre-finalizer add function: %w", err)
+		}
+	}
+
+	logger.Info("adding finalizer", "finalizer", m.options.Name)
+	return true, m.patch(ctx, obj, func() bool {
+		return controllerutil.AddFinalizer(obj, m.options.Name)
+	})
+}
+
+// Remove removes the finalizer from obj, if present, after calling the
+// RemoveFinalizerFunc. It returns true if the finalizer was removed. Errors of the
+// hook are returned wrapped, as on Add
+func (m *Manager) Remove(ctx context.Context, logger logr.Logger, obj client.Object) (bool, error) {
+	if !m.Contains(obj) {
+		return false, nil
+	}
+
+	if m.options.RemoveFinalizerFunc != nil {
+		fctx, err := m.finalizerContext(ctx, logger, obj, hooks.FinalizerRemove, hooks.ReasonDeletion)
+		if err != nil {
+			return false, err
+		}
+		if err := hooks.Run(ctx, m.options.Runner, obj, "RemoveFinalizerFunc", func(ctx context.Context, obj client.Object) error {
+			fctx.Object = obj
+			return m.options.RemoveFinalizerFunc(ctx, fctx)
+		}); err != nil {
+			return false, fmt.Errorf("error executing pre-finalizer removal function: %w", err)
+		}
+	}
+
+	logger.Info("removing finalizer", "finalizer", m.options.Name)
+	return true, m.patch(ctx, obj, func() bool {
+		return controllerutil.RemoveFinalizer(obj, m.options.Name)
+	})
+}
+
+func (m *Manager) finalizerContext(ctx context.Context, logger logr.Logger, obj client.Object, operation hooks.FinalizerOperation, reason hooks.FinalizerReason) (hooks.FinalizerContext, error) {
+	fctx := hooks.FinalizerContext{
+		Object:    obj,
+		Operation: operation,
+		Reason:    reason,
+		Logger:    logger,
+	}
+	if m.options.ParametersFunc != nil {
+		params, err := m.options.ParametersFunc(ctx, obj)
+		if err != nil {
+			return fctx, fmt.Errorf("error resolving the finalizer hook parameters: %w", err)
+		}
+		fctx.Parameters = params
+	}
+	return fctx, nil
+}
+
+// patch applies mutate to obj and patches it, guarded by the object resource
+// version. On conflicts obj is fetched again and mutated, so concurrent changes
+// to the finalizers list are not lost
+func (m *Manager) patch(ctx context.Context, obj client.Object, mutate func() bool) error {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		original, ok := obj.DeepCopyObject().(client.Object)
+		if !ok {
+			return fmt.Errorf("unable to copy %T", obj)
+		}
+		if !mutate() {
+			return nil
+		}
+		err := m.client.Patch(ctx, obj, client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{}))
+		if apierrors.IsConflict(err) {
+			if getErr := m.client.Get(ctx, client.ObjectKeyFromObject(obj), obj); getErr != nil {
+				return getErr
+			}
+		}
+		return err
+	})
+	if apierrors.IsNotFound(err) {
+		// The object is gone, so there is no finalizer to manage
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error patching the finalizers of %s: %w", client.ObjectKeyFromObject(obj), err)
+	}
+	return nil
+}
@@ -0,0 +1,540 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The package flags binds the kgame ControllerOptions to command line flags, so
+// the binaries built on kgame expose a consistent CLI. Only the flags set on the
+// command line are applied, so they override the options set with code:
+//
+//	kgameFlags := flags.New()
+//	kgameFlags.AddGoFlags(flag.CommandLine)
+//	flag.Parse()
+//	ctr, err := controllers.NewController(controllers.WithGatewayHooks(h), kgameFlags.Option())
+//
+// The --kubeconfig flag is registered on flag.CommandLine by controller-runtime.
+package flags
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rikatz/kgame/pkg/controllers"
+	kgamelogging "github.com/rikatz/kgame/pkg/logging"
+	"github.com/rikatz/kgame/pkg/provisioner"
+	"github.com/rikatz/kgame/pkg/sharding"
+	"github.com/rikatz/kgame/pkg/writer"
+	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/klog/v2"
+)
+
+// Flags are the command line flags of the ControllerOptions
+type Flags struct {
+	flags []*kgameFlag
+}
+
+// kgameFlag is a flag.Value, and a pflag.Value, applied to the ControllerOptions
+// only when it is set
+type kgameFlag struct {
+	name   string
+	usage  string
+	typ    string
+	raw    string
+	set    bool
+	parse  func(value string) error
+	applyF func(opts *controllers.ControllerOptions)
+}
+
+func (f *kgameFlag) String() string { return f.raw }
+
+func (f *kgameFlag) Type() string { return f.typ }
+
+// IsBoolFlag allows the boolean flags without a value, like --leader-elect
+func (f *kgameFlag) IsBoolFlag() bool { return f.typ == "bool" }
+
+func (f *kgameFlag) Set(value string) error {
+	if err := f.parse(value); err != nil {
+		return err
+	}
+	f.raw, f.set = value, true
+	return nil
+}
+
+// newFlag returns a flag parsing its value with parse, and applying it with apply
+func newFlag[T any](name, typ, usage string, parse func(string) (T, error), apply func(opts *controllers.ControllerOptions, value T)) *kgameFlag {
+	var parsed T
+	return &kgameFlag{
+		name:  name,
+		usage: usage,
+		typ:   typ,
+		parse: func(value string) error {
+			v, err := parse(value)
+			if err != nil {
+				return err
+			}
+			parsed = v
+			return nil
+		},
+		applyF: func(opts *controllers.ControllerOptions) {
+			apply(opts, parsed)
+		},
+	}
+}
+
+func stringFlag(name, usage string, apply func(opts *controllers.ControllerOptions, value string)) *kgameFlag {
+	return newFlag(name, "string", usage, func(value string) (string, error) { return value, nil }, apply)
+}
+
+func boolFlag(name, usage string, apply func(opts *controllers.ControllerOptions, value bool)) *kgameFlag {
+	return newFlag(name, "bool", usage, strconv.ParseBool, apply)
+}
+
+func intFlag(name, usage string, apply func(opts *controllers.ControllerOptions, value int)) *kgameFlag {
+	return newFlag(name, "int", usage, strconv.Atoi, apply)
+}
+
+func durationFlag(name, usage string, apply func(opts *controllers.ControllerOptions, value time.Duration)) *kgameFlag {
+	return newFlag(name, "duration", usage, time.ParseDuration, apply)
+}
+
+func listFlag(name, usage string, apply func(opts *controllers.ControllerOptions, value []string)) *kgameFlag {
+	return newFlag(name, "strings", usage, parseList, apply)
+}
+
+func selectorFlag(name, usage string, apply func(opts *controllers.ControllerOptions, value labels.Selector)) *kgameFlag {
+	return newFlag(name, "selector", usage, func(value string) (labels.Selector, error) { return labels.Parse(value) }, apply)
+}
+
+func fieldSelectorFlag(name, usage string, apply func(opts *controllers.ControllerOptions, value fields.Selector)) *kgameFlag {
+	return newFlag(name, "selector", usage, fields.ParseSelector, apply)
+}
+
+// parseFloat32 parses a float32 value
+func parseFloat32(value string) (float32, error) {
+	f, err := strconv.ParseFloat(value, 32)
+	return float32(f), err
+}
+
+// parseList parses a comma separated list, ignoring the empty items
+func parseList(value string) ([]string, error) {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// parseFeatureGates parses a comma separated list of name=bool
+func parseFeatureGates(value string) (map[string]bool, error) {
+	items, _ := parseList(value)
+	gates := make(map[string]bool, len(items))
+	for _, item := range items {
+		name, enabled, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid feature gate %q, expected name=true|false", item)
+		}
+		b, err := strconv.ParseBool(enabled)
+		if err != nil {
+			return nil, fmt.Errorf("invalid feature gate %q: %w", item, err)
+		}
+		gates[strings.TrimSpace(name)] = b
+	}
+	return gates, nil
+}
+
+// parseLevels parses a comma separated list of subsystem=level
+func parseLevels(value string) (map[string]int, error) {
+	items, _ := parseList(value)
+	levels := make(map[string]int, len(items))
+	for _, item := range items {
+		subsystem, level, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid log level %q, expected subsystem=level", item)
+		}
+		v, err := strconv.Atoi(level)
+		if err != nil {
+			return nil, fmt.Errorf("invalid log level %q: %w", item, err)
+		}
+		levels[strings.TrimSpace(subsystem)] = v
+	}
+	return levels, nil
+}
+
+// parseLogFormat parses a log format, text or json
+func parseLogFormat(value string) (kgamelogging.Format, error) {
+	switch format := kgamelogging.Format(value); format {
+	case kgamelogging.FormatText, kgamelogging.FormatJSON:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unsupported log format %q, supported formats are %q and %q", value, kgamelogging.FormatText, kgamelogging.FormatJSON)
+	}
+}
+
+// parseMapping parses a comma separated list of key=value
+func parseMapping(value string) (map[string]string, error) {
+	items, _ := parseList(value)
+	mapping := make(map[string]string, len(items))
+	for _, item := range items {
+		key, mapped, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid mapping %q, expected key=value", item)
+		}
+		mapping[strings.TrimSpace(key)] = strings.TrimSpace(mapped)
+	}
+	return mapping, nil
+}
+
+// logging returns the Logging options, creating them when empty
+func logging(opts *controllers.ControllerOptions) *kgamelogging.Options {
+	if opts.Logging == nil {
+		opts.Logging = &kgamelogging.Options{}
+	}
+	return opts.Logging
+}
+
+// New returns the flags of the ControllerOptions
+func New() *Flags {
+	return &Flags{flags: []*kgameFlag{
+		stringFlag("controller-class", "GatewayClass controllerName managed by the controller", func(opts *controllers.ControllerOptions, value string) {
+			opts.ControllerClass = value
+		}),
+		listFlag("controller-classes", "Comma separated additional GatewayClass controllerNames managed by the controller", func(opts *controllers.ControllerOptions, value []string) {
+			opts.ControllerClasses = value
+		}),
+		stringFlag("controller-name", "Name of the controller, used on its logs, events and leases", func(opts *controllers.ControllerOptions, value string) {
+			opts.ControllerName = value
+		}),
+		newFlag("log-format", "string", "Format of the logs, text or json", parseLogFormat, func(opts *controllers.ControllerOptions, value kgamelogging.Format) {
+			logging(opts).Format = value
+		}),
+		intFlag("log-level", "Verbosity of the logs with --log-format", func(opts *controllers.ControllerOptions, value int) {
+			logging(opts).Level = value
+		}),
+		newFlag("log-subsystem-levels", "strings", "Comma separated verbosity of the logging subsystems with --log-format, like gateway=4,cache-transform=0", parseLevels, func(opts *controllers.ControllerOptions, value map[string]int) {
+			logging(opts).Subsystems = value
+		}),
+		listFlag("namespaces", "Comma separated namespaces watched by the controller. Defaults to all the namespaces", func(opts *controllers.ControllerOptions, value []string) {
+			opts.Namespaces = value
+		}),
+		selectorFlag("gatewayclass-selector", "Label selector of the cached GatewayClasses", func(opts *controllers.ControllerOptions, value labels.Selector) {
+			opts.Selectors.GatewayClass = value
+		}),
+		selectorFlag("gateway-selector", "Label selector of the cached Gateways", func(opts *controllers.ControllerOptions, value labels.Selector) {
+			opts.Selectors.Gateway = value
+		}),
+		selectorFlag("httproute-selector", "Label selector of the cached HTTPRoutes", func(opts *controllers.ControllerOptions, value labels.Selector) {
+			opts.Selectors.HTTPRoute = value
+		}),
+		durationFlag("sync-period", "Period of the full resync of the informer caches", func(opts *controllers.ControllerOptions, value time.Duration) {
+			opts.SyncPeriod = value
+		}),
+		listFlag("disable-controllers", "Comma separated kinds whose controller is not started, like Gateway", func(opts *controllers.ControllerOptions, value []string) {
+			opts.DisabledControllers = nil
+			for _, kind := range value {
+				opts.DisabledControllers = append(opts.DisabledControllers, controllers.Kind(kind))
+			}
+		}),
+		newFlag("feature-gates", "strings", "Comma separated feature gates, like Feature=true,Other=false", parseFeatureGates, func(opts *controllers.ControllerOptions, value map[string]bool) {
+			opts.FeatureGates = value
+		}),
+		intFlag("gatewayclass-max-concurrent-reconciles", "Number of GatewayClasses reconciled in parallel", func(opts *controllers.ControllerOptions, value int) {
+			opts.GatewayClassOptions.MaxConcurrentReconciles = value
+		}),
+		intFlag("gateway-max-concurrent-reconciles", "Number of Gateways reconciled in parallel", func(opts *controllers.ControllerOptions, value int) {
+			opts.GatewayOptions.MaxConcurrentReconciles = value
+		}),
+		intFlag("httproute-max-concurrent-reconciles", "Number of HTTPRoutes reconciled in parallel", func(opts *controllers.ControllerOptions, value int) {
+			opts.HTTPRouteOptions.MaxConcurrentReconciles = value
+		}),
+		durationFlag("gatewayclass-resync-period", "Period to reconcile each GatewayClass again. Disabled when zero", func(opts *controllers.ControllerOptions, value time.Duration) {
+			opts.GatewayClassOptions.ResyncPeriod = value
+		}),
+		durationFlag("gateway-resync-period", "Period to reconcile each Gateway again. Disabled when zero", func(opts *controllers.ControllerOptions, value time.Duration) {
+			opts.GatewayOptions.ResyncPeriod = value
+		}),
+		durationFlag("httproute-resync-period", "Period to reconcile each HTTPRoute again. Disabled when zero", func(opts *controllers.ControllerOptions, value time.Duration) {
+			opts.HTTPRouteOptions.ResyncPeriod = value
+		}),
+		durationFlag("gatewayclass-debounce", "Window collapsing the GatewayClass events into one reconciliation. Disabled when zero", func(opts *controllers.ControllerOptions, value time.Duration) {
+			opts.GatewayClassOptions.RateLimit.Debounce = value
+		}),
+		durationFlag("gateway-debounce", "Window collapsing the Gateway events into one reconciliation. Disabled when zero", func(opts *controllers.ControllerOptions, value time.Duration) {
+			opts.GatewayOptions.RateLimit.Debounce = value
+		}),
+		durationFlag("httproute-debounce", "Window collapsing the HTTPRoute events into one reconciliation. Disabled when zero", func(opts *controllers.ControllerOptions, value time.Duration) {
+			opts.HTTPRouteOptions.RateLimit.Debounce = value
+		}),
+		durationFlag("shutdown-timeout", "Maximum time to drain the in-flight reconciliations when stopping", func(opts *controllers.ControllerOptions, value time.Duration) {
+			opts.ShutdownTimeout = value
+		}),
+		newFlag("kube-api-qps", "float", "Maximum queries per second to the API server. Negative disables the client side throttling", parseFloat32, func(opts *controllers.ControllerOptions, value float32) {
+			opts.Client.QPS = value
+		}),
+		intFlag("kube-api-burst", "Maximum burst of queries to the API server", func(opts *controllers.ControllerOptions, value int) {
+			opts.Client.Burst = value
+		}),
+		stringFlag("kube-api-content-type", "Encoding of the requests to the API server, Protobuf or JSON", func(opts *controllers.ControllerOptions, value string) {
+			opts.Client.ContentType = controllers.ContentType(value)
+		}),
+		intFlag("shards", "Number of active replicas partitioning the reconciliations. Disabled when lower than 2", func(opts *controllers.ControllerOptions, value int) {
+			opts.Sharding.Replicas = value
+		}),
+		intFlag("shard-ordinal", "Ordinal of this replica, from 0 to the number of shards minus 1", func(opts *controllers.ControllerOptions, value int) {
+			opts.Sharding.Ordinal = value
+		}),
+		stringFlag("shard-key", "What the reconciliations are partitioned by, Namespace or Name", func(opts *controllers.ControllerOptions, value string) {
+			opts.Sharding.Key = sharding.Key(value)
+		}),
+		boolFlag("leader-elect", "Enable the leader election", func(opts *controllers.ControllerOptions, value bool) {
+			opts.LeaderElection.Enabled = value
+		}),
+		stringFlag("leader-election-namespace", "Namespace of the leader election Lease", func(opts *controllers.ControllerOptions, value string) {
+			opts.LeaderElection.Namespace = value
+		}),
+		stringFlag("leader-election-id", "Name of the leader election Lease", func(opts *controllers.ControllerOptions, value string) {
+			opts.LeaderElection.Name = value
+		}),
+		durationFlag("leader-election-lease-duration", "Duration the non leader replicas wait to acquire the Lease", func(opts *controllers.ControllerOptions, value time.Duration) {
+			opts.LeaderElection.LeaseDuration = value
+		}),
+		durationFlag("leader-election-renew-deadline", "Duration the leader retries to renew the Lease", func(opts *controllers.ControllerOptions, value time.Duration) {
+			opts.LeaderElection.RenewDeadline = value
+		}),
+		durationFlag("leader-election-retry-period", "Duration between the leader election attempts", func(opts *controllers.ControllerOptions, value time.Duration) {
+			opts.LeaderElection.RetryPeriod = value
+		}),
+		boolFlag("leader-election-release-on-cancel", "Release the Lease when the controller stops", func(opts *controllers.ControllerOptions, value bool) {
+			opts.LeaderElection.ReleaseOnCancel = value
+		}),
+		stringFlag("metrics-bind-address", `Address of the metrics server. "0" disables it`, func(opts *controllers.ControllerOptions, value string) {
+			opts.Metrics.BindAddress = value
+		}),
+		boolFlag("metrics-secure", "Serve the metrics over HTTPS", func(opts *controllers.ControllerOptions, value bool) {
+			opts.Metrics.SecureServing = value
+		}),
+		durationFlag("metrics-cache-interval", "Interval of the measure of the cached objects. Disabled when zero", func(opts *controllers.ControllerOptions, value time.Duration) {
+			opts.Metrics.CacheInterval = value
+		}),
+		stringFlag("metrics-cert-dir", "Directory with the tls.crt and tls.key of the metrics server", func(opts *controllers.ControllerOptions, value string) {
+			opts.Metrics.CertDir = value
+		}),
+		stringFlag("health-probe-bind-address", "Address of the health and readiness probes server. Disabled when empty", func(opts *controllers.ControllerOptions, value string) {
+			opts.Probes.BindAddress = value
+		}),
+		durationFlag("watch-errors-readiness-window", "Report the replica not ready while any informer failed to list or watch within the window. Disabled when zero", func(opts *controllers.ControllerOptions, value time.Duration) {
+			opts.WatchErrors = controllers.NewWatchErrors(nil, value)
+		}),
+		boolFlag("probes-require-leader", "Report the replicas that are not the leader as not ready", func(opts *controllers.ControllerOptions, value bool) {
+			opts.Probes.RequireLeader = value
+		}),
+		stringFlag("pprof-bind-address", "Address of the pprof endpoints. Disabled when empty", func(opts *controllers.ControllerOptions, value string) {
+			opts.PprofBindAddress = value
+		}),
+		boolFlag("enable-webhooks", "Start the admission webhooks server", func(opts *controllers.ControllerOptions, value bool) {
+			opts.Webhooks.Enabled = value
+		}),
+		intFlag("webhook-port", "Port of the admission webhooks server", func(opts *controllers.ControllerOptions, value int) {
+			opts.Webhooks.Port = value
+		}),
+		stringFlag("webhook-cert-dir", "Directory with the tls.crt and tls.key of the admission webhooks server", func(opts *controllers.ControllerOptions, value string) {
+			opts.Webhooks.CertDir = value
+		}),
+		stringFlag("status-report-name", "Name of the ConfigMap reporting the controller health. Disabled when empty", func(opts *controllers.ControllerOptions, value string) {
+			opts.StatusReport.Name = value
+		}),
+		stringFlag("status-report-namespace", "Namespace of the ConfigMap reporting the controller health", func(opts *controllers.ControllerOptions, value string) {
+			opts.StatusReport.Namespace = value
+		}),
+		boolFlag("enable-config-reload", "Watch the KgameConfig, applying its settings at runtime", func(opts *controllers.ControllerOptions, value bool) {
+			opts.ConfigReload.Enabled = value
+		}),
+		stringFlag("config-reload-name", "Name of the KgameConfig. Defaults to the controller name", func(opts *controllers.ControllerOptions, value string) {
+			opts.ConfigReload.Name = value
+		}),
+		boolFlag("gateway-class-api-reader-fallback", "Read the GatewayClasses not found on the cache from the API server", func(opts *controllers.ControllerOptions, value bool) {
+			opts.GatewayOptions.ClassAPIReaderFallback = value
+		}),
+		boolFlag("gateway-generation-changed", "Only reconcile the Gateway updates changing their generation", func(opts *controllers.ControllerOptions, value bool) {
+			opts.GatewayOptions.Filter.GenerationChanged = value
+		}),
+		boolFlag("gateway-ignore-status-updates", "Skip the Gateway updates only changing their status", func(opts *controllers.ControllerOptions, value bool) {
+			opts.GatewayOptions.Filter.IgnoreStatusUpdates = value
+		}),
+		boolFlag("httproute-generation-changed", "Only reconcile the HTTPRoute updates changing their generation", func(opts *controllers.ControllerOptions, value bool) {
+			opts.HTTPRouteOptions.Filter.GenerationChanged = value
+		}),
+		boolFlag("httproute-ignore-status-updates", "Skip the HTTPRoute updates only changing their status", func(opts *controllers.ControllerOptions, value bool) {
+			opts.HTTPRouteOptions.Filter.IgnoreStatusUpdates = value
+		}),
+		boolFlag("gateway-input-cache", "Only program the Gateways whose resolved inputs changed since they were last programmed", func(opts *controllers.ControllerOptions, value bool) {
+			opts.GatewayOptions.InputCache = value
+		}),
+		intFlag("gateway-program-parallelism", "Number of Gateways programmed in parallel on background lanes. Disabled when zero", func(opts *controllers.ControllerOptions, value int) {
+			opts.GatewayOptions.ProgramParallelism = value
+		}),
+		stringFlag("provisioner-image", "Image of the dataplane provisioned for each accepted Gateway. Disabled when empty", func(opts *controllers.ControllerOptions, value string) {
+			if value == "" {
+				opts.Provisioner = nil
+				return
+			}
+			if opts.Provisioner == nil {
+				opts.Provisioner = &provisioner.Options{}
+			}
+			opts.Provisioner.Image = value
+		}),
+		stringFlag("provisioner-mode", "How the provisioned dataplane is deployed, Deployment or DaemonSet", func(opts *controllers.ControllerOptions, value string) {
+			if opts.Provisioner == nil {
+				opts.Provisioner = &provisioner.Options{}
+			}
+			opts.Provisioner.Mode = provisioner.Mode(value)
+		}),
+		stringFlag("provisioner-service-type", "Type of the provisioned Services, LoadBalancer, NodePort or ClusterIP", func(opts *controllers.ControllerOptions, value string) {
+			if opts.Provisioner == nil {
+				opts.Provisioner = &provisioner.Options{}
+			}
+			opts.Provisioner.ServiceType = corev1.ServiceType(value)
+		}),
+		boolFlag("provisioner-host-network", "Run the DaemonSet dataplane pods on the node network", func(opts *controllers.ControllerOptions, value bool) {
+			if opts.Provisioner == nil {
+				opts.Provisioner = &provisioner.Options{}
+			}
+			opts.Provisioner.HostNetwork = value
+		}),
+		boolFlag("provisioner-class-image", "Provision the dataplane image set on the image of the GatewayClass parameters", func(opts *controllers.ControllerOptions, value bool) {
+			if opts.Provisioner == nil {
+				opts.Provisioner = &provisioner.Options{}
+			}
+			if value {
+				opts.Provisioner.ClassImageFunc = provisioner.ParametersImage
+			}
+		}),
+		intFlag("provisioner-max-unavailable-gateways", "Maximum number of the Gateways of a class rolling out a new dataplane image at once. Zero rolls all of them at once", func(opts *controllers.ControllerOptions, value int) {
+			if opts.Provisioner == nil {
+				opts.Provisioner = &provisioner.Options{}
+			}
+			opts.Provisioner.MaxUnavailableGateways = value
+		}),
+		stringFlag("provisioner-canary-gateways", "Number, or percentage, of the newest Gateways of a class rolling out a new dataplane image first, unless some have the kgame.io/canary label", func(opts *controllers.ControllerOptions, value string) {
+			if opts.Provisioner == nil {
+				opts.Provisioner = &provisioner.Options{}
+			}
+			if opts.Provisioner.Canary == nil {
+				opts.Provisioner.Canary = &provisioner.CanaryOptions{}
+			}
+			gateways := intstr.Parse(value)
+			opts.Provisioner.Canary.Gateways = &gateways
+		}),
+		durationFlag("provisioner-canary-soak-period", "How long the canary Gateways must stay ready on a new dataplane image before the other Gateways of the class roll it out", func(opts *controllers.ControllerOptions, value time.Duration) {
+			if opts.Provisioner == nil {
+				opts.Provisioner = &provisioner.Options{}
+			}
+			if opts.Provisioner.Canary == nil {
+				opts.Provisioner.Canary = &provisioner.CanaryOptions{}
+			}
+			opts.Provisioner.Canary.SoakPeriod = value
+		}),
+		newFlag("provisioner-service-annotations", "strings", "Comma separated mapping of the Gateway infrastructure parameters to annotations of the provisioned Services, like internal=networking.gke.io/load-balancer-type", parseMapping, func(opts *controllers.ControllerOptions, value map[string]string) {
+			if opts.Provisioner == nil {
+				opts.Provisioner = &provisioner.Options{}
+			}
+			opts.Provisioner.ServiceAnnotationsFunc = provisioner.ParametersAnnotations(value)
+		}),
+		durationFlag("provisioner-sweep-interval", "Interval to remove the orphaned provisioned resources. Disabled when zero", func(opts *controllers.ControllerOptions, value time.Duration) {
+			if opts.Provisioner == nil {
+				opts.Provisioner = &provisioner.Options{}
+			}
+			opts.Provisioner.SweepInterval = value
+		}),
+		stringFlag("provisioner-drift-policy", "How the out of band changes of the provisioned resources are handled, Revert or Report", func(opts *controllers.ControllerOptions, value string) {
+			if opts.Provisioner == nil {
+				opts.Provisioner = &provisioner.Options{}
+			}
+			opts.Provisioner.DriftPolicy = provisioner.DriftPolicy(value)
+		}),
+		boolFlag("gateway-watch-backends", "Reconcile the Gateways again when the backend Services or EndpointSlices of their routes change", func(opts *controllers.ControllerOptions, value bool) {
+			opts.GatewayOptions.WatchBackends = value
+		}),
+		boolFlag("httproute-watch-backends", "Reconcile the HTTPRoutes again when their backend Services or EndpointSlices change", func(opts *controllers.ControllerOptions, value bool) {
+			opts.HTTPRouteOptions.WatchBackends = value
+		}),
+		boolFlag("drop-unmanaged-gateways", "Drop from the cache the Gateways of the GatewayClasses not managed by kgame", func(opts *controllers.ControllerOptions, value bool) {
+			opts.DropUnmanagedGateways = value
+		}),
+		boolFlag("route-cache-drop-non-gateway-parents", "Drop from the cache the routes without any Gateway parentRef", func(opts *controllers.ControllerOptions, value bool) {
+			opts.RouteCache.DropNonGatewayParents = value
+		}),
+		boolFlag("secret-cache-tls-only", "Strip the cached Secrets data, except the TLS certificate, key and CA", func(opts *controllers.ControllerOptions, value bool) {
+			opts.SecretCache.TLSOnly = value
+		}),
+		boolFlag("secret-cache-drop-non-tls", "Drop from the cache the Secrets not of type kubernetes.io/tls", func(opts *controllers.ControllerOptions, value bool) {
+			opts.SecretCache.DropNonTLS = value
+		}),
+		selectorFlag("secret-selector", "Label selector of the cached Secrets", func(opts *controllers.ControllerOptions, value labels.Selector) {
+			opts.SecretCache.Label = value
+		}),
+		fieldSelectorFlag("secret-field-selector", "Field selector of the cached Secrets, like type=kubernetes.io/tls", func(opts *controllers.ControllerOptions, value fields.Selector) {
+			opts.SecretCache.Field = value
+		}),
+		stringFlag("field-manager", "Field manager of the kgame writes, shown on the managedFields", func(opts *controllers.ControllerOptions, value string) {
+			opts.Writes.FieldManager = value
+		}),
+		stringFlag("write-strategy", "Strategy of the status writes, one of MergePatch, Update or ServerSideApply", func(opts *controllers.ControllerOptions, value string) {
+			opts.Writes.Strategy = writer.Strategy(value)
+		}),
+	}}
+}
+
+// AddGoFlags adds the flags, and the klog ones, to fs. The klog flags can only
+// be added once per program
+func (f *Flags) AddGoFlags(fs *flag.FlagSet) {
+	for _, kf := range f.flags {
+		fs.Var(kf, kf.name, kf.usage)
+	}
+	klog.InitFlags(fs)
+}
+
+// AddPFlags adds the flags, and the klog ones, to fs. The klog flags can only be
+// added once per program
+func (f *Flags) AddPFlags(fs *pflag.FlagSet) {
+	for _, kf := range f.flags {
+		pf := fs.VarPF(kf, kf.name, "", kf.usage)
+		if kf.IsBoolFlag() {
+			pf.NoOptDefVal = "true"
+		}
+	}
+	klogFlags := flag.NewFlagSet("klog", flag.ContinueOnError)
+	klog.InitFlags(klogFlags)
+	fs.AddGoFlagSet(klogFlags)
+}
+
+// Option returns the option applying the flags set on the command line. It must
+// be called after the flags are parsed
+func (f *Flags) Option() controllers.Option {
+	return controllers.OptionFunc(func(opts *controllers.ControllerOptions) {
+		for _, kf := range f.flags {
+			if kf.set {
+				kf.applyF(opts)
+			}
+		}
+	})
+}
@@ -0,0 +1,213 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The package health keeps track of the kgame controllers health, and reports
+// it through a ConfigMap so fleet operators can scrape it from the cluster state.
+package health
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	defaultNamespace = "default"
+	defaultInterval  = 30 * time.Second
+	cacheSyncTimeout = time.Second
+)
+
+// StatusOptions configures the ConfigMap where the controller health is reported
+type StatusOptions struct {
+	// Name of the ConfigMap. The status report is disabled when empty
+	Name string
+	// Namespace of the ConfigMap. Defaults to "default"
+	Namespace string
+	// Interval between status updates. Defaults to 30 seconds
+	Interval time.Duration
+}
+
+// Reporter tracks the health of the kgame controllers and periodically writes it
+// to a ConfigMap. It only runs on the elected leader
+type Reporter struct {
+	client    client.Client
+	apiReader client.Reader
+	cache     cache.Cache
+	logger    logr.Logger
+	options   StatusOptions
+	identity  string
+
+	mu            sync.Mutex
+	started       bool
+	controllers   []string
+	lastReconcile map[string]time.Time
+}
+
+// NewReporter returns a Reporter for the manager. The Reporter should be
+// added to the manager to be started
+func NewReporter(mgr manager.Manager, options StatusOptions) *Reporter {
+	if options.Namespace == "" {
+		options.Namespace = defaultNamespace
+	}
+	if options.Interval <= 0 {
+		options.Interval = defaultInterval
+	}
+
+	identity, err := os.Hostname()
+	if err != nil {
+		identity = "unknown"
+	}
+
+	return &Reporter{
+		client:        mgr.GetClient(),
+		apiReader:     mgr.GetAPIReader(),
+		cache:         mgr.GetCache(),
+		logger:        mgr.GetLogger().WithValues("component", "status"),
+		options:       options,
+		identity:      identity,
+		lastReconcile: make(map[string]time.Time),
+	}
+}
+
+// RegisterController marks the controller of kind as running
+func (r *Reporter) RegisterController(kind string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !slices.Contains(r.controllers, kind) {
+		r.controllers = append(r.controllers, kind)
+	}
+}
+
+// Reconciled records a successful reconciliation of kind
+func (r *Reporter) Reconciled(kind string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lastReconcile[kind] = time.Now()
+}
+
+// Start writes the controller health to the ConfigMap until ctx is done
+func (r *Reporter) Start(ctx context.Context) error {
+	r.mu.Lock()
+	r.started = true
+	r.mu.Unlock()
+
+	ticker := time.NewTicker(r.options.Interval)
+	defer ticker.Stop()
+	for {
+		if err := r.report(ctx); err != nil {
+			r.logger.Error(err, "unable to report controller health")
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Flush writes the last controller health to the ConfigMap, like the
+// reconciliations finished while the manager was stopping. It is a noop if the
+// Reporter is nil or was never started, as this replica was never the leader
+func (r *Reporter) Flush(ctx context.Context) error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	started := r.started
+	r.mu.Unlock()
+	if !started {
+		return nil
+	}
+	return r.report(ctx)
+}
+
+// NeedLeaderElection makes the Reporter run only on the leader, so the reported
+// identity is the one of the leader
+func (r *Reporter) NeedLeaderElection() bool {
+	return true
+}
+
+func (r *Reporter) report(ctx context.Context) error {
+	syncCtx, cancel := context.WithTimeout(ctx, cacheSyncTimeout)
+	defer cancel()
+	synced := r.cache.WaitForCacheSync(syncCtx)
+
+	r.mu.Lock()
+	data := map[string]string{
+		"leader":      r.identity,
+		"controllers": strings.Join(r.controllers, ","),
+		"cacheSynced": fmt.Sprintf("%t", synced),
+	}
+	for kind, last := range r.lastReconcile {
+		data["lastReconcile."+kind] = last.UTC().Format(time.RFC3339)
+	}
+	r.mu.Unlock()
+
+	// The ConfigMap is read directly from the API server, to avoid caching all the
+	// ConfigMaps of the cluster
+	configMap := &corev1.ConfigMap{}
+	err := r.apiReader.Get(ctx, types.NamespacedName{Namespace: r.options.Namespace, Name: r.options.Name}, configMap)
+	if apierrors.IsNotFound(err) {
+		configMap.SetNamespace(r.options.Namespace)
+		configMap.SetName(r.options.Name)
+		configMap.Data = data
+		return r.client.Create(ctx, configMap)
+	}
+	if err != nil {
+		return err
+	}
+	if maps.Equal(configMap.Data, data) {
+		return nil
+	}
+	configMap.Data = data
+	return r.client.Update(ctx, configMap)
+}
+
+// ObserveReconciler wraps rec, recording on the reporter each successful
+// reconciliation of kind. If the reporter is nil rec is returned unchanged
+func ObserveReconciler(kind string, reporter *Reporter, rec reconcile.Reconciler) reconcile.Reconciler {
+	if reporter == nil {
+		return rec
+	}
+	reporter.RegisterController(kind)
+	return reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+		result, err := rec.Reconcile(ctx, req)
+		if err == nil {
+			reporter.Reconciled(kind)
+		}
+		return result, err
+	})
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The package hooks contains the shared machinery used by the kgame controllers
+// to call the hooks supplied by implementers.
+package hooks
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// ErrTerminal signals that a hook failed in a way that retrying will not fix,
+// like an invalid user input. Hooks can return it wrapped, or use Terminal.
+// Terminal errors are surfaced as a condition on the object and the object is
+// not requeued, while any other error is retried with backoff
+var ErrTerminal = errors.New("terminal error")
+
+type terminalError struct {
+	err error
+}
+
+func (e *terminalError) Error() string {
+	return e.err.Error()
+}
+
+func (e *terminalError) Unwrap() error {
+	return e.err
+}
+
+func (e *terminalError) Is(target error) bool {
+	return target == ErrTerminal
+}
+
+// Terminal marks err as a terminal error, keeping its message
+func Terminal(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &terminalError{err: err}
+}
+
+// IsTerminal returns true if err is, or wraps, a terminal error
+func IsTerminal(err error) bool {
+	return errors.Is(err, ErrTerminal)
+}
+
+// ReconcileError converts the error returned by a hook into the error returned
+// by the reconciler. Terminal errors are converted to controller-runtime terminal
+// errors, so the object is not requeued
+func ReconcileError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if IsTerminal(err) {
+		return reconcile.TerminalError(err)
+	}
+	return err
+}
+
+// requeueError signals that a hook asked to reconcile the object again after a
+// delay
+type requeueError struct {
+	after time.Duration
+}
+
+func (e *requeueError) Error() string {
+	return fmt.Sprintf("requeue requested after %s", e.after)
+}
+
+// RequeueAfter can be returned, or wrapped, by a hook to ask for the object to be
+// reconciled again after d, like when waiting for an external load balancer to be
+// provisioned. It stops the current reconciliation, but it is not handled as a
+// failure, so no backoff is applied
+func RequeueAfter(d time.Duration) error {
+	return &requeueError{after: d}
+}
+
+// IsRequeue returns the delay requested by a RequeueAfter error, and true if err
+// is, or wraps, one of them
+func IsRequeue(err error) (time.Duration, bool) {
+	var requeue *requeueError
+	if errors.As(err, &requeue) {
+		return requeue.after, true
+	}
+	return 0, false
+}
+
+// Result converts the error returned by a hook into the result of the reconciler.
+// RequeueAfter errors are converted to a delayed requeue, terminal errors to
+// controller-runtime terminal errors and any other error is returned unchanged
+func Result(err error) (reconcile.Result, error) {
+	if after, ok := IsRequeue(err); ok {
+		return reconcile.Result{RequeueAfter: after}, nil
+	}
+	return reconcile.Result{}, ReconcileError(err)
+}
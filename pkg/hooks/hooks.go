@@ -0,0 +1,127 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hooks
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// FinalizerOperation is the change of the finalizer a finalizer hook is called for
+type FinalizerOperation string
+
+const (
+	// FinalizerAdd is the addition of the finalizer
+	FinalizerAdd FinalizerOperation = "Add"
+	// FinalizerRemove is the removal of the finalizer
+	FinalizerRemove FinalizerOperation = "Remove"
+)
+
+// FinalizerReason is the reason the finalizer changes
+type FinalizerReason string
+
+const (
+	// ReasonAdoption is used when kgame starts managing an object that does not
+	// have the finalizer, like a new object or one created before kgame
+	ReasonAdoption FinalizerReason = "Adoption"
+	// ReasonDeletion is used when the object is being deleted
+	ReasonDeletion FinalizerReason = "Deletion"
+)
+
+// FinalizerContext is passed to the finalizer hooks, so they do not need to fetch
+// again what kgame already resolved
+type FinalizerContext struct {
+	// Object is the object getting or losing the finalizer. It must not be
+	// modified by the hook
+	Object client.Object
+	// Operation is the finalizer change
+	Operation FinalizerOperation
+	// Reason is why the finalizer changes
+	Reason FinalizerReason
+	// Logger is the logger of the reconciliation
+	Logger logr.Logger
+	// Parameters are the resolved parameters of the GatewayClass of the object.
+	// Empty when the GatewayClass has no parameters, or they cannot be resolved
+	Parameters any
+}
+
+// AddFinalizerFunc is a function that should be called immediately before adding a
+// finalizer.
+// If empty the finalizer will be added without further check
+type AddFinalizerFunc func(ctx context.Context, fctx FinalizerContext) error
+
+// RemoveFinalizerFunc is a function that should be called immediately before removing
+// a finalizer. If empty the finalizer will be removed without any further check
+type RemoveFinalizerFunc func(ctx context.Context, fctx FinalizerContext) error
+
+// ReconcileHooks can be implemented to observe and extend the reconciliation of
+// an object of type T without forking the reconciler.
+// The hooks returning a boolean can veto the rest of the processing by returning
+// false; an error is retried with backoff, unless it is a terminal error (see
+// ErrTerminal), that is surfaced as a condition and not retried
+type ReconcileHooks[T client.Object] interface {
+	// PreReconcile is called once the object is fetched, before any finalizer or
+	// status change. Returning false skips the reconciliation of this object
+	PreReconcile(ctx context.Context, logger logr.Logger, obj T) (bool, error)
+	// PostReconcile is called after the object status was successfully updated
+	PostReconcile(ctx context.Context, logger logr.Logger, obj T) error
+	// OnDelete is called when the object is being deleted, before its finalizer is
+	// removed. Returning false keeps the finalizer, holding the deletion
+	OnDelete(ctx context.Context, logger logr.Logger, obj T) (bool, error)
+}
+
+// Hooks groups the reconciliation hooks shared by all the kgame controllers for
+// an object of type T. All the hooks are optional, and are called through the
+// Runner. The finalizer hooks are called by a finalizer.Manager
+type Hooks[T client.Object] struct {
+	ReconcileHooks ReconcileHooks[T]
+	Runner         Runner
+}
+
+// PreReconcile calls the PreReconcile hook. It returns true if the reconciliation
+// should proceed
+func (h Hooks[T]) PreReconcile(ctx context.Context, logger logr.Logger, obj T) (bool, error) {
+	if h.ReconcileHooks == nil {
+		return true, nil
+	}
+	return Call(ctx, h.Runner, obj, "PreReconcile", func(ctx context.Context, obj T) (bool, error) {
+		return h.ReconcileHooks.PreReconcile(ctx, logger, obj)
+	})
+}
+
+// PostReconcile calls the PostReconcile hook
+func (h Hooks[T]) PostReconcile(ctx context.Context, logger logr.Logger, obj T) error {
+	if h.ReconcileHooks == nil {
+		return nil
+	}
+	return Run(ctx, h.Runner, obj, "PostReconcile", func(ctx context.Context, obj T) error {
+		return h.ReconcileHooks.PostReconcile(ctx, logger, obj)
+	})
+}
+
+// OnDelete calls the OnDelete hook. It returns true if the deletion should
+// proceed
+func (h Hooks[T]) OnDelete(ctx context.Context, logger logr.Logger, obj T) (bool, error) {
+	if h.ReconcileHooks == nil {
+		return true, nil
+	}
+	return Call(ctx, h.Runner, obj, "OnDelete", func(ctx context.Context, obj T) (bool, error) {
+		return h.ReconcileHooks.OnDelete(ctx, logger, obj)
+	})
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hooks
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+// DefaultTimeout is the timeout of a hook call when none is configured
+const DefaultTimeout = 30 * time.Second
+
+// Runner calls the hooks supplied by implementers with a timeout and a panic
+// recovery, so a misbehaving hook cannot wedge a worker or crash the manager
+type Runner struct {
+	timeout  time.Duration
+	recorder record.EventRecorder
+}
+
+// NewRunner returns a Runner with the given timeout, defaulting to DefaultTimeout.
+// Panics and timeouts are reported as Warning events through recorder, when not
+// nil
+func NewRunner(timeout time.Duration, recorder record.EventRecorder) Runner {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return Runner{
+		timeout:  timeout,
+		recorder: recorder,
+	}
+}
+
+// Run calls a hook that only returns an error. See Call
+func Run[O runtime.Object](ctx context.Context, r Runner, obj O, name string, fn func(ctx context.Context, obj O) error) error {
+	_, err := Call(ctx, r, obj, name, func(ctx context.Context, obj O) (struct{}, error) {
+		return struct{}{}, fn(ctx, obj)
+	})
+	return err
+}
+
+// Call calls the hook fn, named name, on behalf of obj. The hook receives a
+// context cancelled after the Runner timeout, and the call returns once the
+// timeout expires even if the hook ignores the context. In this case the hook
+// keeps running in background and its result is discarded.
+// The hook receives a copy of obj, copied back to obj once the hook returns in
+// time, so a hook running after its timeout cannot change the object written by
+// the reconciler. A panic on the hook is recovered and returned as an error
+func Call[O runtime.Object, T any](ctx context.Context, r Runner, obj O, name string, fn func(ctx context.Context, obj O) (T, error)) (T, error) {
+	type result struct {
+		value    T
+		err      error
+		panicked bool
+	}
+
+	timeout := r.timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	hookCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	live := reflect.ValueOf(obj)
+	isObject := live.Kind() == reflect.Pointer && !live.IsNil()
+	hookObj := obj
+	if isObject {
+		hookObj = obj.DeepCopyObject().(O)
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				done <- result{err: fmt.Errorf("hook %s panicked: %v", name, p), panicked: true}
+			}
+		}()
+		value, err := fn(hookCtx, hookObj)
+		done <- result{value: value, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		if res.panicked {
+			if r.recorder != nil && isObject {
+				r.recorder.Event(obj, corev1.EventTypeWarning, "HookPanic", res.err.Error())
+			}
+			return res.value, res.err
+		}
+		if isObject {
+			live.Elem().Set(reflect.ValueOf(hookObj).Elem())
+		}
+		return res.value, res.err
+	case <-hookCtx.Done():
+		var zero T
+		err := fmt.Errorf("hook %s did not finish: %w", name, hookCtx.Err())
+		if r.recorder != nil && isObject {
+			r.recorder.Event(obj, corev1.EventTypeWarning, "HookTimeout", err.Error())
+		}
+		return zero, err
+	}
+}
@@ -0,0 +1,233 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The package indexes contains the cache indexes shared by the kgame controllers.
+package indexes
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+const (
+	// GatewayClassName indexes the Gateways by their spec.gatewayClassName
+	GatewayClassName = "spec.gatewayClassName"
+	// HTTPRouteParentGateway indexes the HTTPRoutes by the Gateways referenced on
+	// their spec.parentRefs, using GatewayKey. The routes referencing a listener
+	// by its sectionName are also indexed by ListenerKey
+	HTTPRouteParentGateway = "spec.parentRefs.gateway"
+	// HTTPRouteBackendService indexes the HTTPRoutes by the Services referenced on
+	// the backendRefs of their rules, using ServiceKey
+	HTTPRouteBackendService = "spec.rules.backendRefs.service"
+	// GatewayCertificateSecret indexes the Gateways by the Secrets referenced on
+	// the certificateRefs of their listeners, using SecretKey
+	GatewayCertificateSecret = "spec.listeners.tls.certificateRefs"
+	// GatewayClassParameters indexes the GatewayClasses by their
+	// spec.parametersRef, using ParametersKey
+	GatewayClassParameters = "spec.parametersRef"
+	// GatewayInfrastructureParameters indexes the Gateways by their
+	// spec.infrastructure.parametersRef, using ParametersKey
+	GatewayInfrastructureParameters = "spec.infrastructure.parametersRef"
+)
+
+type registration struct {
+	indexer client.FieldIndexer
+	field   string
+}
+
+// registered are the indexes already added to each indexer, as an index cannot
+// be added twice to the same indexer
+var registered sync.Map
+
+// AddGatewayClassName adds the GatewayClassName index to indexer. It can be called
+// by each controller that uses the index
+func AddGatewayClassName(ctx context.Context, indexer client.FieldIndexer) error {
+	return add(ctx, indexer, &gatewayv1.Gateway{}, GatewayClassName, func(obj client.Object) []string {
+		gw, ok := obj.(*gatewayv1.Gateway)
+		if !ok {
+			return nil
+		}
+		return []string{string(gw.Spec.GatewayClassName)}
+	})
+}
+
+// AddHTTPRouteParentGateway adds the HTTPRouteParentGateway index to indexer. It
+// can be called by each controller that uses the index
+func AddHTTPRouteParentGateway(ctx context.Context, indexer client.FieldIndexer) error {
+	return add(ctx, indexer, &gatewayv1.HTTPRoute{}, HTTPRouteParentGateway, func(obj client.Object) []string {
+		route, ok := obj.(*gatewayv1.HTTPRoute)
+		if !ok {
+			return nil
+		}
+		var keys []string
+		for _, parentRef := range route.Spec.ParentRefs {
+			if parentRef.Group != nil && *parentRef.Group != gatewayv1.GroupName {
+				continue
+			}
+			if parentRef.Kind != nil && *parentRef.Kind != "Gateway" {
+				continue
+			}
+			namespace := route.GetNamespace()
+			if parentRef.Namespace != nil {
+				namespace = string(*parentRef.Namespace)
+			}
+			keys = append(keys, GatewayKey(namespace, string(parentRef.Name)))
+			if parentRef.SectionName != nil {
+				keys = append(keys, ListenerKey(namespace, string(parentRef.Name), *parentRef.SectionName))
+			}
+		}
+		return keys
+	})
+}
+
+// AddHTTPRouteBackendService adds the HTTPRouteBackendService index to indexer. It
+// can be called by each controller that uses the index
+func AddHTTPRouteBackendService(ctx context.Context, indexer client.FieldIndexer) error {
+	return add(ctx, indexer, &gatewayv1.HTTPRoute{}, HTTPRouteBackendService, func(obj client.Object) []string {
+		route, ok := obj.(*gatewayv1.HTTPRoute)
+		if !ok {
+			return nil
+		}
+		var keys []string
+		for _, rule := range route.Spec.Rules {
+			for _, backendRef := range rule.BackendRefs {
+				ref := backendRef.BackendObjectReference
+				if ref.Group != nil && *ref.Group != "" {
+					continue
+				}
+				if ref.Kind != nil && *ref.Kind != "Service" {
+					continue
+				}
+				namespace := route.GetNamespace()
+				if ref.Namespace != nil {
+					namespace = string(*ref.Namespace)
+				}
+				if key := ServiceKey(namespace, string(ref.Name)); !slices.Contains(keys, key) {
+					keys = append(keys, key)
+				}
+			}
+		}
+		return keys
+	})
+}
+
+// AddGatewayCertificateSecret adds the GatewayCertificateSecret index to indexer.
+// It can be called by each controller that uses the index
+func AddGatewayCertificateSecret(ctx context.Context, indexer client.FieldIndexer) error {
+	return add(ctx, indexer, &gatewayv1.Gateway{}, GatewayCertificateSecret, func(obj client.Object) []string {
+		gw, ok := obj.(*gatewayv1.Gateway)
+		if !ok {
+			return nil
+		}
+		var keys []string
+		for _, listener := range gw.Spec.Listeners {
+			if listener.TLS == nil {
+				continue
+			}
+			for _, ref := range listener.TLS.CertificateRefs {
+				if ref.Group != nil && *ref.Group != "" {
+					continue
+				}
+				if ref.Kind != nil && *ref.Kind != "Secret" {
+					continue
+				}
+				namespace := gw.GetNamespace()
+				if ref.Namespace != nil {
+					namespace = string(*ref.Namespace)
+				}
+				if key := SecretKey(namespace, string(ref.Name)); !slices.Contains(keys, key) {
+					keys = append(keys, key)
+				}
+			}
+		}
+		return keys
+	})
+}
+
+// AddGatewayClassParameters adds the GatewayClassParameters index to indexer. It
+// can be called by each controller that uses the index
+func AddGatewayClassParameters(ctx context.Context, indexer client.FieldIndexer) error {
+	return add(ctx, indexer, &gatewayv1.GatewayClass{}, GatewayClassParameters, func(obj client.Object) []string {
+		gatewayClass, ok := obj.(*gatewayv1.GatewayClass)
+		if !ok || gatewayClass.Spec.ParametersRef == nil {
+			return nil
+		}
+		ref := gatewayClass.Spec.ParametersRef
+		var namespace string
+		if ref.Namespace != nil {
+			namespace = string(*ref.Namespace)
+		}
+		return []string{ParametersKey(string(ref.Group), string(ref.Kind), namespace, ref.Name)}
+	})
+}
+
+// AddGatewayInfrastructureParameters adds the GatewayInfrastructureParameters
+// index to indexer. It can be called by each controller that uses the index
+func AddGatewayInfrastructureParameters(ctx context.Context, indexer client.FieldIndexer) error {
+	return add(ctx, indexer, &gatewayv1.Gateway{}, GatewayInfrastructureParameters, func(obj client.Object) []string {
+		gw, ok := obj.(*gatewayv1.Gateway)
+		if !ok || gw.Spec.Infrastructure == nil || gw.Spec.Infrastructure.ParametersRef == nil {
+			return nil
+		}
+		ref := gw.Spec.Infrastructure.ParametersRef
+		return []string{ParametersKey(string(ref.Group), string(ref.Kind), gw.GetNamespace(), ref.Name)}
+	})
+}
+
+// ParametersKey is the GatewayClassParameters and GatewayInfrastructureParameters
+// index value of a parameters object. The namespace is empty for the cluster
+// scoped kinds
+func ParametersKey(group, kind, namespace, name string) string {
+	return group + "/" + kind + "/" + namespace + "/" + name
+}
+
+// SecretKey is the GatewayCertificateSecret index value of a Secret
+func SecretKey(namespace, name string) string {
+	return namespace + "/" + name
+}
+
+// ServiceKey is the HTTPRouteBackendService index value of a Service
+func ServiceKey(namespace, name string) string {
+	return namespace + "/" + name
+}
+
+// GatewayKey is the HTTPRouteParentGateway index value of a Gateway
+func GatewayKey(namespace, name string) string {
+	return namespace + "/" + name
+}
+
+// ListenerKey is the HTTPRouteParentGateway index value of a Gateway listener.
+// It only matches the routes referencing the listener by its sectionName, the
+// routes attached to all the listeners are matched by GatewayKey
+func ListenerKey(namespace, name string, sectionName gatewayv1.SectionName) string {
+	return GatewayKey(namespace, name) + "/" + string(sectionName)
+}
+
+func add(ctx context.Context, indexer client.FieldIndexer, obj client.Object, field string, extract client.IndexerFunc) error {
+	if _, loaded := registered.LoadOrStore(registration{indexer: indexer, field: field}, struct{}{}); loaded {
+		return nil
+	}
+	if err := indexer.IndexField(ctx, obj, field, extract); err != nil {
+		registered.Delete(registration{indexer: indexer, field: field})
+		return fmt.Errorf("error adding the %s index: %w", field, err)
+	}
+	return nil
+}
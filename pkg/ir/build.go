@@ -0,0 +1,333 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ir
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/rikatz/kgame/pkg/attachment"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// BuildOptions configures how the Graph is built
+type BuildOptions struct {
+	// ControllerName is the GatewayClass controllerName managed by kgame. When
+	// empty, all the GatewayClasses returned by the reader are considered managed
+	ControllerName gatewayv1.GatewayController
+	// AdditionalControllerNames are the other GatewayClass controllerNames managed
+	// by kgame
+	AdditionalControllerNames []gatewayv1.GatewayController
+	// PolicyKinds are the policy kinds attached to the graph nodes. The policies
+	// are expected to have the Gateway API spec.targetRefs, or spec.targetRef,
+	// field. Policies targeting a resource outside of the graph are ignored
+	PolicyKinds []schema.GroupVersionKind
+	// ListOptions are added to each List call, like client.UnsafeDisableDeepCopy
+	// when the graph is built from a cache and is only read
+	ListOptions []client.ListOption
+}
+
+// Build builds the Graph of the managed resources read from reader.
+// Only references on the same namespace of the referrer are resolved, as
+// ReferenceGrants are not supported yet
+func Build(ctx context.Context, reader client.Reader, opts BuildOptions) (*Graph, error) {
+	b := &builder{
+		reader:          reader,
+		opts:            opts,
+		namespaceLabels: make(map[string]map[string]string),
+		graph: &Graph{
+			GatewayClasses: make(map[string]*GatewayClass),
+			HTTPRoutes:     make(map[types.NamespacedName]*HTTPRoute),
+		},
+	}
+
+	if err := b.addGatewayClasses(ctx); err != nil {
+		return nil, err
+	}
+	if err := b.addGateways(ctx); err != nil {
+		return nil, err
+	}
+	if err := b.addHTTPRoutes(ctx); err != nil {
+		return nil, err
+	}
+	if err := b.addPolicies(ctx); err != nil {
+		return nil, err
+	}
+	return b.graph, nil
+}
+
+type builder struct {
+	reader          client.Reader
+	opts            BuildOptions
+	graph           *Graph
+	namespaceLabels map[string]map[string]string
+}
+
+func (b *builder) addGatewayClasses(ctx context.Context) error {
+	classes := &gatewayv1.GatewayClassList{}
+	if err := b.reader.List(ctx, classes, b.opts.ListOptions...); err != nil {
+		return fmt.Errorf("error listing gatewayclasses: %w", err)
+	}
+	for i := range classes.Items {
+		class := &classes.Items[i]
+		if b.opts.ControllerName != "" && class.Spec.ControllerName != b.opts.ControllerName && !slices.Contains(b.opts.AdditionalControllerNames, class.Spec.ControllerName) {
+			continue
+		}
+		b.graph.GatewayClasses[class.GetName()] = &GatewayClass{Object: class}
+	}
+	return nil
+}
+
+func (b *builder) addGateways(ctx context.Context) error {
+	gateways := &gatewayv1.GatewayList{}
+	if err := b.reader.List(ctx, gateways, b.opts.ListOptions...); err != nil {
+		return fmt.Errorf("error listing gateways: %w", err)
+	}
+	for i := range gateways.Items {
+		gw := &gateways.Items[i]
+		class, ok := b.graph.GatewayClasses[string(gw.Spec.GatewayClassName)]
+		if !ok {
+			continue
+		}
+
+		node := &Gateway{Object: gw, Class: class}
+		for _, listener := range gw.Spec.Listeners {
+			listenerNode := &Listener{Spec: listener, Gateway: node}
+			if listener.TLS != nil {
+				for _, ref := range listener.TLS.CertificateRefs {
+					if !IsLocalRef(ref.Group, ref.Kind, ref.Namespace, "Secret", gw.GetNamespace()) {
+						continue
+					}
+					secret := &corev1.Secret{}
+					found, err := b.getOptional(ctx, types.NamespacedName{Namespace: gw.GetNamespace(), Name: string(ref.Name)}, secret)
+					if err != nil {
+						return fmt.Errorf("error getting certificate of gateway %s/%s: %w", gw.GetNamespace(), gw.GetName(), err)
+					}
+					if found {
+						listenerNode.Certificates = append(listenerNode.Certificates, secret)
+					}
+				}
+			}
+			node.Listeners = append(node.Listeners, listenerNode)
+		}
+		class.Gateways = append(class.Gateways, node)
+	}
+	return nil
+}
+
+func (b *builder) addHTTPRoutes(ctx context.Context) error {
+	routes := &gatewayv1.HTTPRouteList{}
+	if err := b.reader.List(ctx, routes, b.opts.ListOptions...); err != nil {
+		return fmt.Errorf("error listing httproutes: %w", err)
+	}
+	for i := range routes.Items {
+		route := &routes.Items[i]
+		var node *HTTPRoute
+		for _, parentRef := range route.Spec.ParentRefs {
+			for _, class := range b.graph.GatewayClasses {
+				for _, gw := range class.Gateways {
+					if !attachment.ParentRefersTo(parentRef, route.GetNamespace(), gw.Object) {
+						continue
+					}
+					labels, err := b.labelsOf(ctx, route.GetNamespace())
+					if err != nil {
+						return err
+					}
+					result := attachment.Resolve(attachment.Route{
+						Kind:            "HTTPRoute",
+						Namespace:       route.GetNamespace(),
+						NamespaceLabels: labels,
+						Hostnames:       route.Spec.Hostnames,
+					}, parentRef, gw.Object)
+					for _, name := range result.Listeners {
+						listener := gw.Listener(name)
+						if listener == nil {
+							continue
+						}
+						if node == nil {
+							node = &HTTPRoute{Object: route}
+						}
+						// A route can reach the same listener through several
+						// parentRefs
+						if slices.Contains(node.Listeners, listener) {
+							continue
+						}
+						listener.HTTPRoutes = append(listener.HTTPRoutes, node)
+						node.Listeners = append(node.Listeners, listener)
+					}
+				}
+			}
+		}
+		if node == nil {
+			continue
+		}
+
+		for _, rule := range route.Spec.Rules {
+			ruleNode := &HTTPRouteRule{Spec: rule, Route: node}
+			for _, backendRef := range rule.BackendRefs {
+				backend := &Backend{Ref: backendRef}
+				ref := backendRef.BackendObjectReference
+				if IsLocalRef(ref.Group, ref.Kind, ref.Namespace, "Service", route.GetNamespace()) {
+					service := &corev1.Service{}
+					found, err := b.getOptional(ctx, types.NamespacedName{Namespace: route.GetNamespace(), Name: string(ref.Name)}, service)
+					if err != nil {
+						return fmt.Errorf("error getting backend of httproute %s/%s: %w", route.GetNamespace(), route.GetName(), err)
+					}
+					if found {
+						backend.Service = service
+					}
+				}
+				ruleNode.Backends = append(ruleNode.Backends, backend)
+			}
+			node.Rules = append(node.Rules, ruleNode)
+		}
+		b.graph.HTTPRoutes[client.ObjectKeyFromObject(route)] = node
+	}
+	return nil
+}
+
+func (b *builder) addPolicies(ctx context.Context) error {
+	for _, gvk := range b.opts.PolicyKinds {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := b.reader.List(ctx, list, b.opts.ListOptions...); err != nil {
+			return fmt.Errorf("error listing %s: %w", gvk.Kind, err)
+		}
+		for i := range list.Items {
+			policy := &Policy{Object: &list.Items[i]}
+			for _, target := range targetRefs(policy.Object) {
+				b.attachPolicy(policy, target)
+			}
+		}
+	}
+	return nil
+}
+
+// attachPolicy attaches the policy to the graph node referenced by target
+func (b *builder) attachPolicy(policy *Policy, target policyTargetRef) {
+	namespace := policy.Object.GetNamespace()
+	switch {
+	case target.Group == gatewayv1.GroupName && target.Kind == "GatewayClass":
+		if class, ok := b.graph.GatewayClasses[target.Name]; ok {
+			class.Policies = append(class.Policies, policy)
+		}
+	case target.Group == gatewayv1.GroupName && target.Kind == "Gateway":
+		gw := b.graph.Gateway(types.NamespacedName{Namespace: namespace, Name: target.Name})
+		if gw == nil {
+			return
+		}
+		if target.SectionName == "" {
+			gw.Policies = append(gw.Policies, policy)
+			return
+		}
+		if listener := gw.Listener(gatewayv1.SectionName(target.SectionName)); listener != nil {
+			listener.Policies = append(listener.Policies, policy)
+		}
+	case target.Group == gatewayv1.GroupName && target.Kind == "HTTPRoute":
+		if route, ok := b.graph.HTTPRoutes[types.NamespacedName{Namespace: namespace, Name: target.Name}]; ok {
+			route.Policies = append(route.Policies, policy)
+		}
+	case target.Group == "" && target.Kind == "Service":
+		for _, route := range b.graph.HTTPRoutes {
+			for _, rule := range route.Rules {
+				for _, backend := range rule.Backends {
+					if backend.Service != nil && backend.Service.GetNamespace() == namespace && backend.Service.GetName() == target.Name {
+						backend.Policies = append(backend.Policies, policy)
+					}
+				}
+			}
+		}
+	}
+}
+
+// policyTargetRef is the Gateway API policy target reference
+type policyTargetRef struct {
+	Group       string
+	Kind        string
+	Name        string
+	SectionName string
+}
+
+// targetRefs returns the targets of a policy, from either spec.targetRefs or
+// spec.targetRef
+func targetRefs(policy *unstructured.Unstructured) []policyTargetRef {
+	var raw []any
+	if refs, found, _ := unstructured.NestedSlice(policy.Object, "spec", "targetRefs"); found {
+		raw = refs
+	} else if ref, found, _ := unstructured.NestedMap(policy.Object, "spec", "targetRef"); found {
+		raw = []any{ref}
+	}
+
+	targets := make([]policyTargetRef, 0, len(raw))
+	for _, r := range raw {
+		ref, ok := r.(map[string]any)
+		if !ok {
+			continue
+		}
+		target := policyTargetRef{}
+		target.Group, _, _ = unstructured.NestedString(ref, "group")
+		target.Kind, _, _ = unstructured.NestedString(ref, "kind")
+		target.Name, _, _ = unstructured.NestedString(ref, "name")
+		target.SectionName, _, _ = unstructured.NestedString(ref, "sectionName")
+		targets = append(targets, target)
+	}
+	return targets
+}
+
+// labelsOf returns the labels of the namespace, caching them for the build
+func (b *builder) labelsOf(ctx context.Context, name string) (map[string]string, error) {
+	if labels, ok := b.namespaceLabels[name]; ok {
+		return labels, nil
+	}
+	labels, err := attachment.NamespaceLabels(ctx, b.reader, name)
+	if err != nil {
+		return nil, fmt.Errorf("error getting namespace %s: %w", name, err)
+	}
+	b.namespaceLabels[name] = labels
+	return labels, nil
+}
+
+// getOptional gets the object, returning false if it does not exist
+func (b *builder) getOptional(ctx context.Context, key types.NamespacedName, obj client.Object) (bool, error) {
+	if err := b.reader.Get(ctx, key, obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// IsLocalRef returns true if the reference points to the expected core kind, on
+// the same namespace of the referrer, applying the API defaults for an empty group,
+// kind and namespace
+func IsLocalRef(group *gatewayv1.Group, kind *gatewayv1.Kind, namespace *gatewayv1.Namespace, expected gatewayv1.Kind, referrer string) bool {
+	if group != nil && *group != "" && *group != "core" {
+		return false
+	}
+	if kind != nil && *kind != expected {
+		return false
+	}
+	return namespace == nil || string(*namespace) == referrer
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The package ir contains the intermediate representation of the resources
+// managed by kgame: a graph with the resolved relationships between
+// GatewayClasses, Gateways, Listeners, Routes, Rules and Backends, with the TLS
+// certificates and policies attached to them.
+// Implementers can consume the graph instead of the raw API objects, without
+// resolving references on their own.
+//
+// The graph nodes keep a pointer to their parents, so it can be walked in both
+// directions. Those pointers are not serialized, so the graph can be dumped as
+// JSON from the GatewayClasses down.
+package ir
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// Graph is the intermediate representation of all the managed resources
+type Graph struct {
+	// GatewayClasses are the managed GatewayClasses, by name
+	GatewayClasses map[string]*GatewayClass `json:"gatewayClasses"`
+	// HTTPRoutes are the HTTPRoutes attached to at least one managed listener, by
+	// name
+	HTTPRoutes map[types.NamespacedName]*HTTPRoute `json:"-"`
+}
+
+// Policy is a policy attached to a node of the graph. As policies are
+// implementation specific, they are kept as unstructured objects
+type Policy struct {
+	Object *unstructured.Unstructured `json:"object"`
+}
+
+// GatewayClass is a managed GatewayClass and its Gateways
+type GatewayClass struct {
+	Object   *gatewayv1.GatewayClass `json:"object"`
+	Gateways []*Gateway              `json:"gateways,omitempty"`
+	Policies []*Policy               `json:"policies,omitempty"`
+}
+
+// Gateway is a Gateway of a managed GatewayClass and its listeners
+type Gateway struct {
+	Object    *gatewayv1.Gateway `json:"object"`
+	Class     *GatewayClass      `json:"-"`
+	Listeners []*Listener        `json:"listeners,omitempty"`
+	Policies  []*Policy          `json:"policies,omitempty"`
+}
+
+// Listener is a listener of a Gateway, with its resolved TLS certificates and
+// the routes attached to it
+type Listener struct {
+	Spec    gatewayv1.Listener `json:"spec"`
+	Gateway *Gateway           `json:"-"`
+	// Certificates are the resolved TLS certificate references of the listener.
+	// Missing or not allowed references are not present
+	Certificates []*corev1.Secret `json:"certificates,omitempty"`
+	// HTTPRoutes are the HTTPRoutes accepted by the listener
+	HTTPRoutes []*HTTPRoute `json:"httpRoutes,omitempty"`
+	Policies   []*Policy    `json:"policies,omitempty"`
+}
+
+// HTTPRoute is an HTTPRoute attached to at least one managed listener. The same
+// route is shared by all the listeners it is attached to
+type HTTPRoute struct {
+	Object    *gatewayv1.HTTPRoute `json:"object"`
+	Listeners []*Listener          `json:"-"`
+	Rules     []*HTTPRouteRule     `json:"rules,omitempty"`
+	Policies  []*Policy            `json:"policies,omitempty"`
+}
+
+// HTTPRouteRule is a rule of an HTTPRoute and its backends
+type HTTPRouteRule struct {
+	Spec     gatewayv1.HTTPRouteRule `json:"spec"`
+	Route    *HTTPRoute              `json:"-"`
+	Backends []*Backend              `json:"backends,omitempty"`
+}
+
+// Backend is a backend reference of a route rule
+type Backend struct {
+	Ref gatewayv1.HTTPBackendRef `json:"ref"`
+	// Service referenced by the backend. Empty when the Service does not exist, or
+	// the reference is not allowed
+	Service  *corev1.Service `json:"service,omitempty"`
+	Policies []*Policy       `json:"policies,omitempty"`
+}
+
+// Gateway returns the Gateway with the given name, or nil if it is not managed
+func (g *Graph) Gateway(name types.NamespacedName) *Gateway {
+	for _, class := range g.GatewayClasses {
+		for _, gw := range class.Gateways {
+			if gw.Object.GetNamespace() == name.Namespace && gw.Object.GetName() == name.Name {
+				return gw
+			}
+		}
+	}
+	return nil
+}
+
+// Listener returns the listener with the given name, or nil if it does not exist
+func (g *Gateway) Listener(name gatewayv1.SectionName) *Listener {
+	for _, listener := range g.Listeners {
+		if listener.Spec.Name == name {
+			return listener
+		}
+	}
+	return nil
+}
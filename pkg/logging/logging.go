@@ -0,0 +1,213 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The package logging creates the loggers of the kgame controllers, with a text
+// or JSON format and a verbosity per subsystem that can be changed at runtime.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"sync"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/zapr"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Format is the format of the logs
+type Format string
+
+const (
+	// FormatText logs human readable lines
+	FormatText Format = "text"
+	// FormatJSON logs one JSON object per line
+	FormatJSON Format = "json"
+)
+
+// The subsystems of the kgame controllers. A subsystem is matched against the
+// logger names, and the "controller" and "component" values of the logger
+const (
+	SubsystemCacheTransform = "cache-transform"
+	SubsystemGatewayClass   = "gatewayclass"
+	SubsystemGateway        = "gateway"
+	SubsystemHTTPRoute      = "httproute"
+	SubsystemKgameConfig    = "kgameconfig"
+	SubsystemStatus         = "status"
+)
+
+// subsystemKeys are the logger values naming a subsystem
+var subsystemKeys = []string{"controller", "component"}
+
+// Options configures a logger
+type Options struct {
+	// Format of the logs. Defaults to FormatText
+	Format Format
+	// Level is the verbosity of the logs, like the klog -v flag. The logs with a
+	// higher V level are dropped
+	Level int
+	// Subsystems overrides the Level of the given subsystems, like
+	// {"cache-transform": 0, "gateway": 4}. When a logger matches more than one
+	// subsystem, the innermost one is used
+	Subsystems map[string]int
+	// Output of the logs. Defaults to os.Stderr
+	Output io.Writer
+}
+
+// New returns a logger with the options. Its levels can be changed at runtime,
+// see LevelsOf
+func New(options Options) (logr.Logger, error) {
+	var encoder zapcore.Encoder
+	switch options.Format {
+	case "", FormatText:
+		config := zap.NewDevelopmentEncoderConfig()
+		config.EncodeTime = zapcore.ISO8601TimeEncoder
+		encoder = zapcore.NewConsoleEncoder(config)
+	case FormatJSON:
+		config := zap.NewProductionEncoderConfig()
+		config.EncodeTime = zapcore.ISO8601TimeEncoder
+		encoder = zapcore.NewJSONEncoder(config)
+	default:
+		return logr.Logger{}, fmt.Errorf("unsupported log format %q, supported formats are %q and %q", options.Format, FormatText, FormatJSON)
+	}
+	output := options.Output
+	if output == nil {
+		output = os.Stderr
+	}
+
+	// The levels are filtered by the sink, so zap logs everything it receives
+	core := zapcore.NewCore(encoder, zapcore.Lock(zapcore.AddSync(output)), zap.LevelEnablerFunc(func(zapcore.Level) bool { return true }))
+	return logr.New(&levelSink{
+		LogSink: zapr.NewLogger(zap.New(core, zap.AddCaller())).GetSink(),
+		levels:  newLevels(options.Level, options.Subsystems),
+	}), nil
+}
+
+// LevelsOf returns the levels of a logger created by New, or nil for any other
+// logger
+func LevelsOf(logger logr.Logger) *Levels {
+	if sink, ok := logger.GetSink().(*levelSink); ok {
+		return sink.levels
+	}
+	return nil
+}
+
+// Levels are the verbosity of the loggers created by New, shared by all the
+// loggers derived from them
+type Levels struct {
+	mu         sync.RWMutex
+	level      int
+	startup    map[string]int
+	subsystems map[string]int
+}
+
+func newLevels(level int, subsystems map[string]int) *Levels {
+	return &Levels{level: level, startup: subsystems, subsystems: subsystems}
+}
+
+// SetLevel changes the verbosity of the logs
+func (l *Levels) SetLevel(level int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.level = level
+}
+
+// SetSubsystems overrides the startup Subsystems levels with subsystems. An
+// empty subsystems restores the startup ones
+func (l *Levels) SetSubsystems(subsystems map[string]int) {
+	current := maps.Clone(l.startup)
+	if current == nil {
+		current = make(map[string]int, len(subsystems))
+	}
+	maps.Copy(current, subsystems)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.subsystems = current
+}
+
+// enabled returns true if the logs of level are enabled for a logger matching
+// the subsystems, from the outermost to the innermost
+func (l *Levels) enabled(subsystems []string, level int) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	threshold := l.level
+	for _, subsystem := range subsystems {
+		if v, ok := l.subsystems[subsystem]; ok {
+			threshold = v
+		}
+	}
+	return level <= threshold
+}
+
+// levelSink filters the logs by the Levels of the subsystems of the logger
+type levelSink struct {
+	logr.LogSink
+	levels     *Levels
+	subsystems []string
+}
+
+var _ logr.CallDepthLogSink = &levelSink{}
+
+// Init is a noop, as the inner sink is already initialized
+func (s *levelSink) Init(logr.RuntimeInfo) {}
+
+func (s *levelSink) Enabled(level int) bool {
+	return s.levels.enabled(s.subsystems, level)
+}
+
+func (s *levelSink) WithName(name string) logr.LogSink {
+	return &levelSink{
+		LogSink:    s.LogSink.WithName(name),
+		levels:     s.levels,
+		subsystems: append(slices.Clip(s.subsystems), name),
+	}
+}
+
+func (s *levelSink) WithValues(keysAndValues ...any) logr.LogSink {
+	subsystems := slices.Clip(s.subsystems)
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		key, ok := keysAndValues[i].(string)
+		if !ok || !slices.Contains(subsystemKeys, key) {
+			continue
+		}
+		if value, ok := keysAndValues[i+1].(string); ok {
+			subsystems = append(subsystems, value)
+		}
+	}
+	return &levelSink{
+		LogSink:    s.LogSink.WithValues(keysAndValues...),
+		levels:     s.levels,
+		subsystems: subsystems,
+	}
+}
+
+// WithCallDepth forwards the call depth to the inner sink, so the caller of the
+// logs is the one of the wrapping helpers
+func (s *levelSink) WithCallDepth(depth int) logr.LogSink {
+	sink, ok := s.LogSink.(logr.CallDepthLogSink)
+	if !ok {
+		return s
+	}
+	return &levelSink{
+		LogSink:    sink.WithCallDepth(depth),
+		levels:     s.levels,
+		subsystems: s.subsystems,
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The package notify contains the typed notifications sent by the kgame
+// controllers when a managed resource changes its state, so external consumers
+// like config writers or xDS servers can react without running their own
+// informers.
+package notify
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// Type is the type of a Notification
+type Type string
+
+const (
+	// ClassAccepted is sent when a GatewayClass becomes accepted
+	ClassAccepted Type = "ClassAccepted"
+	// ClassRemoved is sent when a managed GatewayClass is removed
+	ClassRemoved Type = "ClassRemoved"
+	// GatewayAccepted is sent when a Gateway becomes accepted
+	GatewayAccepted Type = "GatewayAccepted"
+	// GatewayProgrammed is sent when a Gateway becomes programmed
+	GatewayProgrammed Type = "GatewayProgrammed"
+	// GatewayRemoved is sent when a managed Gateway is removed
+	GatewayRemoved Type = "GatewayRemoved"
+	// RouteAttached is sent when a route becomes accepted by a managed Gateway
+	RouteAttached Type = "RouteAttached"
+	// RouteDetached is sent when a route is not accepted by a managed Gateway
+	// anymore, or is deleted
+	RouteDetached Type = "RouteDetached"
+)
+
+// Notification is a state change of a managed resource. Notifications are sent
+// once the change is persisted on the resource status
+type Notification struct {
+	Type Type
+	// Name of the resource
+	Name types.NamespacedName
+	// Object is a copy of the resource. It is empty for the removal notifications,
+	// and for the RouteDetached notifications of the deleted routes
+	Object client.Object
+	// ParentRef is the Gateway of the route notifications
+	ParentRef *gatewayv1.ParentReference
+	// Listeners are the Gateway listeners of a RouteAttached notification
+	Listeners []gatewayv1.SectionName
+}
+
+// Func receives the notifications. It is called synchronously by the controller
+// workers, so it should return quickly
+type Func func(ctx context.Context, notification Notification)
+
+// Send calls f with the notification, if f is not empty
+func (f Func) Send(ctx context.Context, notification Notification) {
+	if f != nil {
+		f(ctx, notification)
+	}
+}
+
+// Channel returns a Func that sends the notifications to ch. Sending blocks the
+// controller worker until ch receives the notification or the reconciliation is
+// cancelled, so ch should be buffered and drained continuously
+func Channel(ch chan<- Notification) Func {
+	return func(ctx context.Context, notification Notification) {
+		select {
+		case ch <- notification:
+		case <-ctx.Done():
+		}
+	}
+}
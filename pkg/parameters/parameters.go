@@ -0,0 +1,123 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The package parameters resolves the GatewayClass parametersRef and the Gateway
+// infrastructure parametersRef.
+package parameters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rikatz/kgame/pkg/indexes"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// ErrInvalidReference is returned, wrapped, when the parametersRef points to a
+// kind that is not installed or to an object that does not exist
+var ErrInvalidReference = errors.New("invalid parametersRef")
+
+// Resolve returns the object referenced by the GatewayClass parametersRef, or nil
+// if it is not set. Kinds registered on the client scheme are returned as their
+// typed objects, and any other kind as an unstructured object
+func Resolve(ctx context.Context, c client.Client, gatewayClass *gatewayv1.GatewayClass) (client.Object, error) {
+	ref := gatewayClass.Spec.ParametersRef
+	if ref == nil {
+		return nil, nil
+	}
+	var namespace *string
+	if ref.Namespace != nil {
+		namespace = (*string)(ref.Namespace)
+	}
+	return resolve(ctx, c, ref.Group, ref.Kind, ref.Name, namespace)
+}
+
+// ResolveInfrastructure returns the object referenced by the Gateway
+// spec.infrastructure.parametersRef, on the Gateway namespace, or nil if it is not
+// set. The object is returned like by Resolve
+func ResolveInfrastructure(ctx context.Context, c client.Client, gw *gatewayv1.Gateway) (client.Object, error) {
+	if gw.Spec.Infrastructure == nil || gw.Spec.Infrastructure.ParametersRef == nil {
+		return nil, nil
+	}
+	ref := gw.Spec.Infrastructure.ParametersRef
+	namespace := gw.GetNamespace()
+	return resolve(ctx, c, ref.Group, ref.Kind, ref.Name, &namespace)
+}
+
+// resolve gets the referenced object. The namespace is required by the namespaced
+// kinds, and ignored by the cluster scoped ones
+func resolve(ctx context.Context, c client.Client, group gatewayv1.Group, kind gatewayv1.Kind, name string, namespace *string) (client.Object, error) {
+	mapping, err := c.RESTMapper().RESTMapping(schema.GroupKind{Group: string(group), Kind: string(kind)})
+	if err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil, fmt.Errorf("%w: kind %s is not installed", ErrInvalidReference, kind)
+		}
+		return nil, fmt.Errorf("error mapping the parametersRef kind: %w", err)
+	}
+
+	var obj client.Object
+	if typed, err := c.Scheme().New(mapping.GroupVersionKind); err == nil {
+		obj, _ = typed.(client.Object)
+	}
+	if obj == nil {
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(mapping.GroupVersionKind)
+		obj = u
+	}
+
+	key := types.NamespacedName{Name: name}
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		if namespace == nil {
+			return nil, fmt.Errorf("%w: namespace is required for kind %s", ErrInvalidReference, kind)
+		}
+		key.Namespace = *namespace
+	}
+	if err := c.Get(ctx, key, obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: %s %s not found", ErrInvalidReference, kind, key)
+		}
+		return nil, fmt.Errorf("error getting the parametersRef: %w", err)
+	}
+	return obj, nil
+}
+
+// Metadata returns an empty metadata only object of the parameters kind, to watch
+// the parameters objects without caching their content
+func Metadata(gvk schema.GroupVersionKind) *metav1.PartialObjectMetadata {
+	obj := &metav1.PartialObjectMetadata{}
+	obj.SetGroupVersionKind(gvk)
+	return obj
+}
+
+// GatewayClassesOf returns the GatewayClasses referencing the parameters object
+// of the given kind, found through the indexes.GatewayClassParameters index
+func GatewayClassesOf(ctx context.Context, c client.Client, gvk schema.GroupVersionKind, obj client.Object) ([]gatewayv1.GatewayClass, error) {
+	classes := &gatewayv1.GatewayClassList{}
+	if err := c.List(ctx, classes, client.MatchingFields{
+		indexes.GatewayClassParameters: indexes.ParametersKey(gvk.Group, gvk.Kind, obj.GetNamespace(), obj.GetName()),
+	}); err != nil {
+		return nil, fmt.Errorf("error listing the gatewayclasses of the parameters: %w", err)
+	}
+	return classes.Items, nil
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The package predicates contains the event predicates shared by the kgame
+// controllers.
+//
+// The Predicates of the options of each controller are appended to the
+// predicates of the events of its resource, like to only reconcile the
+// resources with an opt-in label. The resources filtered out are still
+// reconciled on requests from TriggerReconcile or additional watches.
+package predicates
+
+import (
+	"maps"
+	"reflect"
+	"slices"
+
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// IgnoreStatusUpdates returns a predicate skipping the updates that only change
+// the object status, like the kgame status writes. An update is status only when
+// the generation, labels, annotations, finalizers, owners and deletion timestamp
+// did not change
+func IgnoreStatusUpdates() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			if e.ObjectOld == nil || e.ObjectNew == nil {
+				return true
+			}
+			old, updated := e.ObjectOld, e.ObjectNew
+			return old.GetGeneration() != updated.GetGeneration() ||
+				!maps.Equal(old.GetLabels(), updated.GetLabels()) ||
+				!maps.Equal(old.GetAnnotations(), updated.GetAnnotations()) ||
+				!slices.Equal(old.GetFinalizers(), updated.GetFinalizers()) ||
+				!reflect.DeepEqual(old.GetOwnerReferences(), updated.GetOwnerReferences()) ||
+				!old.GetDeletionTimestamp().Equal(updated.GetDeletionTimestamp())
+		},
+	}
+}
+
+// Options attaches the predicates skipping the updates not requiring a
+// reconciliation
+type Options struct {
+	// GenerationChanged only reconciles the updates changing the object
+	// generation, which the Gateway API resources bump on spec changes. Label and
+	// annotation changes are skipped too
+	GenerationChanged bool
+	// IgnoreStatusUpdates skips the updates that only change the object status,
+	// see IgnoreStatusUpdates
+	IgnoreStatusUpdates bool
+}
+
+// Predicates returns the predicates enabled by the options
+func (o Options) Predicates() []predicate.Predicate {
+	var predicates []predicate.Predicate
+	if o.GenerationChanged {
+		predicates = append(predicates, predicate.GenerationChangedPredicate{})
+	}
+	if o.IgnoreStatusUpdates {
+		predicates = append(predicates, IgnoreStatusUpdates())
+	}
+	return predicates
+}
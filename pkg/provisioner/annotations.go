@@ -0,0 +1,108 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioner
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rikatz/kgame/pkg/indexes"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// ServiceAnnotationsFunc returns the annotations of the Service provisioned for
+// a Gateway, like the cloud provider ones requesting an internal load balancer,
+// its type or a static IP. It receives the object referenced by the Gateway
+// spec.infrastructure.parametersRef, nil when unset or not resolved. The
+// annotations not returned anymore are removed from the Service
+type ServiceAnnotationsFunc func(ctx context.Context, gw *gatewayv1.Gateway, params client.Object) (map[string]string, error)
+
+// ParametersAnnotations returns a ServiceAnnotationsFunc mapping the Gateway
+// infrastructure parameters to Service annotations, like mapping "internal" to
+// "service.beta.kubernetes.io/aws-load-balancer-internal". The parameters are
+// the keys of a ConfigMap or, for the kinds not on the scheme, the spec fields.
+// The parameters not set are not mapped
+func ParametersAnnotations(mapping map[string]string) ServiceAnnotationsFunc {
+	return func(_ context.Context, _ *gatewayv1.Gateway, params client.Object) (map[string]string, error) {
+		annotations := make(map[string]string, len(mapping))
+		for parameter, annotation := range mapping {
+			var value string
+			var found bool
+			switch params := params.(type) {
+			case *corev1.ConfigMap:
+				value, found = params.Data[parameter]
+			case *unstructured.Unstructured:
+				var err error
+				value, found, err = unstructured.NestedString(params.Object, "spec", parameter)
+				if err != nil {
+					return nil, fmt.Errorf("error reading the parameter %s: %w", parameter, err)
+				}
+			}
+			if found {
+				annotations[annotation] = value
+			}
+		}
+		return annotations, nil
+	}
+}
+
+// serviceAnnotations sets the ServiceAnnotationsFunc annotations on the Service.
+// The ones set by the TemplateFunc are kept, unless overridden
+func (r *reconciler) serviceAnnotations(ctx context.Context, gw *gatewayv1.Gateway, params client.Object, resources *Resources) error {
+	if r.options.ServiceAnnotationsFunc == nil || resources.Service == nil {
+		return nil
+	}
+	annotations, err := r.options.ServiceAnnotationsFunc(ctx, gw, params)
+	if err != nil {
+		return fmt.Errorf("error getting the annotations of the provisioned service: %w", err)
+	}
+	if len(annotations) == 0 {
+		return nil
+	}
+	if resources.Service.Annotations == nil {
+		resources.Service.Annotations = make(map[string]string, len(annotations))
+	}
+	for key, value := range annotations {
+		resources.Service.Annotations[key] = value
+	}
+	return nil
+}
+
+// gatewaysOfInfrastructureParameters enqueues the Gateways referencing a
+// parameters object of the kind gvk on their spec.infrastructure.parametersRef,
+// so their resources are provisioned again when it changes
+func gatewaysOfInfrastructureParameters(c client.Client, gvk schema.GroupVersionKind) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		gateways := &gatewayv1.GatewayList{}
+		if err := c.List(ctx, gateways, client.InNamespace(obj.GetNamespace()), client.MatchingFields{
+			indexes.GatewayInfrastructureParameters: indexes.ParametersKey(gvk.Group, gvk.Kind, obj.GetNamespace(), obj.GetName()),
+		}); err != nil {
+			return nil
+		}
+		requests := make([]reconcile.Request, 0, len(gateways.Items))
+		for i := range gateways.Items {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&gateways.Items[i])})
+		}
+		return requests
+	}
+}
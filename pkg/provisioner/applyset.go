@@ -0,0 +1,201 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioner
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+const (
+	// ApplySetPartOfLabel is the label set on the Extra resources with the ID of
+	// their ApplySet, as defined by the kubectl ApplySet specification
+	ApplySetPartOfLabel = "applyset.kubernetes.io/part-of"
+	// ApplySetIDLabel is the label set on the ApplySet parent with its ID
+	ApplySetIDLabel = "applyset.kubernetes.io/id"
+
+	applySetToolingAnnotation    = "applyset.kubernetes.io/tooling"
+	applySetGroupKindsAnnotation = "applyset.kubernetes.io/contains-group-kinds"
+	applySetTooling              = "kgame/v1"
+)
+
+// applySetKey returns the ApplySet parent of the Extra resources of the
+// dataplane, a ConfigMap
+func applySetKey(dp dataplane) types.NamespacedName {
+	return types.NamespacedName{Namespace: dp.namespace, Name: dp.name + "-applyset"}
+}
+
+// applySetID returns the ID of the ApplySet of the parent ConfigMap, as defined
+// by the ApplySet specification
+func applySetID(parent types.NamespacedName) string {
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%s.%s.ConfigMap.", parent.Name, parent.Namespace)))
+	return "applyset-" + base64.RawURLEncoding.EncodeToString(hash[:]) + "-v1"
+}
+
+// applyExtra applies the Extra resources of the dataplane, controlled by owner,
+// as members of its ApplySet, and prunes the members that are not rendered
+// anymore. The parent lists the kinds of both the previous and the current
+// members until the previous ones are pruned, so an interrupted prune is resumed
+func (r *reconciler) applyExtra(ctx context.Context, logger logr.Logger, gw *gatewayv1.Gateway, owner client.Object, dp dataplane, extra []client.Object) error {
+	key := applySetKey(dp)
+	parent := &corev1.ConfigMap{}
+	found := true
+	if err := r.client.Get(ctx, key, parent); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("error getting the applyset of the provisioned resources: %w", err)
+		}
+		found = false
+	}
+	if !found && len(extra) == 0 {
+		return nil
+	}
+
+	id := applySetID(key)
+	previous := make(map[schema.GroupKind]struct{})
+	if found {
+		for _, gk := range strings.Split(parent.GetAnnotations()[applySetGroupKindsAnnotation], ",") {
+			if gk != "" {
+				previous[schema.ParseGroupKind(gk)] = struct{}{}
+			}
+		}
+	}
+	current := make(map[schema.GroupKind]struct{})
+	rendered := make(map[string]struct{}, len(extra))
+	for _, obj := range extra {
+		gvk, err := apiutil.GVKForObject(obj, r.scheme)
+		if err != nil {
+			return err
+		}
+		obj.GetObjectKind().SetGroupVersionKind(gvk)
+		if obj.GetNamespace() == "" {
+			obj.SetNamespace(dp.namespace)
+		}
+		if obj.GetNamespace() != dp.namespace {
+			return fmt.Errorf("the extra %s %s must be on the namespace %s of its dataplane", gvk.Kind, obj.GetName(), dp.namespace)
+		}
+		labels := obj.GetLabels()
+		if labels == nil {
+			labels = make(map[string]string, 1)
+		}
+		labels[ApplySetPartOfLabel] = id
+		obj.SetLabels(labels)
+		current[gvk.GroupKind()] = struct{}{}
+		rendered[gvk.GroupKind().String()+"/"+obj.GetNamespace()+"/"+obj.GetName()] = struct{}{}
+	}
+	all := maps.Clone(previous)
+	maps.Copy(all, current)
+
+	if len(extra) > 0 {
+		if err := r.applyParent(ctx, owner, key, dp, id, all); err != nil {
+			return err
+		}
+	}
+	for _, obj := range extra {
+		if err := r.mutate(ctx, gw, obj); err != nil {
+			return err
+		}
+		if err := r.apply(ctx, owner, dp.namespace, obj); err != nil {
+			return err
+		}
+	}
+	for gk := range all {
+		if err := r.prune(ctx, logger, gk, key.Namespace, id, rendered); err != nil {
+			return err
+		}
+	}
+
+	if len(extra) == 0 {
+		logger.Info("removing the applyset of the provisioned resources", "name", key.Name)
+		return client.IgnoreNotFound(r.client.Delete(ctx, parent))
+	}
+	if len(current) != len(all) {
+		return r.applyParent(ctx, owner, key, dp, id, current)
+	}
+	return nil
+}
+
+// applyParent applies the ApplySet parent ConfigMap, listing the group kinds of
+// its members
+func (r *reconciler) applyParent(ctx context.Context, owner client.Object, key types.NamespacedName, dp dataplane, id string, groupKinds map[schema.GroupKind]struct{}) error {
+	kinds := make([]string, 0, len(groupKinds))
+	for gk := range groupKinds {
+		kinds = append(kinds, gk.String())
+	}
+	slices.Sort(kinds)
+
+	labels := maps.Clone(dp.selector)
+	labels[ApplySetIDLabel] = id
+	parent := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      key.Name,
+			Namespace: key.Namespace,
+			Labels:    labels,
+			Annotations: map[string]string{
+				applySetToolingAnnotation:    applySetTooling,
+				applySetGroupKindsAnnotation: strings.Join(kinds, ","),
+			},
+		},
+	}
+	return r.apply(ctx, owner, key.Namespace, parent)
+}
+
+// prune removes the members of the ApplySet of the kind gk that are not rendered
+// anymore. The members are listed from the API server, as their kinds are not
+// cached
+func (r *reconciler) prune(ctx context.Context, logger logr.Logger, gk schema.GroupKind, namespace, id string, rendered map[string]struct{}) error {
+	mapping, err := r.client.RESTMapper().RESTMapping(gk)
+	if err != nil {
+		if meta.IsNoMatchError(err) {
+			// The kind is not installed anymore, neither its members
+			return nil
+		}
+		return fmt.Errorf("error mapping the kind %s of the provisioned resources: %w", gk, err)
+	}
+	list := &metav1.PartialObjectMetadataList{}
+	list.SetGroupVersionKind(mapping.GroupVersionKind.GroupVersion().WithKind(mapping.GroupVersionKind.Kind + "List"))
+	if err := r.reader.List(ctx, list, client.InNamespace(namespace), client.MatchingLabels{ApplySetPartOfLabel: id}); err != nil {
+		return fmt.Errorf("error listing the provisioned %s: %w", gk, err)
+	}
+	for i := range list.Items {
+		obj := &list.Items[i]
+		if _, ok := rendered[gk.String()+"/"+obj.GetNamespace()+"/"+obj.GetName()]; ok {
+			continue
+		}
+		obj.SetGroupVersionKind(mapping.GroupVersionKind)
+		logger.Info("pruning a provisioned resource", "name", obj.GetName(), "kind", gk.String())
+		if err := client.IgnoreNotFound(r.client.Delete(ctx, obj)); err != nil {
+			return fmt.Errorf("error pruning the provisioned %s %s: %w", gk, obj.GetName(), err)
+		}
+	}
+	return nil
+}
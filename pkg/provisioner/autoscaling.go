@@ -0,0 +1,95 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioner
+
+import (
+	"fmt"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+// defaultTargetCPUUtilization is the average CPU utilization targeted by the
+// HorizontalPodAutoscalers when none is configured
+const defaultTargetCPUUtilization = 80
+
+// AutoscalingOptions configures the HorizontalPodAutoscaler provisioned for the
+// Deployment of each Gateway. The metrics can be set per Gateway, like from its
+// infrastructure parameters, with the TemplateFunc
+type AutoscalingOptions struct {
+	// MinReplicas of the dataplane. Defaults to 1
+	MinReplicas int32
+	// MaxReplicas of the dataplane. Required
+	MaxReplicas int32
+	// TargetCPUUtilization is the average CPU utilization, as a percentage of the
+	// requested CPU, targeted by the default metrics. Defaults to 80
+	TargetCPUUtilization int32
+}
+
+// validate returns an error if the HorizontalPodAutoscalers cannot be provisioned
+func (o *AutoscalingOptions) validate(mode Mode) error {
+	if mode == ModeDaemonSet {
+		return fmt.Errorf("autoscaling is not supported with the %s mode", ModeDaemonSet)
+	}
+	if o.MaxReplicas <= 0 {
+		return fmt.Errorf("autoscaling requires the MaxReplicas")
+	}
+	if o.MinReplicas > o.MaxReplicas {
+		return fmt.Errorf("autoscaling MinReplicas must not be greater than MaxReplicas")
+	}
+	if o.MinReplicas < 0 || o.TargetCPUUtilization < 0 {
+		return fmt.Errorf("autoscaling MinReplicas and TargetCPUUtilization must not be negative")
+	}
+	return nil
+}
+
+// horizontalPodAutoscaler returns the HorizontalPodAutoscaler scaling the
+// Deployment of the same name
+func horizontalPodAutoscaler(objectMeta metav1.ObjectMeta, options *AutoscalingOptions) *autoscalingv2.HorizontalPodAutoscaler {
+	minReplicas := options.MinReplicas
+	if minReplicas == 0 {
+		minReplicas = 1
+	}
+	target := options.TargetCPUUtilization
+	if target == 0 {
+		target = defaultTargetCPUUtilization
+	}
+	return &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: objectMeta,
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       objectMeta.Name,
+			},
+			MinReplicas: ptr.To(minReplicas),
+			MaxReplicas: options.MaxReplicas,
+			Metrics: []autoscalingv2.MetricSpec{{
+				Type: autoscalingv2.ResourceMetricSourceType,
+				Resource: &autoscalingv2.ResourceMetricSource{
+					Name: corev1.ResourceCPU,
+					Target: autoscalingv2.MetricTarget{
+						Type:               autoscalingv2.UtilizationMetricType,
+						AverageUtilization: ptr.To(target),
+					},
+				},
+			}},
+		},
+	}
+}
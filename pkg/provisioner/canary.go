@@ -0,0 +1,304 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioner
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rikatz/kgame/pkg/controllers/gatewayclass"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// CanaryLabel set to "true" selects the Gateways rolled out first of their class
+const CanaryLabel = "kgame.io/canary"
+
+// The reasons of the GatewayClass DataplaneRolledOut condition
+const (
+	RolloutReasonRolledOut   = "RolledOut"
+	RolloutReasonCanary      = "Canary"
+	RolloutReasonProgressing = "Progressing"
+)
+
+// CanaryOptions configures the canary rollout of the ClassImageFunc images. A
+// new image of a class is rolled out to its canary Gateways first, and to the
+// other Gateways once the dataplane pods of the canaries stayed ready for the
+// SoakPeriod. A canary pod becoming unready restarts the soak, holding the
+// rollout of the other Gateways
+type CanaryOptions struct {
+	// Gateways is the number, or percentage, of the newest Gateways of a class
+	// rolled out first, when none of them has the CanaryLabel. They are picked
+	// when the rollout of an image starts. Defaults to one
+	Gateways *intstr.IntOrString
+	// SoakPeriod is how long the dataplane pods of the canary Gateways must stay
+	// ready before the other Gateways roll out
+	SoakPeriod time.Duration
+
+	// pinned are the newest Gateways picked as the canaries of each class, kept
+	// until the class rolls out another image
+	pinned canarySets
+}
+
+// canarySets are the canary Gateways picked for the image of each class
+type canarySets struct {
+	mu      sync.Mutex
+	classes map[string]canarySet
+}
+
+// canarySet are the canary Gateways picked for an image
+type canarySet struct {
+	image    string
+	gateways map[types.NamespacedName]struct{}
+}
+
+// validate returns an error if the canary Gateways cannot be selected
+func (o *CanaryOptions) validate() error {
+	if o.SoakPeriod < 0 {
+		return fmt.Errorf("the canary SoakPeriod must not be negative")
+	}
+	if o.Gateways != nil {
+		if _, err := intstr.GetScaledValueFromIntOrPercent(o.Gateways, 100, true); err != nil {
+			return fmt.Errorf("invalid canary Gateways: %w", err)
+		}
+	}
+	return nil
+}
+
+// canaryGateways returns the canary Gateways of the class rolling out image, the
+// ones with the CanaryLabel or the newest ones. The newest ones are picked once
+// per image, so the Gateways created during the soak do not become canaries. The
+// gateways are sorted by age
+func canaryGateways(class, image string, gateways []gatewayv1.Gateway, options *CanaryOptions) []gatewayv1.Gateway {
+	var labeled []gatewayv1.Gateway
+	for _, gw := range gateways {
+		if gw.GetLabels()[CanaryLabel] == "true" {
+			labeled = append(labeled, gw)
+		}
+	}
+	if len(labeled) > 0 {
+		return labeled
+	}
+
+	options.pinned.mu.Lock()
+	defer options.pinned.mu.Unlock()
+	if set, ok := options.pinned.classes[class]; ok && set.image == image {
+		var pinned []gatewayv1.Gateway
+		for _, gw := range gateways {
+			if _, ok := set.gateways[client.ObjectKeyFromObject(&gw)]; ok {
+				pinned = append(pinned, gw)
+			}
+		}
+		// The canaries are picked again when all of them were deleted
+		if len(pinned) > 0 {
+			return pinned
+		}
+	}
+
+	count := 1
+	if options.Gateways != nil {
+		count, _ = intstr.GetScaledValueFromIntOrPercent(options.Gateways, len(gateways), true)
+		count = max(count, 1)
+	}
+	newest := gateways[len(gateways)-min(count, len(gateways)):]
+	if len(newest) == 0 {
+		return nil
+	}
+	set := canarySet{image: image, gateways: make(map[types.NamespacedName]struct{}, len(newest))}
+	for i := range newest {
+		set.gateways[client.ObjectKeyFromObject(&newest[i])] = struct{}{}
+	}
+	if options.pinned.classes == nil {
+		options.pinned.classes = make(map[string]canarySet)
+	}
+	options.pinned.classes[class] = set
+	return newest
+}
+
+// canaryProgress returns true once the dataplane pods of the canary Gateways ran
+// the image, ready, for the SoakPeriod, or a message with the canary progress
+// otherwise. The canaries not provisioned yet are skipped, but at least one
+// canary pod must run the image
+func canaryProgress(ctx context.Context, reader client.Reader, options *CanaryOptions, canaries []gatewayv1.Gateway, image string, now time.Time) (bool, string, error) {
+	var readySince time.Time
+	for i := range canaries {
+		gw := &canaries[i]
+		dp := gatewayDataplane(gw)
+		workload, err := provisionedWorkload(ctx, reader, dp)
+		if err != nil {
+			return false, "", err
+		}
+		if workload == nil {
+			continue
+		}
+		if workloadImage(workload) != image {
+			return false, fmt.Sprintf("waiting for the canary gateway %s to roll out", client.ObjectKeyFromObject(gw)), nil
+		}
+		if ready, message := workloadRolledOut(workload); !ready {
+			return false, message, nil
+		}
+
+		pods := &corev1.PodList{}
+		if err := reader.List(ctx, pods, client.InNamespace(dp.namespace), client.MatchingLabels(dp.selector)); err != nil {
+			return false, "", fmt.Errorf("error listing the canary dataplane pods: %w", err)
+		}
+		for _, pod := range pods.Items {
+			if !pod.GetDeletionTimestamp().IsZero() || containerImage(pod.Spec) != image {
+				continue
+			}
+			since, ready := podReadySince(&pod)
+			if !ready {
+				return false, fmt.Sprintf("the canary dataplane pod %s of the gateway %s is not ready", pod.GetName(), client.ObjectKeyFromObject(gw)), nil
+			}
+			if since.After(readySince) {
+				readySince = since
+			}
+		}
+	}
+	if readySince.IsZero() {
+		return false, "waiting for the canary dataplane pods to run the image", nil
+	}
+	if remaining := options.SoakPeriod - now.Sub(readySince); remaining > 0 {
+		return false, fmt.Sprintf("the canary gateways are soaking the image, %s remaining", remaining.Round(time.Second)), nil
+	}
+	return true, "", nil
+}
+
+// podReadySince returns the time since the pod is ready, false when it is not
+func podReadySince(pod *corev1.Pod) (time.Time, bool) {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.LastTransitionTime.Time, condition.Status == corev1.ConditionTrue
+		}
+	}
+	return time.Time{}, false
+}
+
+// ClassRolloutStatus returns a GatewayClassOptions.RolloutStatusFunc reporting
+// the rollout of the ClassImageFunc image to the dataplanes of the class, and
+// the soak of its canary Gateways with the Canary. The classes without an image
+// have no rollout status
+func ClassRolloutStatus(c client.Client, options Options) gatewayclass.RolloutStatusFunc {
+	return func(ctx context.Context, class *gatewayv1.GatewayClass) (*gatewayclass.RolloutStatus, error) {
+		if options.ClassImageFunc == nil {
+			return nil, nil
+		}
+		image, err := classImage(ctx, c, class, options.ClassImageFunc)
+		if err != nil || image == "" {
+			return nil, err
+		}
+		gateways, err := classGateways(ctx, c, class.GetName())
+		if err != nil {
+			return nil, err
+		}
+
+		dataplanes := []dataplane{classDataplane(class.GetName(), options.MergedNamespace)}
+		if !options.MergeGateways {
+			dataplanes = make([]dataplane, 0, len(gateways))
+			for i := range gateways {
+				dataplanes = append(dataplanes, gatewayDataplane(&gateways[i]))
+			}
+		}
+		var provisioned, rolledOut int
+		for _, dp := range dataplanes {
+			workload, err := provisionedWorkload(ctx, c, dp)
+			if err != nil {
+				return nil, err
+			}
+			if workload == nil {
+				continue
+			}
+			provisioned++
+			if ready, _ := workloadRolledOut(workload); ready && workloadImage(workload) == image {
+				rolledOut++
+			}
+		}
+		if rolledOut == provisioned {
+			return &gatewayclass.RolloutStatus{
+				RolledOut: true,
+				Reason:    RolloutReasonRolledOut,
+				Message:   fmt.Sprintf("The dataplanes rolled out the image %s", image),
+			}, nil
+		}
+
+		message := fmt.Sprintf("%d of %d dataplanes rolled out the image %s", rolledOut, provisioned, image)
+		if options.Canary != nil && !options.MergeGateways {
+			soaked, progress, err := canaryProgress(ctx, c, options.Canary, canaryGateways(class.GetName(), image, gateways, options.Canary), image, time.Now())
+			if err != nil {
+				return nil, err
+			}
+			if !soaked {
+				return &gatewayclass.RolloutStatus{
+					Reason:  RolloutReasonCanary,
+					Message: fmt.Sprintf("%s, %s", message, progress),
+				}, nil
+			}
+		}
+		return &gatewayclass.RolloutStatus{
+			Reason:  RolloutReasonProgressing,
+			Message: message,
+		}, nil
+	}
+}
+
+// WatchClassRollouts reconciles the GatewayClasses of c, like the kgame
+// GatewayClass controller, when the provisioned Deployments and DaemonSets of
+// their Gateways change, so the ClassRolloutStatus follows the rollout
+func WatchClassRollouts(mgr manager.Manager, c controller.Controller) error {
+	for _, obj := range []client.Object{&appsv1.Deployment{}, &appsv1.DaemonSet{}} {
+		if err := c.Watch(source.Kind(mgr.GetCache(), obj,
+			handler.EnqueueRequestsFromMapFunc(classOfWorkload(mgr.GetClient())))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// classOfWorkload enqueues the GatewayClass of a provisioned workload, the class
+// of its Gateway or of its shared dataplane
+func classOfWorkload(reader client.Reader) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		if value, ok := obj.GetLabels()[GatewayClassLabel]; ok {
+			class, err := classOfLabel(ctx, reader, value)
+			if err != nil || class == "" {
+				return nil
+			}
+			return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: class}}}
+		}
+		owner := metav1.GetControllerOf(obj)
+		if owner == nil || owner.Kind != "Gateway" {
+			return nil
+		}
+		gw := &gatewayv1.Gateway{}
+		if err := reader.Get(ctx, types.NamespacedName{Namespace: obj.GetNamespace(), Name: owner.Name}, gw); err != nil {
+			return nil
+		}
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: string(gw.Spec.GatewayClassName)}}}
+	}
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioner
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+
+	"github.com/rikatz/kgame/pkg/controllers/gateway"
+	"github.com/rikatz/kgame/pkg/hooks"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// ConfigChecksumAnnotation is the annotation set on the dataplane pod template
+// with the checksum of its configuration, with RolloutOnConfigChange
+const ConfigChecksumAnnotation = "kgame.io/config-checksum"
+
+// RenderedConfig is a gateway.DataplaneConfig rendered on the ConfigMap, or the
+// Secret with ConfigSecret, provisioned for the Gateway. The keys are the file
+// names on the ConfigMountPath
+type RenderedConfig map[string]string
+
+// configRenderer is a Translator rendering the RenderedConfig translated by the
+// wrapped Translator on the provisioned ConfigMap, or Secret
+type configRenderer struct {
+	translator gateway.Translator
+	client     client.Client
+	options    Options
+}
+
+// RenderConfig returns a GatewayOptions.Translator rendering the configuration
+// translated by translator, which must be a RenderedConfig, on the ConfigMap, or
+// Secret, provisioned for each Gateway, before calling the translator Apply. The
+// rendered keys are applied with their own field manager, so the TemplateFunc
+// may still set other keys. With RolloutOnConfigChange the dataplane pods are
+// replaced when the configuration changes. Not supported with MergeGateways
+func RenderConfig(c client.Client, options Options, translator gateway.Translator) (gateway.Translator, error) {
+	if options.MergeGateways {
+		return nil, fmt.Errorf("the rendered configuration is not supported with MergeGateways")
+	}
+	return &configRenderer{translator: translator, client: c, options: options}, nil
+}
+
+func (c *configRenderer) Translate(ctx context.Context, model gateway.Model) (gateway.DataplaneConfig, error) {
+	return c.translator.Translate(ctx, model)
+}
+
+func (c *configRenderer) Apply(ctx context.Context, gw *gatewayv1.Gateway, config gateway.DataplaneConfig) (gateway.ProgramResult, error) {
+	rendered, ok := config.(RenderedConfig)
+	if !ok {
+		return gateway.ProgramResult{}, hooks.Terminal(fmt.Errorf("the rendered configuration is a %T, not a RenderedConfig", config))
+	}
+
+	dp := gatewayDataplane(gw)
+	objectMeta := metav1.ObjectMeta{
+		Name:      dp.name,
+		Namespace: dp.namespace,
+		Labels:    maps.Clone(dp.selector),
+	}
+	var obj client.Object
+	if c.options.ConfigSecret {
+		secret := &corev1.Secret{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+			ObjectMeta: objectMeta,
+			Data:       make(map[string][]byte, len(rendered)),
+		}
+		for name, content := range rendered {
+			secret.Data[name] = []byte(content)
+		}
+		obj = secret
+	} else {
+		obj = &corev1.ConfigMap{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+			ObjectMeta: objectMeta,
+			Data:       maps.Clone(rendered),
+		}
+	}
+	// The owner is set as by the provisioner, so the configuration rendered
+	// before the dataplane is provisioned is not orphaned
+	if err := controllerutil.SetControllerReference(gw, obj, c.client.Scheme()); err != nil {
+		return gateway.ProgramResult{}, fmt.Errorf("error setting the owner of the rendered configuration: %w", err)
+	}
+
+	fieldManager := c.options.FieldManager
+	if fieldManager == "" {
+		fieldManager = DefaultFieldManager
+	}
+	if err := c.client.Patch(ctx, obj, client.Apply, client.FieldOwner(fieldManager+"-config"), client.ForceOwnership); err != nil {
+		return gateway.ProgramResult{}, fmt.Errorf("error rendering the configuration of %s: %w", dp.name, err)
+	}
+	return c.translator.Apply(ctx, gw, config)
+}
+
+// configChecksum returns the checksum of the content of the applied ConfigMap,
+// or Secret, with the configuration of the dataplane
+func configChecksum(obj client.Object) (string, error) {
+	var content any
+	switch config := obj.(type) {
+	case *corev1.ConfigMap:
+		content = []any{config.Data, config.BinaryData}
+	case *corev1.Secret:
+		content = config.Data
+	}
+	// The maps are encoded with sorted keys
+	encoded, err := json.Marshal(content)
+	if err != nil {
+		return "", fmt.Errorf("error encoding the dataplane configuration: %w", err)
+	}
+	hash := sha256.Sum256(encoded)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// setConfigChecksum annotates the pod template of the workload with the checksum
+// of its configuration, so the pods are replaced when it changes
+func (r *Resources) setConfigChecksum(checksum string) {
+	var template *corev1.PodTemplateSpec
+	switch {
+	case r.Deployment != nil:
+		template = &r.Deployment.Spec.Template
+	case r.DaemonSet != nil:
+		template = &r.DaemonSet.Spec.Template
+	default:
+		return
+	}
+	if template.Annotations == nil {
+		template.Annotations = make(map[string]string, 1)
+	}
+	template.Annotations[ConfigChecksumAnnotation] = checksum
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioner
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/rikatz/kgame/pkg/hooks"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// DefaultFinalizerName is the finalizer of the Gateways whose provisioned
+// resources are not removed right away with them, when none is configured
+const DefaultFinalizerName = "kgame.io/provisioner"
+
+// DeletionPolicy is what happens to the provisioned resources when their Gateway
+// is deleted
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyDelete removes the provisioned resources with the Gateway. It
+	// is the default
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+	// DeletionPolicyOrphan keeps the provisioned resources, releasing them from the
+	// Gateway and the provisioner, like when migrating the dataplane to another
+	// controller
+	DeletionPolicyOrphan DeletionPolicy = "Orphan"
+)
+
+// DeletionOptions configures what happens to the provisioned resources of a
+// deleted Gateway
+type DeletionOptions struct {
+	// Policy of the provisioned resources. Defaults to DeletionPolicyDelete
+	Policy DeletionPolicy
+	// DrainGracePeriod keeps the dataplane running after the deletion of the
+	// Gateway was requested, with DeletionPolicyDelete, so the clients can drain.
+	// Zero removes the resources right away
+	DrainGracePeriod time.Duration
+}
+
+// validate returns an error if the deletion options are not supported
+func (o DeletionOptions) validate() error {
+	switch o.Policy {
+	case "", DeletionPolicyDelete, DeletionPolicyOrphan:
+	default:
+		return fmt.Errorf("unsupported provisioner deletion policy %q", o.Policy)
+	}
+	if o.DrainGracePeriod < 0 {
+		return fmt.Errorf("the provisioner drain grace period must not be negative")
+	}
+	return nil
+}
+
+// finalizes returns true if the deletion of the Gateway is handled by the
+// provisioner finalizer, instead of the garbage collection alone
+func (o DeletionOptions) finalizes() bool {
+	return o.Policy == DeletionPolicyOrphan || o.DrainGracePeriod > 0
+}
+
+// deletionOf returns the DeletionOptions of the Gateway class
+func (r *reconciler) deletionOf(gw *gatewayv1.Gateway) DeletionOptions {
+	if deletion, ok := r.options.ClassDeletion[gw.Spec.GatewayClassName]; ok {
+		return deletion
+	}
+	return r.options.Deletion
+}
+
+// finalize applies the DeletionOptions of a deleted Gateway, removing the
+// provisioner finalizer once the dataplane is drained, or orphaned. The
+// provisioned resources are then removed by the garbage collection
+func (r *reconciler) finalize(ctx context.Context, logger logr.Logger, gw *gatewayv1.Gateway) (reconcile.Result, error) {
+	if !r.finalizers.Contains(gw) {
+		return reconcile.Result{}, nil
+	}
+
+	deletion := r.deletionOf(gw)
+	if deletion.Policy == DeletionPolicyOrphan {
+		if err := r.orphan(ctx, logger, gw); err != nil {
+			return reconcile.Result{}, err
+		}
+	} else if remaining := time.Until(gw.GetDeletionTimestamp().Add(deletion.DrainGracePeriod)); remaining > 0 {
+		logger.V(2).Info("draining the dataplane", "remaining", remaining)
+		return reconcile.Result{RequeueAfter: remaining}, nil
+	}
+
+	if _, err := r.finalizers.Remove(ctx, logger, gw); err != nil {
+		return hooks.Result(err)
+	}
+	return reconcile.Result{}, nil
+}
+
+// orphan releases the resources provisioned for the Gateway, removing their
+// owner reference, so they are not garbage collected, and their ManagedByLabel,
+// so they are not cached and swept by the provisioner anymore
+func (r *reconciler) orphan(ctx context.Context, logger logr.Logger, gw *gatewayv1.Gateway) error {
+	dp := gatewayDataplane(gw)
+	for _, list := range r.options.provisionedLists() {
+		if err := r.client.List(ctx, list, client.InNamespace(dp.namespace), client.MatchingLabels(dp.selector)); err != nil {
+			return fmt.Errorf("error listing the provisioned resources: %w", err)
+		}
+		err := meta.EachListItem(list, func(item runtime.Object) error {
+			obj, ok := item.(client.Object)
+			if !ok || !metav1.IsControlledBy(obj, gw) {
+				return nil
+			}
+			original, ok := obj.DeepCopyObject().(client.Object)
+			if !ok {
+				return fmt.Errorf("unable to copy %T", obj)
+			}
+			obj.SetOwnerReferences(slices.DeleteFunc(obj.GetOwnerReferences(), func(ref metav1.OwnerReference) bool {
+				return ref.UID == gw.GetUID()
+			}))
+			labels := obj.GetLabels()
+			delete(labels, ManagedByLabel)
+			obj.SetLabels(labels)
+			logger.Info("orphaning a provisioned resource", "name", obj.GetName(), "kind", fmt.Sprintf("%T", obj))
+			return client.IgnoreNotFound(r.client.Patch(ctx, obj, client.MergeFrom(original)))
+		})
+		if err != nil {
+			return fmt.Errorf("error orphaning the provisioned resources: %w", err)
+		}
+	}
+	return nil
+}
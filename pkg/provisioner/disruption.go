@@ -0,0 +1,61 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioner
+
+import (
+	"fmt"
+	"maps"
+
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// DisruptionOptions configures the PodDisruptionBudget provisioned for the
+// dataplane pods of each Gateway, so they survive the node drains. The budget can
+// be set per Gateway, like from its GatewayClass or infrastructure parameters,
+// with the TemplateFunc
+type DisruptionOptions struct {
+	// MinAvailable is the number, or percentage, of dataplane pods that must be
+	// available during the voluntary disruptions
+	MinAvailable *intstr.IntOrString
+	// MaxUnavailable is the number, or percentage, of dataplane pods that can be
+	// unavailable during the voluntary disruptions. Only one of MinAvailable and
+	// MaxUnavailable can be set
+	MaxUnavailable *intstr.IntOrString
+}
+
+// validate returns an error if the PodDisruptionBudgets cannot be provisioned
+func (o *DisruptionOptions) validate() error {
+	if (o.MinAvailable == nil) == (o.MaxUnavailable == nil) {
+		return fmt.Errorf("the disruption budget requires one of MinAvailable or MaxUnavailable")
+	}
+	return nil
+}
+
+// podDisruptionBudget returns the PodDisruptionBudget of the dataplane pods
+// selected by selector
+func podDisruptionBudget(objectMeta metav1.ObjectMeta, selector map[string]string, options *DisruptionOptions) *policyv1.PodDisruptionBudget {
+	return &policyv1.PodDisruptionBudget{
+		ObjectMeta: objectMeta,
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			Selector:       &metav1.LabelSelector{MatchLabels: maps.Clone(selector)},
+			MinAvailable:   options.MinAvailable,
+			MaxUnavailable: options.MaxUnavailable,
+		},
+	}
+}
@@ -0,0 +1,384 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/rikatz/kgame/pkg/controllers/gateway"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// DriftedAnnotation lists the fields of a provisioned resource changed out of
+// band and kept with the DriftPolicyReport, like .spec.replicas
+const DriftedAnnotation = "kgame.io/drifted-fields"
+
+// The reasons of the Gateway DataplaneDrifted condition
+const (
+	DriftReasonDrifted = "Drifted"
+	DriftReasonInSync  = "InSync"
+)
+
+// applyWithoutConflicts applies obj again without the fields of the conflicts of
+// err, owned by other managers, annotating it with them, so the other fields are
+// still applied. It returns false when the conflicting fields are not known
+func (r *reconciler) applyWithoutConflicts(ctx context.Context, obj client.Object, err error, opts []client.PatchOption) (bool, error) {
+	var fields []string
+	if status, ok := err.(apierrors.APIStatus); ok && status.Status().Details != nil {
+		for _, cause := range status.Status().Details.Causes {
+			if cause.Type == metav1.CauseTypeFieldManagerConflict && cause.Field != "" && !slices.Contains(fields, cause.Field) {
+				fields = append(fields, cause.Field)
+			}
+		}
+	}
+	if len(fields) == 0 {
+		return false, nil
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return false, fmt.Errorf("error converting %s: %w", obj.GetName(), err)
+	}
+	u := &unstructured.Unstructured{Object: content}
+	u.GetObjectKind().SetGroupVersionKind(obj.GetObjectKind().GroupVersionKind())
+	for _, field := range fields {
+		path, ok := parseFieldPath(field)
+		if !ok {
+			return false, nil
+		}
+		if _, ok := removeFieldPath(u.Object, path); !ok {
+			return false, nil
+		}
+	}
+	annotations := u.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string, 1)
+	}
+	annotations[DriftedAnnotation] = strings.Join(fields, ", ")
+	u.SetAnnotations(annotations)
+	if err := r.client.Patch(ctx, u, client.Apply, opts...); err != nil {
+		return false, err
+	}
+	return true, runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, obj)
+}
+
+// pathElement is an element of a server side apply field path, a field, a list
+// item by its keys or index, or a set value
+type pathElement struct {
+	field string
+	keys  map[string]json.RawMessage
+	value json.RawMessage
+	index int
+}
+
+// parseFieldPath parses the field of a server side apply conflict, like
+// .spec.template.spec.containers[name="dataplane"].image
+func parseFieldPath(path string) ([]pathElement, bool) {
+	var elements []pathElement
+	for path != "" {
+		switch path[0] {
+		case '.':
+			end := strings.IndexAny(path[1:], ".[") + 1
+			if end == 0 {
+				end = len(path)
+			}
+			elements = append(elements, pathElement{field: path[1:end], index: -1})
+			path = path[end:]
+		case '[':
+			end := selectorEnd(path)
+			if end < 0 {
+				return nil, false
+			}
+			element, ok := parseSelector(path[1:end])
+			if !ok {
+				return nil, false
+			}
+			elements = append(elements, element)
+			path = path[end+1:]
+		default:
+			return nil, false
+		}
+	}
+	return elements, len(elements) > 0
+}
+
+// selectorEnd returns the index of the bracket closing the selector starting the
+// path, -1 when it is not closed
+func selectorEnd(path string) int {
+	quoted := false
+	for i := 1; i < len(path); i++ {
+		switch {
+		case quoted && path[i] == '\\':
+			i++
+		case path[i] == '"':
+			quoted = !quoted
+		case !quoted && path[i] == ']':
+			return i
+		}
+	}
+	return -1
+}
+
+// parseSelector parses a list item selector, like name="dataplane", =value or 0
+func parseSelector(selector string) (pathElement, bool) {
+	element := pathElement{index: -1}
+	if value, ok := strings.CutPrefix(selector, "="); ok {
+		element.value = json.RawMessage(value)
+		return element, json.Valid(element.value)
+	}
+	if index, err := strconv.Atoi(selector); err == nil {
+		element.index = index
+		return element, index >= 0
+	}
+	// The key values are JSON, and may contain commas
+	decoder := json.NewDecoder(strings.NewReader("{" + quoteKeys(selector) + "}"))
+	if err := decoder.Decode(&element.keys); err != nil {
+		return element, false
+	}
+	return element, len(element.keys) > 0
+}
+
+// quoteKeys turns the key selector name="dataplane",port=80 into the JSON
+// members "name":"dataplane","port":80. The commas of the object and array
+// values do not split the keys
+func quoteKeys(selector string) string {
+	var b strings.Builder
+	quoted, key, depth := false, true, 0
+	for i := 0; i < len(selector); i++ {
+		c := selector[i]
+		switch {
+		case quoted && c == '\\' && i+1 < len(selector):
+			b.WriteByte(c)
+			i++
+			c = selector[i]
+		case c == '"':
+			quoted = !quoted
+		case !quoted && (c == '{' || c == '['):
+			depth++
+		case !quoted && (c == '}' || c == ']'):
+			depth--
+		case !quoted && key && c == '=':
+			b.WriteString(`":`)
+			key = false
+			continue
+		case !quoted && !key && depth == 0 && c == ',':
+			b.WriteString(`,"`)
+			key = true
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return `"` + b.String()
+}
+
+// matches returns true if the list item at index i is selected by the element
+func (e pathElement) matches(i int, item any) bool {
+	switch {
+	case e.index >= 0:
+		return i == e.index
+	case e.value != nil:
+		return jsonEqual(item, e.value)
+	}
+	fields, ok := item.(map[string]any)
+	if !ok {
+		return false
+	}
+	for key, value := range e.keys {
+		if !jsonEqual(fields[key], value) {
+			return false
+		}
+	}
+	return true
+}
+
+// jsonEqual returns true if the JSON encoding of value is raw
+func jsonEqual(value any, raw json.RawMessage) bool {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return false
+	}
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, raw); err != nil {
+		return false
+	}
+	return bytes.Equal(encoded, compacted.Bytes())
+}
+
+// removeFieldPath removes the path from node, returning the updated node, false
+// when the path does not match its structure. The paths not set are ignored
+func removeFieldPath(node any, path []pathElement) (any, bool) {
+	element, last := path[0], len(path) == 1
+	switch node := node.(type) {
+	case map[string]any:
+		if element.field == "" {
+			return node, false
+		}
+		child, ok := node[element.field]
+		if !ok {
+			// The field names are not escaped, so the dots of the keys, like
+			// the ones of the labels, split them
+			if !last && path[1].field != "" {
+				joined := pathElement{field: element.field + "." + path[1].field, index: -1}
+				return removeFieldPath(node, append([]pathElement{joined}, path[2:]...))
+			}
+			return node, true
+		}
+		if last {
+			delete(node, element.field)
+			return node, true
+		}
+		child, ok = removeFieldPath(child, path[1:])
+		node[element.field] = child
+		return node, ok
+	case []any:
+		if element.field != "" {
+			return node, false
+		}
+		for i, item := range node {
+			if !element.matches(i, item) {
+				continue
+			}
+			if last {
+				return slices.Delete(node, i, i+1), true
+			}
+			child, ok := removeFieldPath(item, path[1:])
+			node[i] = child
+			return node, ok
+		}
+		return node, true
+	}
+	return node, false
+}
+
+// DriftStatus returns a gateway.DriftStatusFunc reporting the fields of the
+// provisioned resources of the dataplane of the Gateway changed out of band,
+// kept with the DriftPolicyReport. The Gateways have no drift status with the
+// DriftPolicyRevert, as the changes are reverted
+func DriftStatus(c client.Client, options Options) gateway.DriftStatusFunc {
+	return func(ctx context.Context, gw *gatewayv1.Gateway) (*gateway.DriftStatus, error) {
+		if options.DriftPolicy != DriftPolicyReport {
+			return nil, nil
+		}
+		listOptions := []client.ListOption{client.InNamespace(gw.GetNamespace()), client.MatchingLabels(selectorLabels(gw))}
+		if options.MergeGateways {
+			listOptions = []client.ListOption{client.InNamespace(options.MergedNamespace), client.MatchingLabels(mergedSelectorLabels(string(gw.Spec.GatewayClassName)))}
+		}
+		var drifted []string
+		for _, list := range options.provisionedLists() {
+			if err := c.List(ctx, list, listOptions...); err != nil {
+				return nil, fmt.Errorf("error listing the provisioned resources: %w", err)
+			}
+			err := meta.EachListItem(list, func(item runtime.Object) error {
+				obj, ok := item.(client.Object)
+				if !ok {
+					return nil
+				}
+				fields, ok := obj.GetAnnotations()[DriftedAnnotation]
+				if !ok || (!options.MergeGateways && !metav1.IsControlledBy(obj, gw)) {
+					return nil
+				}
+				gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+				if err != nil {
+					return err
+				}
+				drifted = append(drifted, fmt.Sprintf("%s %s: %s", gvk.Kind, obj.GetName(), fields))
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+		if len(drifted) == 0 {
+			return &gateway.DriftStatus{
+				Reason:  DriftReasonInSync,
+				Message: "The provisioned resources match the Gateway",
+			}, nil
+		}
+		slices.Sort(drifted)
+		return &gateway.DriftStatus{
+			Drifted: true,
+			Reason:  DriftReasonDrifted,
+			Message: fmt.Sprintf("The provisioned resources were changed out of band: %s", strings.Join(drifted, "; ")),
+		}, nil
+	}
+}
+
+// WatchDrift reconciles the Gateways of c, like the kgame Gateway controller,
+// when the DriftedAnnotation of their provisioned resources changes, so the
+// DriftStatus follows the drift
+func WatchDrift(mgr manager.Manager, c controller.Controller, options Options) error {
+	changed := predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			_, ok := e.Object.GetAnnotations()[DriftedAnnotation]
+			return ok
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return e.ObjectOld.GetAnnotations()[DriftedAnnotation] != e.ObjectNew.GetAnnotations()[DriftedAnnotation]
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			_, ok := e.Object.GetAnnotations()[DriftedAnnotation]
+			return ok
+		},
+		GenericFunc: func(event.GenericEvent) bool {
+			return false
+		},
+	}
+	for _, list := range options.provisionedLists() {
+		gvk, err := apiutil.GVKForObject(list, mgr.GetScheme())
+		if err != nil {
+			return err
+		}
+		gvk.Kind = strings.TrimSuffix(gvk.Kind, "List")
+		item, err := mgr.GetScheme().New(gvk)
+		if err != nil {
+			return err
+		}
+		obj, ok := item.(client.Object)
+		if !ok {
+			return fmt.Errorf("%s is not an object", gvk)
+		}
+		if err := c.Watch(source.Kind(mgr.GetCache(), obj,
+			handler.EnqueueRequestForOwner(mgr.GetScheme(), mgr.GetRESTMapper(), &gatewayv1.Gateway{}, handler.OnlyControllerOwner()),
+			changed)); err != nil {
+			return err
+		}
+		if err := c.Watch(source.Kind(mgr.GetCache(), obj,
+			handler.EnqueueRequestsFromMapFunc(gatewaysOfMergedDataplane(mgr.GetClient())),
+			changed)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,269 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioner
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParseFieldPath(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		want []pathElement
+		ok   bool
+	}{
+		{
+			name: "fields",
+			path: ".spec.replicas",
+			want: []pathElement{{field: "spec", index: -1}, {field: "replicas", index: -1}},
+			ok:   true,
+		},
+		{
+			name: "key selector",
+			path: `.spec.template.spec.containers[name="dataplane"].image`,
+			want: []pathElement{
+				{field: "spec", index: -1},
+				{field: "template", index: -1},
+				{field: "spec", index: -1},
+				{field: "containers", index: -1},
+				{keys: map[string]json.RawMessage{"name": json.RawMessage(`"dataplane"`)}, index: -1},
+				{field: "image", index: -1},
+			},
+			ok: true,
+		},
+		{
+			name: "several keys",
+			path: `.spec.ports[port=80,protocol="TCP"]`,
+			want: []pathElement{
+				{field: "spec", index: -1},
+				{field: "ports", index: -1},
+				{keys: map[string]json.RawMessage{"port": json.RawMessage(`80`), "protocol": json.RawMessage(`"TCP"`)}, index: -1},
+			},
+			ok: true,
+		},
+		{
+			name: "quoted brackets and commas",
+			path: `.args[name="a],b"]`,
+			want: []pathElement{
+				{field: "args", index: -1},
+				{keys: map[string]json.RawMessage{"name": json.RawMessage(`"a],b"`)}, index: -1},
+			},
+			ok: true,
+		},
+		{
+			name: "escaped quotes",
+			path: `.env[name="say \"hi\"]"].value`,
+			want: []pathElement{
+				{field: "env", index: -1},
+				{keys: map[string]json.RawMessage{"name": json.RawMessage(`"say \"hi\"]"`)}, index: -1},
+				{field: "value", index: -1},
+			},
+			ok: true,
+		},
+		{
+			name: "set value",
+			path: `.metadata.finalizers[="kgame.io/finalizer"]`,
+			want: []pathElement{
+				{field: "metadata", index: -1},
+				{field: "finalizers", index: -1},
+				{value: json.RawMessage(`"kgame.io/finalizer"`), index: -1},
+			},
+			ok: true,
+		},
+		{
+			name: "index",
+			path: ".spec.containers[0].image",
+			want: []pathElement{
+				{field: "spec", index: -1},
+				{field: "containers", index: -1},
+				{index: 0},
+				{field: "image", index: -1},
+			},
+			ok: true,
+		},
+		{
+			name: "dotted label key",
+			path: ".metadata.labels.app.kubernetes.io/name",
+			want: []pathElement{
+				{field: "metadata", index: -1},
+				{field: "labels", index: -1},
+				{field: "app", index: -1},
+				{field: "kubernetes", index: -1},
+				{field: "io/name", index: -1},
+			},
+			ok: true,
+		},
+		{name: "empty", path: "", ok: false},
+		{name: "no leading dot", path: "spec.replicas", ok: false},
+		{name: "unclosed selector", path: `.containers[name="dataplane"`, ok: false},
+		{name: "negative index", path: ".containers[-1]", ok: false},
+		{name: "invalid set value", path: ".finalizers[=value]", ok: false},
+		{name: "invalid key value", path: ".containers[name=dataplane]", ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseFieldPath(tt.path)
+			if ok != tt.ok {
+				t.Fatalf("parseFieldPath(%q) ok = %v, want %v", tt.path, ok, tt.ok)
+			}
+			if ok && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseFieldPath(%q) = %+v, want %+v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuoteKeys(t *testing.T) {
+	tests := []struct {
+		selector string
+		want     string
+	}{
+		{selector: `name="dataplane"`, want: `"name":"dataplane"`},
+		{selector: `name="dataplane",port=80`, want: `"name":"dataplane","port":80`},
+		{selector: `name="a=b,c"`, want: `"name":"a=b,c"`},
+		{selector: `name="say \"hi\""`, want: `"name":"say \"hi\""`},
+		{selector: `key={"a":1,"b":[1,2]}`, want: `"key":{"a":1,"b":[1,2]}`},
+		{selector: `key={"a":1,"b":2},port=80`, want: `"key":{"a":1,"b":2},"port":80`},
+	}
+	for _, tt := range tests {
+		if got := quoteKeys(tt.selector); got != tt.want {
+			t.Errorf("quoteKeys(%q) = %q, want %q", tt.selector, got, tt.want)
+		}
+	}
+}
+
+func TestRemoveFieldPath(t *testing.T) {
+	tests := []struct {
+		name string
+		node string
+		path string
+		want string
+		ok   bool
+	}{
+		{
+			name: "field",
+			node: `{"spec":{"replicas":2,"paused":true}}`,
+			path: ".spec.replicas",
+			want: `{"spec":{"paused":true}}`,
+			ok:   true,
+		},
+		{
+			name: "key selector",
+			node: `{"spec":{"template":{"spec":{"containers":[{"name":"init","image":"a"},{"name":"dataplane","image":"b"}]}}}}`,
+			path: `.spec.template.spec.containers[name="dataplane"].image`,
+			want: `{"spec":{"template":{"spec":{"containers":[{"name":"init","image":"a"},{"name":"dataplane"}]}}}}`,
+			ok:   true,
+		},
+		{
+			name: "list item",
+			node: `{"ports":[{"port":80,"protocol":"TCP"},{"port":80,"protocol":"UDP"}]}`,
+			path: `.ports[port=80,protocol="UDP"]`,
+			want: `{"ports":[{"port":80,"protocol":"TCP"}]}`,
+			ok:   true,
+		},
+		{
+			name: "JSON key",
+			node: `{"items":[{"key":{"a":1,"b":2},"v":1},{"key":{"a":2,"b":2},"v":2}]}`,
+			path: `.items[key={"a":2,"b":2}].v`,
+			want: `{"items":[{"key":{"a":1,"b":2},"v":1},{"key":{"a":2,"b":2}}]}`,
+			ok:   true,
+		},
+		{
+			name: "set value",
+			node: `{"metadata":{"finalizers":["a","b"]}}`,
+			path: `.metadata.finalizers[="b"]`,
+			want: `{"metadata":{"finalizers":["a"]}}`,
+			ok:   true,
+		},
+		{
+			name: "index",
+			node: `{"args":["a","b","c"]}`,
+			path: ".args[1]",
+			want: `{"args":["a","c"]}`,
+			ok:   true,
+		},
+		{
+			name: "dotted label key",
+			node: `{"metadata":{"labels":{"app.kubernetes.io/name":"gw","team":"a"}}}`,
+			path: ".metadata.labels.app.kubernetes.io/name",
+			want: `{"metadata":{"labels":{"team":"a"}}}`,
+			ok:   true,
+		},
+		{
+			name: "not set",
+			node: `{"spec":{"replicas":2}}`,
+			path: `.spec.containers[name="dataplane"].image`,
+			want: `{"spec":{"replicas":2}}`,
+			ok:   true,
+		},
+		{
+			name: "no matching item",
+			node: `{"containers":[{"name":"init"}]}`,
+			path: `.containers[name="dataplane"].image`,
+			want: `{"containers":[{"name":"init"}]}`,
+			ok:   true,
+		},
+		{
+			name: "selector on a map",
+			node: `{"spec":{"replicas":2}}`,
+			path: ".spec[0]",
+			ok:   false,
+		},
+		{
+			name: "field of a list",
+			node: `{"args":["a"]}`,
+			path: ".args.name",
+			ok:   false,
+		},
+		{
+			name: "field of a value",
+			node: `{"spec":{"replicas":2}}`,
+			path: ".spec.replicas.value",
+			ok:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var node any
+			if err := json.Unmarshal([]byte(tt.node), &node); err != nil {
+				t.Fatal(err)
+			}
+			path, ok := parseFieldPath(tt.path)
+			if !ok {
+				t.Fatalf("parseFieldPath(%q) failed", tt.path)
+			}
+			got, ok := removeFieldPath(node, path)
+			if ok != tt.ok {
+				t.Fatalf("removeFieldPath(%q) ok = %v, want %v", tt.path, ok, tt.ok)
+			}
+			if !ok {
+				return
+			}
+			var want any
+			if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				encoded, _ := json.Marshal(got)
+				t.Errorf("removeFieldPath(%q) = %s, want %s", tt.path, encoded, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,278 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioner
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/rikatz/kgame/pkg/hooks"
+	"github.com/rikatz/kgame/pkg/indexes"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// GatewayClassLabel is the label set on the resources of the dataplane shared
+// by the Gateways of a GatewayClass, with MergeGateways, with the class name
+const GatewayClassLabel = "kgame.io/gateway-class"
+
+// mergedSelectorLabels are the labels selecting the resources of the dataplane
+// shared by the Gateways of the class
+func mergedSelectorLabels(class string) map[string]string {
+	return map[string]string{
+		GatewayClassLabel: labelValue(class),
+		ManagedByLabel:    ManagedByValue,
+	}
+}
+
+// classDataplane returns the dataplane shared by the Gateways of the class, on
+// the namespace
+func classDataplane(class, namespace string) dataplane {
+	return dataplane{
+		name:      dnsLabelName(class),
+		namespace: namespace,
+		selector:  mergedSelectorLabels(class),
+	}
+}
+
+// classGateways returns the Gateways of the class, from the oldest to the newest,
+// which is the order their listeners are merged. The reader must have the
+// GatewayClassName index
+func classGateways(ctx context.Context, reader client.Reader, class string) ([]gatewayv1.Gateway, error) {
+	gateways := &gatewayv1.GatewayList{}
+	if err := reader.List(ctx, gateways, client.MatchingFields{indexes.GatewayClassName: class}); err != nil {
+		return nil, fmt.Errorf("error listing gateways: %w", err)
+	}
+	result := gateways.Items
+	slices.SortFunc(result, func(a, b gatewayv1.Gateway) int {
+		if c := a.GetCreationTimestamp().Compare(b.GetCreationTimestamp().Time); c != 0 {
+			return c
+		}
+		return strings.Compare(client.ObjectKeyFromObject(&a).String(), client.ObjectKeyFromObject(&b).String())
+	})
+	return result, nil
+}
+
+// listenerConflicts returns the listeners that cannot be merged on the shared
+// dataplane, by Gateway, with the conflict message. A listener conflicts when its
+// port is used by a listener of an older Gateway with another protocol, or with
+// TCP or UDP, which cannot share the port. The Gateways not accepted, or being
+// deleted, do not claim their ports, as they are not merged. The gateways are
+// sorted by age
+func listenerConflicts(gateways []gatewayv1.Gateway) map[types.NamespacedName]map[gatewayv1.SectionName]string {
+	type claim struct {
+		gateway  types.NamespacedName
+		protocol gatewayv1.ProtocolType
+	}
+	claims := make(map[gatewayv1.PortNumber]claim)
+	conflicts := make(map[types.NamespacedName]map[gatewayv1.SectionName]string)
+	for i := range gateways {
+		key := client.ObjectKeyFromObject(&gateways[i])
+		merged := gateways[i].GetDeletionTimestamp().IsZero() && meta.IsStatusConditionTrue(gateways[i].Status.Conditions, string(gatewayv1.GatewayConditionAccepted))
+		for _, listener := range gateways[i].Spec.Listeners {
+			existing, ok := claims[listener.Port]
+			if !ok {
+				if merged {
+					claims[listener.Port] = claim{gateway: key, protocol: listener.Protocol}
+				}
+				continue
+			}
+			if existing.gateway == key {
+				continue
+			}
+			if existing.protocol == listener.Protocol && listener.Protocol != gatewayv1.TCPProtocolType && listener.Protocol != gatewayv1.UDPProtocolType {
+				continue
+			}
+			if conflicts[key] == nil {
+				conflicts[key] = make(map[gatewayv1.SectionName]string)
+			}
+			conflicts[key][listener.Name] = fmt.Sprintf("port %d is used by the %s listeners of the Gateway %s on the shared dataplane", listener.Port, existing.protocol, existing.gateway)
+		}
+	}
+	return conflicts
+}
+
+// ConflictingListeners returns a function returning the listeners of a Gateway
+// that cannot be merged on the dataplane shared by the Gateways of its class,
+// with MergeGateways, by listener name with the conflict message. The Gateway
+// controller rejects them, so the status of each Gateway reflects the listeners
+// served by the shared dataplane
+func ConflictingListeners(reader client.Reader) func(ctx context.Context, gw *gatewayv1.Gateway) (map[gatewayv1.SectionName]string, error) {
+	return func(ctx context.Context, gw *gatewayv1.Gateway) (map[gatewayv1.SectionName]string, error) {
+		gateways, err := classGateways(ctx, reader, string(gw.Spec.GatewayClassName))
+		if err != nil {
+			return nil, err
+		}
+		return listenerConflicts(gateways)[client.ObjectKeyFromObject(gw)], nil
+	}
+}
+
+// mergedReconciler provisions the dataplane shared by the accepted Gateways of
+// each managed GatewayClass, with MergeGateways. The resources are controlled by
+// the GatewayClass, on the MergedNamespace
+type mergedReconciler struct {
+	*reconciler
+
+	mu sync.Mutex
+	// conflicts are the last listener conflicts of each class, so the Gateways
+	// are reconciled again by the Gateway controller when they change
+	conflicts map[string]map[types.NamespacedName]map[gatewayv1.SectionName]string
+}
+
+func (r *mergedReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	logger := r.logger.WithValues("gatewayclass", req.Name)
+
+	class := &gatewayv1.GatewayClass{}
+	if err := r.client.Get(ctx, req.NamespacedName, class); err != nil {
+		// The provisioned resources of a deleted GatewayClass are garbage collected
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+	gateways, err := classGateways(ctx, r.client, class.GetName())
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	conflicts := listenerConflicts(gateways)
+	if err := r.triggerConflicts(ctx, class.GetName(), gateways, conflicts); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	dp := classDataplane(class.GetName(), r.options.MergedNamespace)
+	for i := range gateways {
+		gw := &gateways[i]
+		if !gw.GetDeletionTimestamp().IsZero() || !meta.IsStatusConditionTrue(gw.Status.Conditions, string(gatewayv1.GatewayConditionAccepted)) {
+			continue
+		}
+		for _, listener := range gw.Spec.Listeners {
+			if _, conflicted := conflicts[client.ObjectKeyFromObject(gw)][listener.Name]; !conflicted {
+				dp.listeners = append(dp.listeners, listener)
+			}
+		}
+	}
+	// The TemplateFunc and MutateFuncs receive a Gateway merging the listeners
+	// of the class, named after it
+	merged := &gatewayv1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: dp.name, Namespace: dp.namespace},
+		Spec: gatewayv1.GatewaySpec{
+			GatewayClassName: gatewayv1.ObjectName(class.GetName()),
+			Listeners:        dp.listeners,
+		},
+	}
+	if len(dp.listeners) == 0 || !class.GetDeletionTimestamp().IsZero() {
+		if err := r.applyExtra(ctx, logger, merged, class, dp, nil); err != nil {
+			return hooks.Result(err)
+		}
+		return reconcile.Result{}, r.remove(ctx, logger, class, dp, r.options.provisionedLists())
+	}
+	return r.provision(ctx, logger, merged, class, dp)
+}
+
+// triggerConflicts reconciles the Gateways of the class again on the Gateway
+// controller when the listener conflicts of the class changed since the last
+// reconciliation, so their listener conditions are updated
+func (r *mergedReconciler) triggerConflicts(ctx context.Context, class string, gateways []gatewayv1.Gateway, conflicts map[types.NamespacedName]map[gatewayv1.SectionName]string) error {
+	if r.options.GatewayTrigger == nil {
+		return nil
+	}
+	r.mu.Lock()
+	last, ok := r.conflicts[class]
+	r.conflicts[class] = conflicts
+	r.mu.Unlock()
+	if ok && reflect.DeepEqual(last, conflicts) {
+		return nil
+	}
+
+	// Both the Gateways conflicting now and before are reconciled again
+	keys := make(map[types.NamespacedName]struct{}, len(conflicts)+len(last))
+	for key := range maps.Keys(conflicts) {
+		keys[key] = struct{}{}
+	}
+	for key := range maps.Keys(last) {
+		keys[key] = struct{}{}
+	}
+	for i := range gateways {
+		if _, ok := keys[client.ObjectKeyFromObject(&gateways[i])]; !ok {
+			continue
+		}
+		select {
+		case r.options.GatewayTrigger <- event.GenericEvent{Object: &gateways[i]}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// gatewayClassOf enqueues the GatewayClass of the Gateway
+func gatewayClassOf(_ context.Context, obj client.Object) []reconcile.Request {
+	gw, ok := obj.(*gatewayv1.Gateway)
+	if !ok {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: string(gw.Spec.GatewayClassName)}}}
+}
+
+// gatewaysOfMergedDataplane enqueues the Gateways of the class of a shared
+// dataplane resource, so their addresses and rollout status are updated
+func gatewaysOfMergedDataplane(reader client.Reader) func(ctx context.Context, obj client.Object) []reconcile.Request {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		value, ok := obj.GetLabels()[GatewayClassLabel]
+		if !ok {
+			return nil
+		}
+		class, err := classOfLabel(ctx, reader, value)
+		if err != nil || class == "" {
+			return nil
+		}
+		gateways, err := classGateways(ctx, reader, class)
+		if err != nil {
+			return nil
+		}
+		requests := make([]reconcile.Request, 0, len(gateways))
+		for i := range gateways {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&gateways[i])})
+		}
+		return requests
+	}
+}
+
+// orphanedMerged returns true if the shared dataplane obj is not controlled by
+// its GatewayClass, found by the GatewayClassLabel, or the MergeGateways is not
+// set anymore
+func (s *sweeper) orphanedMerged(ctx context.Context, obj client.Object, class string) (bool, error) {
+	if !s.reconciler.options.MergeGateways || class == "" {
+		return true, nil
+	}
+	gatewayClass := &gatewayv1.GatewayClass{}
+	if err := s.reader.Get(ctx, client.ObjectKey{Name: class}, gatewayClass); err != nil {
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, fmt.Errorf("error getting the gatewayclass of %s/%s: %w", obj.GetNamespace(), obj.GetName(), err)
+	}
+	return !metav1.IsControlledBy(obj, gatewayClass), nil
+}
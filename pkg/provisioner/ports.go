@@ -0,0 +1,387 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioner
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// ExposesHostPorts returns true if the dataplane pods use the listener ports on
+// their nodes, with ModeDaemonSet and HostNetwork or HostPorts
+func (o Options) ExposesHostPorts() bool {
+	return o.Mode == ModeDaemonSet && (o.HostNetwork || o.HostPorts)
+}
+
+// hostPort is a port used by a pod on its node
+type hostPort struct {
+	port     int32
+	protocol corev1.Protocol
+}
+
+// listenerHostPort returns the port the listener uses on the nodes
+func listenerHostPort(listener gatewayv1.Listener) hostPort {
+	protocol := corev1.ProtocolTCP
+	if listener.Protocol == gatewayv1.UDPProtocolType {
+		protocol = corev1.ProtocolUDP
+	}
+	return hostPort{port: int32(listener.Port), protocol: protocol}
+}
+
+// hostPorts returns the ports the pods of spec use on their nodes
+func hostPorts(spec corev1.PodSpec) map[hostPort]struct{} {
+	ports := make(map[hostPort]struct{})
+	for _, container := range spec.Containers {
+		for _, port := range container.Ports {
+			protocol := port.Protocol
+			if protocol == "" {
+				protocol = corev1.ProtocolTCP
+			}
+			switch {
+			case port.HostPort != 0:
+				ports[hostPort{port: port.HostPort, protocol: protocol}] = struct{}{}
+			case spec.HostNetwork:
+				ports[hostPort{port: port.ContainerPort, protocol: protocol}] = struct{}{}
+			}
+		}
+	}
+	return ports
+}
+
+// sameNodes returns false when the node selectors of a and b require different
+// values of a label, so their pods never run on the same nodes. The affinities
+// are not considered
+func sameNodes(a, b corev1.PodSpec) bool {
+	for key, value := range a.NodeSelector {
+		if other, ok := b.NodeSelector[key]; ok && other != value {
+			return false
+		}
+	}
+	return true
+}
+
+// olderDaemonSet returns true if a was created before b
+func olderDaemonSet(a, b *appsv1.DaemonSet) bool {
+	if c := a.GetCreationTimestamp().Compare(b.GetCreationTimestamp().Time); c != 0 {
+		return c < 0
+	}
+	return strings.Compare(client.ObjectKeyFromObject(a).String(), client.ObjectKeyFromObject(b).String()) < 0
+}
+
+// hostPortConflicts returns the listeners whose port is used, on the same nodes,
+// by an older provisioned DaemonSet, with the conflict message. The DaemonSets
+// selected by own are the ones of the dataplane of the listeners, which is the
+// newest when it is not provisioned yet
+func hostPortConflicts(ctx context.Context, reader client.Reader, listeners []gatewayv1.Listener, own func(*appsv1.DaemonSet) bool) (map[gatewayv1.SectionName]string, error) {
+	daemonSets := &appsv1.DaemonSetList{}
+	if err := reader.List(ctx, daemonSets, client.MatchingLabels{ManagedByLabel: ManagedByValue}); err != nil {
+		return nil, fmt.Errorf("error listing the provisioned daemonsets: %w", err)
+	}
+	var current *appsv1.DaemonSet
+	for i := range daemonSets.Items {
+		if own(&daemonSets.Items[i]) {
+			current = &daemonSets.Items[i]
+		}
+	}
+
+	conflicts := make(map[gatewayv1.SectionName]string)
+	for i := range daemonSets.Items {
+		other := &daemonSets.Items[i]
+		if own(other) {
+			continue
+		}
+		if current != nil && (!olderDaemonSet(other, current) || !sameNodes(current.Spec.Template.Spec, other.Spec.Template.Spec)) {
+			continue
+		}
+		used := hostPorts(other.Spec.Template.Spec)
+		for _, listener := range listeners {
+			if _, conflicted := conflicts[listener.Name]; conflicted {
+				continue
+			}
+			if _, ok := used[listenerHostPort(listener)]; ok {
+				conflicts[listener.Name] = fmt.Sprintf("port %d is used on the same nodes by the dataplane %s", listener.Port, client.ObjectKeyFromObject(other))
+			}
+		}
+	}
+	return conflicts, nil
+}
+
+// ConflictingHostPorts returns a function returning the listeners of a Gateway
+// whose port is used on the same nodes by the dataplane of an older Gateway, with
+// ExposesHostPorts, by listener name with the conflict message. The Gateway
+// controller rejects them, and they are not provisioned, so the pods of the
+// dataplane are not left pending
+func ConflictingHostPorts(reader client.Reader, options Options) func(ctx context.Context, gw *gatewayv1.Gateway) (map[gatewayv1.SectionName]string, error) {
+	return func(ctx context.Context, gw *gatewayv1.Gateway) (map[gatewayv1.SectionName]string, error) {
+		return hostPortConflicts(ctx, reader, gw.Spec.Listeners, func(daemonSet *appsv1.DaemonSet) bool {
+			if options.MergeGateways {
+				return daemonSet.GetLabels()[GatewayClassLabel] == labelValue(string(gw.Spec.GatewayClassName))
+			}
+			return metav1.IsControlledBy(daemonSet, gw)
+		})
+	}
+}
+
+// withoutHostPortConflicts drops the listeners of the dataplane, controlled by
+// owner, whose port is used on the same nodes by an older dataplane
+func (r *reconciler) withoutHostPortConflicts(ctx context.Context, owner client.Object, dp dataplane) (dataplane, error) {
+	conflicts, err := hostPortConflicts(ctx, r.client, dp.listeners, func(daemonSet *appsv1.DaemonSet) bool {
+		return metav1.IsControlledBy(daemonSet, owner)
+	})
+	if err != nil {
+		return dp, err
+	}
+	dp.listeners = slices.DeleteFunc(slices.Clone(dp.listeners), func(listener gatewayv1.Listener) bool {
+		_, conflicted := conflicts[listener.Name]
+		return conflicted
+	})
+	return dp, nil
+}
+
+// requestedHostPorts returns the ports the pods of daemonSet use on their nodes,
+// and the ports of the listeners of its Gateways, including the ones rejected for
+// conflicting with another dataplane
+func requestedHostPorts(ctx context.Context, reader client.Reader, daemonSet *appsv1.DaemonSet, owner *metav1.OwnerReference) (map[hostPort]struct{}, error) {
+	ports := hostPorts(daemonSet.Spec.Template.Spec)
+	var gateways []gatewayv1.Gateway
+	switch owner.Kind {
+	case "Gateway":
+		gw := gatewayv1.Gateway{}
+		if err := reader.Get(ctx, types.NamespacedName{Namespace: daemonSet.GetNamespace(), Name: owner.Name}, &gw); err != nil {
+			return nil, client.IgnoreNotFound(err)
+		}
+		gateways = append(gateways, gw)
+	case "GatewayClass":
+		var err error
+		if gateways, err = classGateways(ctx, reader, owner.Name); err != nil {
+			return nil, err
+		}
+	}
+	for i := range gateways {
+		for _, listener := range gateways[i].Spec.Listeners {
+			ports[listenerHostPort(listener)] = struct{}{}
+		}
+	}
+	return ports, nil
+}
+
+// sharesHostPort returns true if a and b have a port in common
+func sharesHostPort(a, b map[hostPort]struct{}) bool {
+	for port := range a {
+		if _, ok := b[port]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ownersOfHostPortDataplanes returns the owners of kind of the provisioned
+// DaemonSets whose host ports may conflict with the changed ones, on the same
+// nodes, so their host port conflicts are resolved again. The changed DaemonSets
+// are the versions before and after the change, as the freed ports must be
+// claimed again. With MergeGateways the Gateways of the class of a shared
+// dataplane are returned
+func ownersOfHostPortDataplanes(reader client.Reader, kind string) func(ctx context.Context, changed ...*appsv1.DaemonSet) []reconcile.Request {
+	return func(ctx context.Context, changed ...*appsv1.DaemonSet) []reconcile.Request {
+		daemonSets := &appsv1.DaemonSetList{}
+		if err := reader.List(ctx, daemonSets, client.MatchingLabels{ManagedByLabel: ManagedByValue}); err != nil {
+			return nil
+		}
+		var requests []reconcile.Request
+		for i := range daemonSets.Items {
+			daemonSet := &daemonSets.Items[i]
+			owner := metav1.GetControllerOf(daemonSet)
+			if owner == nil {
+				continue
+			}
+			if slices.ContainsFunc(changed, func(c *appsv1.DaemonSet) bool { return c.GetUID() == daemonSet.GetUID() }) {
+				continue
+			}
+			// The owner is enqueued when its ports cannot be resolved
+			requested, err := requestedHostPorts(ctx, reader, daemonSet, owner)
+			if err == nil && !slices.ContainsFunc(changed, func(c *appsv1.DaemonSet) bool {
+				return sameNodes(c.Spec.Template.Spec, daemonSet.Spec.Template.Spec) && sharesHostPort(hostPorts(c.Spec.Template.Spec), requested)
+			}) {
+				continue
+			}
+			switch {
+			case owner.Kind == kind && kind == "Gateway":
+				requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: daemonSet.GetNamespace(), Name: owner.Name}})
+			case owner.Kind == kind:
+				requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: owner.Name}})
+			case owner.Kind == "GatewayClass" && kind == "Gateway":
+				requests = append(requests, gatewaysOfMergedDataplane(reader)(ctx, daemonSet)...)
+			}
+		}
+		return requests
+	}
+}
+
+// hostPortDataplanes enqueues the owners of kind returned by
+// ownersOfHostPortDataplanes for the changed DaemonSets
+func hostPortDataplanes(reader client.Reader, kind string) handler.EventHandler {
+	owners := ownersOfHostPortDataplanes(reader, kind)
+	enqueue := func(ctx context.Context, q workqueue.TypedRateLimitingInterface[reconcile.Request], objs ...client.Object) {
+		var changed []*appsv1.DaemonSet
+		for _, obj := range objs {
+			if daemonSet, ok := obj.(*appsv1.DaemonSet); ok {
+				changed = append(changed, daemonSet)
+			}
+		}
+		if len(changed) == 0 {
+			return
+		}
+		for _, request := range owners(ctx, changed...) {
+			q.Add(request)
+		}
+	}
+	return handler.Funcs{
+		CreateFunc: func(ctx context.Context, e event.CreateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(ctx, q, e.Object)
+		},
+		UpdateFunc: func(ctx context.Context, e event.UpdateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(ctx, q, e.ObjectOld, e.ObjectNew)
+		},
+		DeleteFunc: func(ctx context.Context, e event.DeleteEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(ctx, q, e.Object)
+		},
+		GenericFunc: func(ctx context.Context, e event.GenericEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(ctx, q, e.Object)
+		},
+	}
+}
+
+// watchHostPorts enqueues the owners of kind of the provisioned DaemonSets when
+// the ports of any of them may have changed
+func watchHostPorts(b *builder.Builder, reader client.Reader, kind string) *builder.Builder {
+	return b.Watches(&appsv1.DaemonSet{}, hostPortDataplanes(reader, kind),
+		builder.WithPredicates(predicate.GenerationChangedPredicate{}))
+}
+
+// WatchHostPorts reconciles the Gateways of c, like the kgame Gateway controller,
+// when the provisioned DaemonSets change, so their ConflictingHostPorts are
+// resolved again
+func WatchHostPorts(mgr manager.Manager, c controller.Controller) error {
+	return c.Watch(source.Kind(mgr.GetCache(), client.Object(&appsv1.DaemonSet{}),
+		hostPortDataplanes(mgr.GetClient(), "Gateway"),
+		predicate.GenerationChangedPredicate{}))
+}
+
+// gatewayOfPod enqueues the Gateway of a dataplane pod, or the Gateways of its
+// class on a shared dataplane, so their node addresses are updated
+func gatewayOfPod(reader client.Reader) handler.MapFunc {
+	merged := gatewaysOfMergedDataplane(reader)
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		if value, ok := obj.GetLabels()[GatewayNameLabel]; ok {
+			name, err := gatewayOfLabel(ctx, reader, obj.GetNamespace(), value)
+			if err != nil || name == "" {
+				return nil
+			}
+			return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: obj.GetNamespace(), Name: name}}}
+		}
+		return merged(ctx, obj)
+	}
+}
+
+// ExposedPorts returns a GatewayOptions.ExposedPortsFunc returning the ports
+// each listener of the Gateway is reachable on from outside of the cluster: the
+// listener port on the nodes with ExposesHostPorts, the node port of a NodePort
+// Service or the port of a LoadBalancer Service. The ClusterIP Services are not
+// reachable
+func ExposedPorts(reader client.Reader, options Options) func(ctx context.Context, gw *gatewayv1.Gateway) (map[gatewayv1.SectionName]int32, error) {
+	return func(ctx context.Context, gw *gatewayv1.Gateway) (map[gatewayv1.SectionName]int32, error) {
+		ports := make(map[gatewayv1.SectionName]int32, len(gw.Spec.Listeners))
+		if options.ExposesHostPorts() {
+			for _, listener := range gw.Spec.Listeners {
+				ports[listener.Name] = int32(listener.Port)
+			}
+			return ports, nil
+		}
+		services, err := provisionedServices(ctx, reader, gw)
+		if err != nil {
+			return nil, err
+		}
+		for _, service := range services {
+			for _, listener := range gw.Spec.Listeners {
+				i := slices.IndexFunc(service.Spec.Ports, func(port corev1.ServicePort) bool {
+					return port.Name == fmt.Sprintf("port-%d", listener.Port)
+				})
+				if i < 0 {
+					continue
+				}
+				switch service.Spec.Type {
+				case corev1.ServiceTypeNodePort:
+					if nodePort := service.Spec.Ports[i].NodePort; nodePort != 0 {
+						ports[listener.Name] = nodePort
+					}
+				case corev1.ServiceTypeLoadBalancer:
+					ports[listener.Name] = service.Spec.Ports[i].Port
+				}
+			}
+		}
+		return ports, nil
+	}
+}
+
+// nodeAddresses returns the addresses of the nodes running the ready dataplane
+// pods behind the Service, when it is a NodePort or the pods use host ports
+func nodeAddresses(ctx context.Context, reader client.Reader, service corev1.Service) ([]gatewayv1.GatewayStatusAddress, error) {
+	if len(service.Spec.Selector) == 0 {
+		return nil, nil
+	}
+	pods := &corev1.PodList{}
+	if err := reader.List(ctx, pods, client.InNamespace(service.GetNamespace()), client.MatchingLabels(service.Spec.Selector)); err != nil {
+		return nil, fmt.Errorf("error listing the dataplane pods: %w", err)
+	}
+	var ips []string
+	for _, pod := range pods.Items {
+		if service.Spec.Type != corev1.ServiceTypeNodePort && len(hostPorts(pod.Spec)) == 0 {
+			continue
+		}
+		ready := slices.ContainsFunc(pod.Status.Conditions, func(condition corev1.PodCondition) bool {
+			return condition.Type == corev1.PodReady && condition.Status == corev1.ConditionTrue
+		})
+		if ready && pod.Status.HostIP != "" && !slices.Contains(ips, pod.Status.HostIP) {
+			ips = append(ips, pod.Status.HostIP)
+		}
+	}
+	slices.Sort(ips)
+	addresses := make([]gatewayv1.GatewayStatusAddress, 0, len(ips))
+	for _, ip := range ips {
+		addresses = append(addresses, gatewayv1.GatewayStatusAddress{Type: ptr.To(gatewayv1.IPAddressType), Value: ip})
+	}
+	return addresses, nil
+}
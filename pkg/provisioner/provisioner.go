@@ -0,0 +1,705 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The package provisioner creates and reconciles the dataplane of each accepted
+// Gateway, for managed gateway implementations: a Deployment, or a DaemonSet,
+// running the dataplane, a Service exposing the Gateway listeners and a ConfigMap,
+// or a Secret, with its configuration, which may be rendered by a Translator.
+// The resources are owned by the Gateway, so they are removed with it. With
+// MergeGateways the Gateways of each GatewayClass share a single dataplane, owned
+// by the class.
+package provisioner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/rikatz/kgame/pkg/finalizer"
+	"github.com/rikatz/kgame/pkg/hooks"
+	"github.com/rikatz/kgame/pkg/indexes"
+	"github.com/rikatz/kgame/pkg/parameters"
+	"github.com/rikatz/kgame/pkg/sharding"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// DefaultFieldManager is the field manager of the provisioned resources when
+// none is configured
+const DefaultFieldManager = "kgame-provisioner"
+
+// TemplateFunc customizes the resources provisioned for a Gateway. It receives
+// the object referenced by the Gateway spec.infrastructure.parametersRef, nil when
+// unset or not resolved, and the default resources, built from the Options. It may
+// change them, or set any of them as nil so it is not provisioned. It is called
+// with the HookTimeout, and a panic is returned as an error. Errors are retried
+// with backoff, unless they are terminal (see hooks.ErrTerminal). With
+// MergeGateways gw merges the listeners of the Gateways of the class, and is named
+// after it
+type TemplateFunc func(ctx context.Context, gw *gatewayv1.Gateway, params client.Object, resources *Resources) error
+
+// MutateFunc changes a generated resource right before it is applied, like
+// injecting a sidecar, the container resources or a node selector on the
+// Deployment. The obj is the typed resource, with the Gateway infrastructure
+// labels and annotations already set. It is called with the HookTimeout, like
+// the TemplateFunc. Errors are retried with backoff, unless they are terminal
+// (see hooks.ErrTerminal)
+type MutateFunc func(ctx context.Context, gw *gatewayv1.Gateway, obj client.Object) error
+
+// Mode is how the dataplane is deployed
+type Mode string
+
+const (
+	// ModeDeployment runs the dataplane on a Deployment, behind the Service. It is
+	// the default
+	ModeDeployment Mode = "Deployment"
+	// ModeDaemonSet runs the dataplane on every node, with a DaemonSet, for
+	// implementations terminating the traffic on the nodes instead of behind a
+	// cloud load balancer
+	ModeDaemonSet Mode = "DaemonSet"
+)
+
+// DriftPolicy is how the out of band changes of the provisioned resources, like a
+// kubectl edit of the Deployment, are handled
+type DriftPolicy string
+
+const (
+	// DriftPolicyRevert applies the resources again, taking the ownership of the
+	// changed fields back, so the Gateway remains the source of truth of the
+	// dataplane. The fields not set by the provisioner are kept. It is the default
+	DriftPolicyRevert DriftPolicy = "Revert"
+	// DriftPolicyReport keeps the changed fields, applying the others, and reports
+	// them as a Warning event on the Gateway and, see DriftStatus, on its
+	// DataplaneDrifted condition until they are reverted
+	DriftPolicyReport DriftPolicy = "Report"
+)
+
+// Options configures the Provisioner
+type Options struct {
+	// Image of the dataplane container. Required, unless the ClassImageFunc or
+	// the TemplateFunc set it
+	Image string
+	// ClassImageFunc overrides the Image of the Gateways of each GatewayClass,
+	// like from a version set on its parameters, see ParametersImage. The
+	// Gateways of a class are rolled out when it changes
+	ClassImageFunc ClassImageFunc
+	// MaxUnavailableGateways is the maximum number of the Gateways of a class
+	// rolling out a new ClassImageFunc image at once, so a bad version does not
+	// take every Gateway of the class down. Zero rolls all of them at once
+	MaxUnavailableGateways int
+	// Canary rolls a new ClassImageFunc image out to the canary Gateways of the
+	// class first, and to the others once the canaries soaked it. Disabled when
+	// empty, so all the Gateways roll out at once, up to MaxUnavailableGateways
+	Canary *CanaryOptions
+	// TemplateFunc customizes the provisioned resources. If empty the default
+	// resources are provisioned
+	TemplateFunc TemplateFunc
+	// MutateFuncs are called, in order, on each provisioned resource before it is
+	// applied. The provisioner keeps creating, updating and removing the resources
+	MutateFuncs []MutateFunc
+	// HookTimeout is the maximum duration of each TemplateFunc and MutateFunc
+	// call. Defaults to hooks.DefaultTimeout
+	HookTimeout time.Duration
+	// ServiceType of the provisioned Services: LoadBalancer, NodePort or
+	// ClusterIP. Defaults to LoadBalancer, or to ClusterIP with ModeDaemonSet. The
+	// TemplateFunc may change it per Gateway, like from its parameters
+	ServiceType corev1.ServiceType
+	// ServicePorts maps the listener ports to the ports of the provisioned
+	// Services, like exposing a listener on 8080 as the port 80. The listener
+	// ports not mapped are exposed on the same port
+	ServicePorts map[gatewayv1.PortNumber]int32
+	// ServiceAnnotationsFunc sets annotations on the provisioned Services, like
+	// the cloud provider load balancer ones, see ParametersAnnotations. The
+	// Services are provisioned again when the Gateway infrastructure parameters
+	// of the ParametersKinds change
+	ServiceAnnotationsFunc ServiceAnnotationsFunc
+	// AllocateLoadBalancerNodePorts sets whether the LoadBalancer Services
+	// allocate node ports. It is not set on the other Service types. If empty the
+	// API server default, to allocate them, is kept
+	AllocateLoadBalancerNodePorts *bool
+	// ConfigMountPath is where the ConfigMap is mounted on the dataplane
+	// container. Defaults to /etc/kgame
+	ConfigMountPath string
+	// ConfigSecret provisions a Secret with the dataplane configuration, instead
+	// of the ConfigMap, like when it has credentials
+	ConfigSecret bool
+	// RolloutOnConfigChange annotates the dataplane pod template with the
+	// checksum of its configuration, see RenderConfig, so the pods are replaced
+	// when it changes. Disabled for the dataplanes reloading it by themselves
+	RolloutOnConfigChange bool
+	// FieldManager of the provisioned resources, which are server side applied.
+	// Defaults to DefaultFieldManager
+	FieldManager string
+	// MaxConcurrentReconciles is the number of Gateways provisioned in parallel.
+	// Defaults to 1
+	MaxConcurrentReconciles int
+	// SweepInterval is the interval to remove the orphaned provisioned
+	// resources, like the ones left without owner references, or of a previous
+	// GatewayClass of their Gateway. Disabled when zero
+	SweepInterval time.Duration
+
+	// Mode of the dataplane. Defaults to ModeDeployment
+	Mode Mode
+	// HostNetwork runs the dataplane pods on the node network, with ModeDaemonSet
+	HostNetwork bool
+	// HostPorts exposes the listener ports on the nodes, with ModeDaemonSet. It is
+	// implied by HostNetwork
+	HostPorts bool
+	// Autoscaling provisions a HorizontalPodAutoscaler for the Deployment of each
+	// Gateway, with ModeDeployment. Disabled when empty
+	Autoscaling *AutoscalingOptions
+	// Disruption provisions a PodDisruptionBudget for the dataplane pods of each
+	// Gateway. Disabled when empty
+	Disruption *DisruptionOptions
+	// ServiceAccount provisions a ServiceAccount for the dataplane pods of each
+	// Gateway, with a Role reading its configuration. Disabled when empty, so the
+	// pods run with the default ServiceAccount of the namespace
+	ServiceAccount *ServiceAccountOptions
+	// DriftPolicy of the provisioned resources. Defaults to DriftPolicyRevert
+	DriftPolicy DriftPolicy
+	// Deletion is what happens to the provisioned resources when their Gateway is
+	// deleted. Defaults to removing them with the Gateway. Not used with
+	// MergeGateways, as the shared dataplanes are owned by their class
+	Deletion DeletionOptions
+	// ClassDeletion overrides the Deletion of the Gateways of each GatewayClass,
+	// by class name
+	ClassDeletion map[gatewayv1.ObjectName]DeletionOptions
+	// FinalizerName is the finalizer added to the Gateways whose deletion is not
+	// handled by the garbage collection alone, like with DeletionPolicyOrphan or
+	// a DrainGracePeriod. Defaults to DefaultFinalizerName
+	FinalizerName string
+
+	// MergeGateways provisions a single dataplane for all the accepted Gateways of
+	// each GatewayClass, merging their listeners on the same Service. The
+	// listeners whose port is used by an older Gateway of the class with another
+	// protocol are not merged, see ConflictingListeners
+	MergeGateways bool
+	// MergedNamespace is the namespace of the shared dataplanes. Required with
+	// MergeGateways
+	MergedNamespace string
+	// GatewayTrigger receives the Gateways to be reconciled again by the Gateway
+	// controller when their listener conflicts change, with MergeGateways. It is
+	// set by NewController
+	GatewayTrigger chan<- event.GenericEvent
+	// ParametersKinds are the kinds of the GatewayClass and Gateway
+	// infrastructure parameters watched to provision the Gateways again when
+	// they change, like to roll out the ClassImageFunc changes. They are set by
+	// NewController from the ControllerOptions.ParametersKinds
+	ParametersKinds []schema.GroupVersionKind
+	// Sharding skips the Gateways, and the merged GatewayClasses, owned by other
+	// active replicas, both when provisioning and sweeping. It is set by
+	// NewController from ControllerOptions.Sharding
+	Sharding *sharding.Options
+}
+
+// Validate returns an error if the options cannot provision any resource
+func (o Options) Validate() error {
+	if o.Image == "" && o.TemplateFunc == nil && o.ClassImageFunc == nil {
+		return fmt.Errorf("the provisioner requires an Image, a ClassImageFunc or a TemplateFunc")
+	}
+	if o.HookTimeout < 0 {
+		return fmt.Errorf("HookTimeout must not be negative")
+	}
+	if o.MaxUnavailableGateways < 0 {
+		return fmt.Errorf("MaxUnavailableGateways must not be negative")
+	}
+	if o.Canary != nil {
+		if o.MergeGateways {
+			return fmt.Errorf("the canary rollout is not supported with MergeGateways")
+		}
+		if err := o.Canary.validate(); err != nil {
+			return err
+		}
+	}
+	switch o.Mode {
+	case "", ModeDeployment:
+		if o.HostNetwork || o.HostPorts {
+			return fmt.Errorf("HostNetwork and HostPorts require the %s mode", ModeDaemonSet)
+		}
+	case ModeDaemonSet:
+	default:
+		return fmt.Errorf("unsupported provisioner mode %q", o.Mode)
+	}
+	if o.MergeGateways && o.MergedNamespace == "" {
+		return fmt.Errorf("MergeGateways requires the MergedNamespace")
+	}
+	if err := o.Deletion.validate(); err != nil {
+		return err
+	}
+	for class, deletion := range o.ClassDeletion {
+		if err := deletion.validate(); err != nil {
+			return fmt.Errorf("invalid deletion of the gatewayclass %s: %w", class, err)
+		}
+	}
+	switch o.DriftPolicy {
+	case "", DriftPolicyRevert, DriftPolicyReport:
+	default:
+		return fmt.Errorf("unsupported provisioner drift policy %q", o.DriftPolicy)
+	}
+	switch o.ServiceType {
+	case "", corev1.ServiceTypeLoadBalancer, corev1.ServiceTypeNodePort, corev1.ServiceTypeClusterIP:
+	default:
+		return fmt.Errorf("unsupported provisioner service type %q", o.ServiceType)
+	}
+	ports := make(map[int32]gatewayv1.PortNumber, len(o.ServicePorts))
+	for listenerPort, port := range o.ServicePorts {
+		if port < 1 || port > 65535 {
+			return fmt.Errorf("invalid service port %d for the listener port %d", port, listenerPort)
+		}
+		if other, ok := ports[port]; ok {
+			return fmt.Errorf("the listener ports %d and %d are mapped to the same service port %d", min(other, listenerPort), max(other, listenerPort), port)
+		}
+		ports[port] = listenerPort
+	}
+	if o.Autoscaling != nil {
+		if err := o.Autoscaling.validate(o.Mode); err != nil {
+			return err
+		}
+	}
+	if o.Disruption != nil {
+		return o.Disruption.validate()
+	}
+	return nil
+}
+
+// reconciler provisions the accepted Gateways of the managed GatewayClasses
+type reconciler struct {
+	client client.Client
+	// reader lists the Extra resources from the API server, as their kinds are
+	// not cached
+	reader   client.Reader
+	scheme   *runtime.Scheme
+	logger   logr.Logger
+	recorder record.EventRecorder
+	// finalizers manages the FinalizerName
+	finalizers *finalizer.Manager
+	// runner calls the TemplateFunc and the MutateFuncs
+	runner  hooks.Runner
+	options Options
+	// rollouts are the Gateways rolling out a new image, with the
+	// MaxUnavailableGateways
+	rollouts *rollouts
+}
+
+// SetupWithManager sets the provisioner to be started with the current manager.
+// The Gateways of GatewayClasses not on the cache are not provisioned, as the
+// kgame cache only keeps the managed GatewayClasses. The manager scheme must have
+// the apps/v1 types, the autoscaling/v2 types with the Autoscaling, the policy/v1
+// types with the Disruption and the rbac/v1 types with the ServiceAccount
+func SetupWithManager(mgr manager.Manager, options Options) error {
+	r := &reconciler{
+		client:   mgr.GetClient(),
+		reader:   mgr.GetAPIReader(),
+		scheme:   mgr.GetScheme(),
+		logger:   mgr.GetLogger().WithValues("controller", "provisioner"),
+		recorder: mgr.GetEventRecorderFor("kgame-provisioner"),
+		options:  options,
+		rollouts: &rollouts{classes: make(map[string]map[types.NamespacedName]string)},
+	}
+	r.runner = hooks.NewRunner(options.HookTimeout, r.recorder)
+	finalizerName := options.FinalizerName
+	if finalizerName == "" {
+		finalizerName = DefaultFinalizerName
+	}
+	r.finalizers = finalizer.NewManager(r.client, finalizer.Options{
+		Name:   finalizerName,
+		Runner: r.runner,
+	})
+	if err := indexes.AddGatewayClassName(context.Background(), mgr.GetFieldIndexer()); err != nil {
+		return err
+	}
+	if options.SweepInterval > 0 {
+		if err := mgr.Add(&sweeper{reconciler: r, cache: mgr.GetCache(), reader: mgr.GetAPIReader(), interval: options.SweepInterval}); err != nil {
+			return err
+		}
+	}
+	b := ctrl.NewControllerManagedBy(mgr).
+		Named("provisioner").
+		WithOptions(controller.Options{MaxConcurrentReconciles: options.MaxConcurrentReconciles}).
+		For(&gatewayv1.Gateway{})
+	if options.ExposesHostPorts() {
+		b = watchHostPorts(b, r.client, "Gateway")
+	}
+	if len(options.ParametersKinds) > 0 {
+		if err := indexes.AddGatewayInfrastructureParameters(context.Background(), mgr.GetFieldIndexer()); err != nil {
+			return err
+		}
+	}
+	for _, gvk := range options.ParametersKinds {
+		b = b.Watches(parameters.Metadata(gvk), handler.EnqueueRequestsFromMapFunc(gatewaysOfInfrastructureParameters(r.client, gvk)))
+	}
+	if options.ClassImageFunc != nil {
+		if len(options.ParametersKinds) > 0 {
+			if err := indexes.AddGatewayClassParameters(context.Background(), mgr.GetFieldIndexer()); err != nil {
+				return err
+			}
+		}
+		b = b.Watches(&gatewayv1.GatewayClass{}, handler.EnqueueRequestsFromMapFunc(gatewaysOfClass(r.client)),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}))
+		for _, gvk := range options.ParametersKinds {
+			b = b.Watches(parameters.Metadata(gvk), handler.EnqueueRequestsFromMapFunc(classesOfParameters(r.client, gvk, true)))
+		}
+	}
+	if err := r.owns(b).Complete(options.Sharding.Wrap(r)); err != nil {
+		return err
+	}
+	if !options.MergeGateways {
+		return nil
+	}
+
+	merged := &mergedReconciler{
+		reconciler: r,
+		conflicts:  make(map[string]map[types.NamespacedName]map[gatewayv1.SectionName]string),
+	}
+	b = ctrl.NewControllerManagedBy(mgr).
+		Named("provisioner-merged").
+		WithOptions(controller.Options{MaxConcurrentReconciles: options.MaxConcurrentReconciles}).
+		For(&gatewayv1.GatewayClass{}).
+		Watches(&gatewayv1.Gateway{}, handler.EnqueueRequestsFromMapFunc(gatewayClassOf))
+	if options.ExposesHostPorts() {
+		b = watchHostPorts(b, r.client, "GatewayClass")
+	}
+	if options.ClassImageFunc != nil {
+		for _, gvk := range options.ParametersKinds {
+			b = b.Watches(parameters.Metadata(gvk), handler.EnqueueRequestsFromMapFunc(classesOfParameters(r.client, gvk, false)))
+		}
+	}
+	return r.owns(b).Complete(options.Sharding.Wrap(merged))
+}
+
+// owns watches the provisioned resources controlled by the objects reconciled by b
+func (r *reconciler) owns(b *builder.Builder) *builder.Builder {
+	b = b.Owns(&appsv1.Deployment{}).
+		Owns(&appsv1.DaemonSet{}).
+		Owns(&corev1.Service{}).
+		Owns(&corev1.ConfigMap{})
+	if r.options.ConfigSecret {
+		b = b.Owns(&corev1.Secret{})
+	}
+	if r.options.Autoscaling != nil {
+		b = b.Owns(&autoscalingv2.HorizontalPodAutoscaler{})
+	}
+	if r.options.Disruption != nil {
+		b = b.Owns(&policyv1.PodDisruptionBudget{})
+	}
+	if r.options.ServiceAccount != nil {
+		b = b.Owns(&corev1.ServiceAccount{}).
+			Owns(&rbacv1.Role{}).
+			Owns(&rbacv1.RoleBinding{})
+	}
+	return b
+}
+
+// WatchAddresses reconciles the Gateways of c, like the kgame Gateway controller,
+// when their provisioned Services or dataplane pods change, so the addresses of
+// AddressProvider are set once the load balancer is assigned, or the pods are
+// ready on their nodes. The changes of a shared dataplane reconcile all the
+// Gateways of its class
+func WatchAddresses(mgr manager.Manager, c controller.Controller) error {
+	if err := c.Watch(source.Kind(mgr.GetCache(), client.Object(&corev1.Service{}),
+		handler.EnqueueRequestForOwner(mgr.GetScheme(), mgr.GetRESTMapper(), &gatewayv1.Gateway{}, handler.OnlyControllerOwner()))); err != nil {
+		return err
+	}
+	if err := c.Watch(source.Kind(mgr.GetCache(), client.Object(&corev1.Service{}),
+		handler.EnqueueRequestsFromMapFunc(gatewaysOfMergedDataplane(mgr.GetClient())))); err != nil {
+		return err
+	}
+	return c.Watch(source.Kind(mgr.GetCache(), client.Object(&corev1.Pod{}),
+		handler.EnqueueRequestsFromMapFunc(gatewayOfPod(mgr.GetClient()))))
+}
+
+func (r *reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	logger := r.logger.WithValues("gateway", req.NamespacedName)
+
+	gw := &gatewayv1.Gateway{}
+	if err := r.client.Get(ctx, req.NamespacedName, gw); err != nil {
+		// The provisioned resources of a deleted Gateway are garbage collected
+		if apierrors.IsNotFound(err) {
+			r.rollouts.finish(req.NamespacedName)
+		}
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+	if !gw.GetDeletionTimestamp().IsZero() {
+		return r.finalize(ctx, logger, gw)
+	}
+
+	provision, transient, err := r.shouldProvision(ctx, gw)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if transient && !r.options.MergeGateways {
+		// The Gateway is provisioned again once its Accepted condition follows
+		// its spec
+		logger.V(2).Info("keeping the resources of the gateway not accepted yet")
+		return reconcile.Result{}, nil
+	}
+	// With MergeGateways the Gateway is provisioned on the dataplane of its class
+	if !provision || r.options.MergeGateways {
+		r.rollouts.finish(req.NamespacedName)
+		return reconcile.Result{}, r.deprovision(ctx, logger, gw)
+	}
+	if r.deletionOf(gw).finalizes() {
+		if _, err := r.finalizers.Add(ctx, logger, gw); err != nil {
+			return hooks.Result(err)
+		}
+	}
+
+	return r.provision(ctx, logger, gw, gw, gatewayDataplane(gw))
+}
+
+// provision applies the resources of the dataplane of gw, controlled by owner,
+// and removes the stale ones
+func (r *reconciler) provision(ctx context.Context, logger logr.Logger, gw *gatewayv1.Gateway, owner client.Object, dp dataplane) (reconcile.Result, error) {
+	if r.options.ExposesHostPorts() {
+		// The Gateway controller rejects the conflicting listeners, whose pods
+		// would not be scheduled on the nodes using their ports
+		var err error
+		if dp, err = r.withoutHostPortConflicts(ctx, owner, dp); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+	options, waiting, err := r.dataplaneOptions(ctx, logger, gw, owner, dp)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	resources, err := r.resources(ctx, gw, dp, options)
+	if err != nil {
+		return hooks.Result(err)
+	}
+	for _, obj := range resources.objects() {
+		if err := r.mutate(ctx, gw, obj); err != nil {
+			return hooks.Result(err)
+		}
+		if err := r.apply(ctx, owner, dp.namespace, obj); err != nil {
+			return reconcile.Result{}, err
+		}
+		// The configuration is applied before the workload, so its checksum
+		// covers the keys of all its field managers
+		if r.options.RolloutOnConfigChange && (obj == client.Object(resources.ConfigMap) || obj == client.Object(resources.Secret)) {
+			checksum, err := configChecksum(obj)
+			if err != nil {
+				return reconcile.Result{}, err
+			}
+			resources.setConfigChecksum(checksum)
+		}
+	}
+	if err := r.applyExtra(ctx, logger, gw, owner, dp, resources.Extra); err != nil {
+		return hooks.Result(err)
+	}
+	// A dataplane switching modes keeps only the workload of the current one
+	if err := r.removeStale(ctx, logger, owner, dp, resources); err != nil {
+		return reconcile.Result{}, err
+	}
+	if waiting {
+		return reconcile.Result{RequeueAfter: rolloutRetryInterval}, nil
+	}
+	return reconcile.Result{}, nil
+}
+
+// shouldProvision returns true if the Gateway class is managed and the Gateway is
+// accepted. It returns transient when the Accepted condition does not reflect the
+// Gateway spec yet, missing, Unknown or of a previous generation, like while the
+// Gateway controller validates a change, so the resources are kept as they are
+func (r *reconciler) shouldProvision(ctx context.Context, gw *gatewayv1.Gateway) (bool, bool, error) {
+	class := &gatewayv1.GatewayClass{}
+	if err := r.client.Get(ctx, client.ObjectKey{Name: string(gw.Spec.GatewayClassName)}, class); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, false, nil
+		}
+		return false, false, fmt.Errorf("error getting gatewayclass: %w", err)
+	}
+	accepted := meta.FindStatusCondition(gw.Status.Conditions, string(gatewayv1.GatewayConditionAccepted))
+	if accepted == nil || accepted.Status == metav1.ConditionUnknown || accepted.ObservedGeneration < gw.GetGeneration() {
+		return false, true, nil
+	}
+	return accepted.Status == metav1.ConditionTrue, false, nil
+}
+
+// resources returns the resources of the Gateway, built from the options of its
+// dataplane and customized by the TemplateFunc, with the Service annotations and
+// exposure, and the Gateway infrastructure labels and annotations
+func (r *reconciler) resources(ctx context.Context, gw *gatewayv1.Gateway, dp dataplane, options Options) (*Resources, error) {
+	resources := defaultResources(dp, options)
+	if r.options.TemplateFunc == nil && r.options.ServiceAnnotationsFunc == nil {
+		resources.setServiceExposure(r.options)
+		resources.setInfrastructure(gw)
+		return resources, nil
+	}
+
+	// The invalid references are reported on the Gateway Accepted condition
+	params, err := parameters.ResolveInfrastructure(ctx, r.client, gw)
+	if err != nil && !errors.Is(err, parameters.ErrInvalidReference) {
+		return nil, fmt.Errorf("error resolving the infrastructure parameters: %w", err)
+	}
+	if r.options.TemplateFunc != nil {
+		// The template changes a copy of the resources, so a template running after
+		// its timeout cannot change the applied ones
+		defaults := resources
+		resources, err = hooks.Call(ctx, r.runner, gw, "Template", func(ctx context.Context, gw *gatewayv1.Gateway) (*Resources, error) {
+			templated := defaults.deepCopy()
+			return templated, r.options.TemplateFunc(ctx, gw, params, templated)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error executing the provisioner template: %w", err)
+		}
+	}
+	if err := r.serviceAnnotations(ctx, gw, params, resources); err != nil {
+		return nil, err
+	}
+	resources.setServiceExposure(r.options)
+	resources.setInfrastructure(gw)
+	return resources, nil
+}
+
+// mutate calls the MutateFuncs on obj, through the runner
+func (r *reconciler) mutate(ctx context.Context, gw *gatewayv1.Gateway, obj client.Object) error {
+	for _, fn := range r.options.MutateFuncs {
+		if err := hooks.Run(ctx, r.runner, obj, "Mutate", func(ctx context.Context, obj client.Object) error {
+			return fn(ctx, gw, obj)
+		}); err != nil {
+			return fmt.Errorf("error mutating %T %s: %w", obj, obj.GetName(), err)
+		}
+	}
+	return nil
+}
+
+// apply server side applies obj, controlled by owner, on the namespace when it has
+// none. With DriftPolicyReport the fields changed by other managers are not
+// forced: the other fields are applied, the changed ones are listed on the
+// DriftedAnnotation and their conflicts are reported on the owner
+func (r *reconciler) apply(ctx context.Context, owner client.Object, namespace string, obj client.Object) error {
+	gvk, err := apiutil.GVKForObject(obj, r.scheme)
+	if err != nil {
+		return err
+	}
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+	if obj.GetNamespace() == "" {
+		obj.SetNamespace(namespace)
+	}
+	if err := controllerutil.SetControllerReference(owner, obj, r.scheme); err != nil {
+		return fmt.Errorf("error setting the owner of %s %s: %w", gvk.Kind, obj.GetName(), err)
+	}
+
+	fieldManager := r.options.FieldManager
+	if fieldManager == "" {
+		fieldManager = DefaultFieldManager
+	}
+	opts := []client.PatchOption{client.FieldOwner(fieldManager)}
+	if r.options.DriftPolicy != DriftPolicyReport {
+		opts = append(opts, client.ForceOwnership)
+	}
+	if err := r.client.Patch(ctx, obj, client.Apply, opts...); err != nil {
+		if r.options.DriftPolicy != DriftPolicyReport || !apierrors.IsConflict(err) {
+			return fmt.Errorf("error applying %s %s: %w", gvk.Kind, obj.GetName(), err)
+		}
+		applied, applyErr := r.applyWithoutConflicts(ctx, obj, err, opts)
+		if applyErr != nil && !apierrors.IsConflict(applyErr) {
+			return fmt.Errorf("error applying %s %s without the fields changed out of band: %w", gvk.Kind, obj.GetName(), applyErr)
+		}
+		r.logger.Info("provisioned resource changed out of band", "owner", client.ObjectKeyFromObject(owner), "kind", gvk.Kind, "name", obj.GetName(), "applied", applied, "error", err.Error())
+		r.recorder.Eventf(owner, corev1.EventTypeWarning, "ResourceDrifted", "%s %s was changed out of band: %v", gvk.Kind, obj.GetName(), err)
+	}
+	return nil
+}
+
+// deprovision removes the resources provisioned for a Gateway that is not
+// accepted anymore, found by their labels and owner
+func (r *reconciler) deprovision(ctx context.Context, logger logr.Logger, gw *gatewayv1.Gateway) error {
+	if err := r.applyExtra(ctx, logger, gw, gw, gatewayDataplane(gw), nil); err != nil {
+		return err
+	}
+	return r.remove(ctx, logger, gw, gatewayDataplane(gw), r.options.provisionedLists())
+}
+
+// remove deletes the objects of the lists of the dataplane controlled by owner
+func (r *reconciler) remove(ctx context.Context, logger logr.Logger, owner client.Object, dp dataplane, lists []client.ObjectList) error {
+	for _, list := range lists {
+		if err := r.client.List(ctx, list, client.InNamespace(dp.namespace), client.MatchingLabels(dp.selector)); err != nil {
+			return fmt.Errorf("error listing the provisioned resources: %w", err)
+		}
+		err := meta.EachListItem(list, func(item runtime.Object) error {
+			obj, ok := item.(client.Object)
+			if !ok || !metav1.IsControlledBy(obj, owner) {
+				return nil
+			}
+			logger.Info("removing a provisioned resource", "name", obj.GetName(), "kind", fmt.Sprintf("%T", obj))
+			return client.IgnoreNotFound(r.client.Delete(ctx, obj))
+		})
+		if err != nil {
+			return fmt.Errorf("error removing the provisioned resources: %w", err)
+		}
+	}
+	return nil
+}
+
+// removeStale removes the workloads, autoscalers, disruption budgets and service
+// accounts of the dataplane that are not on resources anymore, like the
+// Deployment after switching to ModeDaemonSet
+func (r *reconciler) removeStale(ctx context.Context, logger logr.Logger, owner client.Object, dp dataplane, resources *Resources) error {
+	var lists []client.ObjectList
+	if resources.Deployment == nil {
+		lists = append(lists, &appsv1.DeploymentList{})
+	}
+	if resources.DaemonSet == nil {
+		lists = append(lists, &appsv1.DaemonSetList{})
+	}
+	if r.options.Autoscaling != nil && resources.HorizontalPodAutoscaler == nil {
+		lists = append(lists, &autoscalingv2.HorizontalPodAutoscalerList{})
+	}
+	if r.options.Disruption != nil && resources.PodDisruptionBudget == nil {
+		lists = append(lists, &policyv1.PodDisruptionBudgetList{})
+	}
+	if r.options.ConfigSecret && resources.Secret == nil {
+		lists = append(lists, &corev1.SecretList{})
+	}
+	if r.options.ServiceAccount != nil {
+		if resources.RoleBinding == nil {
+			lists = append(lists, &rbacv1.RoleBindingList{})
+		}
+		if resources.Role == nil {
+			lists = append(lists, &rbacv1.RoleList{})
+		}
+		if resources.ServiceAccount == nil {
+			lists = append(lists, &corev1.ServiceAccountList{})
+		}
+	}
+	return r.remove(ctx, logger, owner, dp, lists)
+}
@@ -0,0 +1,499 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioner
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+const (
+	// GatewayNameLabel is the label set on the provisioned resources with the
+	// name of their Gateway, as defined by the Gateway API
+	GatewayNameLabel = "gateway.networking.k8s.io/gateway-name"
+	// ManagedByLabel is the label set on the provisioned resources, so only them
+	// are cached
+	ManagedByLabel = "app.kubernetes.io/managed-by"
+	// ManagedByValue is the ManagedByLabel value of the provisioned resources
+	ManagedByValue = "kgame"
+
+	// defaultConfigMountPath is where the ConfigMap is mounted when the
+	// ConfigMountPath is not set
+	defaultConfigMountPath = "/etc/kgame"
+	// containerName is the name of the dataplane container
+	containerName = "dataplane"
+)
+
+// Resources are the resources provisioned for a Gateway. A nil resource is not
+// provisioned
+type Resources struct {
+	// Deployment runs the dataplane with ModeDeployment
+	Deployment *appsv1.Deployment
+	// DaemonSet runs the dataplane with ModeDaemonSet
+	DaemonSet *appsv1.DaemonSet
+	// HorizontalPodAutoscaler scales the Deployment, when the Autoscaling is set
+	HorizontalPodAutoscaler *autoscalingv2.HorizontalPodAutoscaler
+	// PodDisruptionBudget protects the dataplane pods, when the Disruption is set
+	PodDisruptionBudget *policyv1.PodDisruptionBudget
+	Service             *corev1.Service
+	ConfigMap           *corev1.ConfigMap
+	// Secret has the dataplane configuration, instead of the ConfigMap, when the
+	// ConfigSecret is set
+	Secret *corev1.Secret
+	// ServiceAccount runs the dataplane pods, with the permissions granted by the
+	// Role and RoleBinding, when the ServiceAccount option is set
+	ServiceAccount *corev1.ServiceAccount
+	Role           *rbacv1.Role
+	RoleBinding    *rbacv1.RoleBinding
+	// Extra are additional resources rendered by the TemplateFunc, like a
+	// NetworkPolicy or a ServiceAccount, on the namespace of the dataplane. They
+	// are tracked on an ApplySet, whose parent is a ConfigMap, and pruned once
+	// they are not rendered anymore
+	Extra []client.Object
+}
+
+// deepCopy returns a copy of the resources
+func (r *Resources) deepCopy() *Resources {
+	copied := &Resources{
+		Deployment:              r.Deployment.DeepCopy(),
+		DaemonSet:               r.DaemonSet.DeepCopy(),
+		HorizontalPodAutoscaler: r.HorizontalPodAutoscaler.DeepCopy(),
+		PodDisruptionBudget:     r.PodDisruptionBudget.DeepCopy(),
+		Service:                 r.Service.DeepCopy(),
+		ConfigMap:               r.ConfigMap.DeepCopy(),
+		Secret:                  r.Secret.DeepCopy(),
+		ServiceAccount:          r.ServiceAccount.DeepCopy(),
+		Role:                    r.Role.DeepCopy(),
+		RoleBinding:             r.RoleBinding.DeepCopy(),
+	}
+	for _, obj := range r.Extra {
+		copied.Extra = append(copied.Extra, obj.DeepCopyObject().(client.Object))
+	}
+	return copied
+}
+
+// objects returns the resources to provision
+func (r *Resources) objects() []client.Object {
+	var objects []client.Object
+	if r.ConfigMap != nil {
+		objects = append(objects, r.ConfigMap)
+	}
+	if r.Secret != nil {
+		objects = append(objects, r.Secret)
+	}
+	if r.ServiceAccount != nil {
+		objects = append(objects, r.ServiceAccount)
+	}
+	if r.Role != nil {
+		objects = append(objects, r.Role)
+	}
+	if r.RoleBinding != nil {
+		objects = append(objects, r.RoleBinding)
+	}
+	if r.Deployment != nil {
+		objects = append(objects, r.Deployment)
+	}
+	if r.DaemonSet != nil {
+		objects = append(objects, r.DaemonSet)
+	}
+	if r.HorizontalPodAutoscaler != nil {
+		objects = append(objects, r.HorizontalPodAutoscaler)
+	}
+	if r.PodDisruptionBudget != nil {
+		objects = append(objects, r.PodDisruptionBudget)
+	}
+	if r.Service != nil {
+		objects = append(objects, r.Service)
+	}
+	return objects
+}
+
+// setInfrastructure propagates the Gateway spec.infrastructure labels and
+// annotations to the resources and the dataplane pods, as required by the Gateway
+// API. The labels selecting the resources are not overridden
+func (r *Resources) setInfrastructure(gw *gatewayv1.Gateway) {
+	infrastructure := gw.Spec.Infrastructure
+	if infrastructure == nil || (len(infrastructure.Labels) == 0 && len(infrastructure.Annotations) == 0) {
+		return
+	}
+	objects := append(r.objects(), r.Extra...)
+	metas := make([]metav1.Object, 0, len(objects)+2)
+	for _, obj := range objects {
+		metas = append(metas, obj)
+	}
+	if r.Deployment != nil {
+		metas = append(metas, &r.Deployment.Spec.Template.ObjectMeta)
+	}
+	if r.DaemonSet != nil {
+		metas = append(metas, &r.DaemonSet.Spec.Template.ObjectMeta)
+	}
+
+	selector := selectorLabels(gw)
+	for _, obj := range metas {
+		labels := obj.GetLabels()
+		if labels == nil {
+			labels = make(map[string]string, len(infrastructure.Labels))
+		}
+		for key, value := range infrastructure.Labels {
+			if _, ok := selector[string(key)]; !ok {
+				labels[string(key)] = string(value)
+			}
+		}
+		obj.SetLabels(labels)
+
+		annotations := obj.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string, len(infrastructure.Annotations))
+		}
+		for key, value := range infrastructure.Annotations {
+			annotations[string(key)] = string(value)
+		}
+		obj.SetAnnotations(annotations)
+	}
+}
+
+// setServiceExposure sets the AllocateLoadBalancerNodePorts of the Service when
+// it is a LoadBalancer, and clears it otherwise, as the API rejects it on the
+// other types. It runs after the TemplateFunc, which may change the Service type
+func (r *Resources) setServiceExposure(options Options) {
+	if r.Service == nil {
+		return
+	}
+	if r.Service.Spec.Type != corev1.ServiceTypeLoadBalancer {
+		r.Service.Spec.AllocateLoadBalancerNodePorts = nil
+		return
+	}
+	if r.Service.Spec.AllocateLoadBalancerNodePorts == nil {
+		r.Service.Spec.AllocateLoadBalancerNodePorts = options.AllocateLoadBalancerNodePorts
+	}
+}
+
+// ResourceName returns the name of the resources provisioned for the Gateway, a
+// DNS-1035 label as required by the Services, see dnsLabelName
+func ResourceName(gw *gatewayv1.Gateway) string {
+	return dnsLabelName(fmt.Sprintf("%s-%s", gw.GetName(), gw.Spec.GatewayClassName))
+}
+
+// nameHashLength is the length of the hash suffix of the names and label values
+// too long, or not valid
+const nameHashLength = 8
+
+// dnsLabelName returns name when it is a DNS-1035 label. Otherwise its dots are
+// replaced, it is prefixed when not starting with a letter, and truncated and
+// suffixed by the hash of name, so the names made valid stay unique
+func dnsLabelName(name string) string {
+	if len(validation.IsDNS1035Label(name)) == 0 {
+		return name
+	}
+	valid := strings.ReplaceAll(name, ".", "-")
+	if valid == "" || valid[0] < 'a' || valid[0] > 'z' {
+		valid = "gw-" + valid
+	}
+	return hashSuffixed(valid, name, validation.DNS1035LabelMaxLength)
+}
+
+// labelValue returns value truncated and suffixed by its hash when it is longer
+// than a label value, like the names of the Gateways and GatewayClasses
+func labelValue(value string) string {
+	if len(value) <= validation.LabelValueMaxLength {
+		return value
+	}
+	return hashSuffixed(value, value, validation.LabelValueMaxLength)
+}
+
+// hashSuffixed truncates value, so it is suffixed by the hash of original within
+// the maxLength
+func hashSuffixed(value, original string, maxLength int) string {
+	hash := sha256.Sum256([]byte(original))
+	value = strings.TrimRight(value[:min(len(value), maxLength-nameHashLength-1)], "-.")
+	return value + "-" + hex.EncodeToString(hash[:])[:nameHashLength]
+}
+
+// selectorLabels are the labels selecting the resources of the Gateway
+func selectorLabels(gw *gatewayv1.Gateway) map[string]string {
+	return map[string]string{
+		GatewayNameLabel: labelValue(gw.GetName()),
+		ManagedByLabel:   ManagedByValue,
+	}
+}
+
+// gatewayOfLabel returns the name of the Gateway of the namespace whose
+// GatewayNameLabel is value, empty when there is none. The names hashed by
+// labelValue are looked up on the Gateways of the namespace
+func gatewayOfLabel(ctx context.Context, reader client.Reader, namespace, value string) (string, error) {
+	if len(value) < validation.LabelValueMaxLength {
+		return value, nil
+	}
+	gateways := &gatewayv1.GatewayList{}
+	if err := reader.List(ctx, gateways, client.InNamespace(namespace)); err != nil {
+		return "", fmt.Errorf("error listing the gateways of %s: %w", namespace, err)
+	}
+	for _, gw := range gateways.Items {
+		if labelValue(gw.GetName()) == value {
+			return gw.GetName(), nil
+		}
+	}
+	return "", nil
+}
+
+// classOfLabel returns the name of the GatewayClass whose GatewayClassLabel is
+// value, empty when there is none. The names hashed by labelValue are looked up
+// on the GatewayClasses
+func classOfLabel(ctx context.Context, reader client.Reader, value string) (string, error) {
+	if len(value) < validation.LabelValueMaxLength {
+		return value, nil
+	}
+	classes := &gatewayv1.GatewayClassList{}
+	if err := reader.List(ctx, classes); err != nil {
+		return "", fmt.Errorf("error listing the gatewayclasses: %w", err)
+	}
+	for _, class := range classes.Items {
+		if labelValue(class.GetName()) == value {
+			return class.GetName(), nil
+		}
+	}
+	return "", nil
+}
+
+// dataplane identifies the provisioned resources of a dataplane, the ones of a
+// Gateway or, with MergeGateways, the ones shared by the Gateways of a class
+type dataplane struct {
+	name      string
+	namespace string
+	// selector are the labels selecting the resources and the dataplane pods
+	selector  map[string]string
+	listeners []gatewayv1.Listener
+}
+
+// gatewayDataplane returns the dataplane of the Gateway
+func gatewayDataplane(gw *gatewayv1.Gateway) dataplane {
+	return dataplane{
+		name:      ResourceName(gw),
+		namespace: gw.GetNamespace(),
+		selector:  selectorLabels(gw),
+		listeners: gw.Spec.Listeners,
+	}
+}
+
+// defaultResources returns the Deployment, or DaemonSet, Service and ConfigMap,
+// or Secret, of the dataplane, exposing the listener ports, and the
+// HorizontalPodAutoscaler of the Deployment, PodDisruptionBudget and
+// ServiceAccount of the pods
+func defaultResources(dp dataplane, options Options) *Resources {
+	name := dp.name
+	objectMeta := metav1.ObjectMeta{
+		Name:      name,
+		Namespace: dp.namespace,
+		Labels:    maps.Clone(dp.selector),
+	}
+
+	mountPath := options.ConfigMountPath
+	if mountPath == "" {
+		mountPath = defaultConfigMountPath
+	}
+	serviceType := options.ServiceType
+	if serviceType == "" {
+		serviceType = corev1.ServiceTypeLoadBalancer
+		if options.Mode == ModeDaemonSet {
+			serviceType = corev1.ServiceTypeClusterIP
+		}
+	}
+
+	var containerPorts []corev1.ContainerPort
+	var servicePorts []corev1.ServicePort
+	var ports []gatewayv1.PortNumber
+	for _, listener := range dp.listeners {
+		if slices.Contains(ports, listener.Port) {
+			continue
+		}
+		ports = append(ports, listener.Port)
+		portName := fmt.Sprintf("port-%d", listener.Port)
+		protocol := corev1.ProtocolTCP
+		if listener.Protocol == gatewayv1.UDPProtocolType {
+			protocol = corev1.ProtocolUDP
+		}
+		containerPort := corev1.ContainerPort{
+			Name:          portName,
+			ContainerPort: int32(listener.Port),
+			Protocol:      protocol,
+		}
+		if options.HostNetwork || options.HostPorts {
+			containerPort.HostPort = int32(listener.Port)
+		}
+		containerPorts = append(containerPorts, containerPort)
+		servicePort, ok := options.ServicePorts[listener.Port]
+		if !ok {
+			servicePort = int32(listener.Port)
+		}
+		servicePorts = append(servicePorts, corev1.ServicePort{
+			Name:       portName,
+			Port:       servicePort,
+			TargetPort: intstr.FromString(portName),
+			Protocol:   protocol,
+		})
+	}
+
+	template := corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: maps.Clone(dp.selector)},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:  containerName,
+				Image: options.Image,
+				Ports: containerPorts,
+				VolumeMounts: []corev1.VolumeMount{{
+					Name:      "config",
+					MountPath: mountPath,
+					ReadOnly:  true,
+				}},
+			}},
+			Volumes: []corev1.Volume{{
+				Name: "config",
+				VolumeSource: corev1.VolumeSource{
+					ConfigMap: &corev1.ConfigMapVolumeSource{
+						LocalObjectReference: corev1.LocalObjectReference{Name: name},
+					},
+				},
+			}},
+		},
+	}
+
+	resources := &Resources{
+		Service: &corev1.Service{
+			ObjectMeta: *objectMeta.DeepCopy(),
+			Spec: corev1.ServiceSpec{
+				Type:     serviceType,
+				Selector: maps.Clone(dp.selector),
+				Ports:    servicePorts,
+			},
+		},
+	}
+	if options.ConfigSecret {
+		template.Spec.Volumes[0].VolumeSource = corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{SecretName: name},
+		}
+		resources.Secret = &corev1.Secret{ObjectMeta: *objectMeta.DeepCopy()}
+	} else {
+		resources.ConfigMap = &corev1.ConfigMap{ObjectMeta: *objectMeta.DeepCopy()}
+	}
+	if options.ServiceAccount != nil {
+		template.Spec.ServiceAccountName = name
+		resources.ServiceAccount, resources.Role, resources.RoleBinding = serviceAccount(*objectMeta.DeepCopy(), options.ServiceAccount, options.ConfigSecret)
+	}
+	if options.Disruption != nil {
+		resources.PodDisruptionBudget = podDisruptionBudget(*objectMeta.DeepCopy(), dp.selector, options.Disruption)
+	}
+
+	if options.Mode == ModeDaemonSet {
+		if options.HostNetwork {
+			template.Spec.HostNetwork = true
+			template.Spec.DNSPolicy = corev1.DNSClusterFirstWithHostNet
+		}
+		resources.DaemonSet = &appsv1.DaemonSet{
+			ObjectMeta: *objectMeta.DeepCopy(),
+			Spec: appsv1.DaemonSetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: maps.Clone(dp.selector)},
+				Template: template,
+			},
+		}
+		return resources
+	}
+	resources.Deployment = &appsv1.Deployment{
+		ObjectMeta: *objectMeta.DeepCopy(),
+		Spec: appsv1.DeploymentSpec{
+			Replicas: ptr.To[int32](1),
+			Selector: &metav1.LabelSelector{MatchLabels: maps.Clone(dp.selector)},
+			Template: template,
+		},
+	}
+	if options.Autoscaling != nil {
+		// The replicas are not applied, so they are owned by the autoscaler
+		resources.Deployment.Spec.Replicas = nil
+		resources.HorizontalPodAutoscaler = horizontalPodAutoscaler(*objectMeta.DeepCopy(), options.Autoscaling)
+	}
+	return resources
+}
+
+// AddressProvider returns a GatewayOptions.AddressProviderFunc returning the
+// load balancer addresses of the Service provisioned for the Gateway, or of the
+// Service shared by the Gateways of its class with MergeGateways. Without a load
+// balancer, the addresses of the nodes running the ready dataplane pods are
+// returned when the Service is a NodePort, or the pods use host ports
+func AddressProvider(reader client.Reader) func(ctx context.Context, gw *gatewayv1.Gateway) ([]gatewayv1.GatewayStatusAddress, error) {
+	return func(ctx context.Context, gw *gatewayv1.Gateway) ([]gatewayv1.GatewayStatusAddress, error) {
+		services, err := provisionedServices(ctx, reader, gw)
+		if err != nil {
+			return nil, err
+		}
+		var addresses []gatewayv1.GatewayStatusAddress
+		for _, service := range services {
+			for _, ingress := range service.Status.LoadBalancer.Ingress {
+				switch {
+				case ingress.IP != "":
+					addresses = append(addresses, gatewayv1.GatewayStatusAddress{Type: ptr.To(gatewayv1.IPAddressType), Value: ingress.IP})
+				case ingress.Hostname != "":
+					addresses = append(addresses, gatewayv1.GatewayStatusAddress{Type: ptr.To(gatewayv1.HostnameAddressType), Value: ingress.Hostname})
+				}
+			}
+		}
+		if len(addresses) > 0 {
+			return addresses, nil
+		}
+		for _, service := range services {
+			nodes, err := nodeAddresses(ctx, reader, service)
+			if err != nil {
+				return nil, err
+			}
+			addresses = append(addresses, nodes...)
+		}
+		return addresses, nil
+	}
+}
+
+// provisionedServices returns the Services provisioned for the Gateway, or the
+// ones shared by the Gateways of its class with MergeGateways
+func provisionedServices(ctx context.Context, reader client.Reader, gw *gatewayv1.Gateway) ([]corev1.Service, error) {
+	services := &corev1.ServiceList{}
+	if err := reader.List(ctx, services, client.InNamespace(gw.GetNamespace()), client.MatchingLabels(selectorLabels(gw))); err != nil {
+		return nil, fmt.Errorf("error listing the provisioned services: %w", err)
+	}
+	services.Items = slices.DeleteFunc(services.Items, func(service corev1.Service) bool {
+		return !metav1.IsControlledBy(&service, gw)
+	})
+	if len(services.Items) == 0 {
+		if err := reader.List(ctx, services, client.MatchingLabels(mergedSelectorLabels(string(gw.Spec.GatewayClassName)))); err != nil {
+			return nil, fmt.Errorf("error listing the shared dataplane services: %w", err)
+		}
+	}
+	return services.Items, nil
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioner
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// progressDeadlineExceeded is the reason of the Deployment Progressing condition
+// once its rollout failed
+const progressDeadlineExceeded = "ProgressDeadlineExceeded"
+
+// RolloutStatus returns a GatewayOptions.DataplaneReadyFunc reporting if the
+// rollout of the Deployment, or DaemonSet, provisioned for the Gateway, or shared
+// by the Gateways of its class with MergeGateways, finished. The Gateways without
+// a provisioned workload, like the ones not provisioned yet, are ready
+func RolloutStatus(reader client.Reader) func(ctx context.Context, gw *gatewayv1.Gateway) (bool, string, error) {
+	return func(ctx context.Context, gw *gatewayv1.Gateway) (bool, string, error) {
+		deployments := &appsv1.DeploymentList{}
+		if err := reader.List(ctx, deployments, client.InNamespace(gw.GetNamespace()), client.MatchingLabels(selectorLabels(gw))); err != nil {
+			return false, "", fmt.Errorf("error listing the provisioned deployments: %w", err)
+		}
+		deployments.Items = slices.DeleteFunc(deployments.Items, func(deployment appsv1.Deployment) bool {
+			return !metav1.IsControlledBy(&deployment, gw)
+		})
+		daemonSets := &appsv1.DaemonSetList{}
+		if err := reader.List(ctx, daemonSets, client.InNamespace(gw.GetNamespace()), client.MatchingLabels(selectorLabels(gw))); err != nil {
+			return false, "", fmt.Errorf("error listing the provisioned daemonsets: %w", err)
+		}
+		daemonSets.Items = slices.DeleteFunc(daemonSets.Items, func(daemonSet appsv1.DaemonSet) bool {
+			return !metav1.IsControlledBy(&daemonSet, gw)
+		})
+		if len(deployments.Items) == 0 && len(daemonSets.Items) == 0 {
+			merged := client.MatchingLabels(mergedSelectorLabels(string(gw.Spec.GatewayClassName)))
+			if err := reader.List(ctx, deployments, merged); err != nil {
+				return false, "", fmt.Errorf("error listing the shared dataplane deployments: %w", err)
+			}
+			if err := reader.List(ctx, daemonSets, merged); err != nil {
+				return false, "", fmt.Errorf("error listing the shared dataplane daemonsets: %w", err)
+			}
+		}
+
+		for i := range deployments.Items {
+			if ready, message := deploymentRolledOut(&deployments.Items[i]); !ready {
+				return false, message, nil
+			}
+		}
+		for i := range daemonSets.Items {
+			if ready, message := daemonSetRolledOut(&daemonSets.Items[i]); !ready {
+				return false, message, nil
+			}
+		}
+		return true, "", nil
+	}
+}
+
+// deploymentRolledOut returns true when the latest spec of the Deployment is
+// available, or a message with the rollout progress otherwise, like kubectl
+// rollout status
+func deploymentRolledOut(deployment *appsv1.Deployment) (bool, string) {
+	if deployment.Status.ObservedGeneration < deployment.Generation {
+		return false, fmt.Sprintf("waiting for the rollout of the dataplane deployment %s to start", deployment.GetName())
+	}
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentProgressing && condition.Reason == progressDeadlineExceeded {
+			return false, fmt.Sprintf("the rollout of the dataplane deployment %s exceeded its progress deadline: %s", deployment.GetName(), condition.Message)
+		}
+	}
+	replicas := deployment.Status.Replicas
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	switch {
+	case deployment.Status.UpdatedReplicas < replicas:
+		return false, fmt.Sprintf("waiting for the rollout of the dataplane deployment %s: %d of %d replicas updated", deployment.GetName(), deployment.Status.UpdatedReplicas, replicas)
+	case deployment.Status.Replicas > deployment.Status.UpdatedReplicas:
+		return false, fmt.Sprintf("waiting for the rollout of the dataplane deployment %s: %d old replicas pending termination", deployment.GetName(), deployment.Status.Replicas-deployment.Status.UpdatedReplicas)
+	case deployment.Status.AvailableReplicas < deployment.Status.UpdatedReplicas || deployment.Status.AvailableReplicas == 0:
+		return false, fmt.Sprintf("waiting for the rollout of the dataplane deployment %s: %d of %d replicas available", deployment.GetName(), deployment.Status.AvailableReplicas, deployment.Status.UpdatedReplicas)
+	}
+	return true, ""
+}
+
+// daemonSetRolledOut returns true when the latest spec of the DaemonSet is
+// available on all its nodes, or a message with the rollout progress otherwise
+func daemonSetRolledOut(daemonSet *appsv1.DaemonSet) (bool, string) {
+	if daemonSet.Status.ObservedGeneration < daemonSet.Generation {
+		return false, fmt.Sprintf("waiting for the rollout of the dataplane daemonset %s to start", daemonSet.GetName())
+	}
+	desired := daemonSet.Status.DesiredNumberScheduled
+	switch {
+	case daemonSet.Status.UpdatedNumberScheduled < desired:
+		return false, fmt.Sprintf("waiting for the rollout of the dataplane daemonset %s: %d of %d pods updated", daemonSet.GetName(), daemonSet.Status.UpdatedNumberScheduled, desired)
+	case daemonSet.Status.NumberAvailable < desired || desired == 0:
+		return false, fmt.Sprintf("waiting for the rollout of the dataplane daemonset %s: %d of %d pods available", daemonSet.GetName(), daemonSet.Status.NumberAvailable, desired)
+	}
+	return true, ""
+}
+
+// workloadRolledOut returns true when the provisioned Deployment, or DaemonSet,
+// rolled out, or a message with the rollout progress otherwise
+func workloadRolledOut(workload client.Object) (bool, string) {
+	switch workload := workload.(type) {
+	case *appsv1.Deployment:
+		return deploymentRolledOut(workload)
+	case *appsv1.DaemonSet:
+		return daemonSetRolledOut(workload)
+	}
+	return false, ""
+}
+
+// WatchRollouts reconciles the Gateways of c, like the kgame Gateway controller,
+// when their provisioned Deployments and DaemonSets change, so the Gateways of
+// RolloutStatus are programmed once the rollout finishes. The changes of a
+// shared dataplane reconcile all the Gateways of its class
+func WatchRollouts(mgr manager.Manager, c controller.Controller) error {
+	for _, obj := range []client.Object{&appsv1.Deployment{}, &appsv1.DaemonSet{}} {
+		if err := c.Watch(source.Kind(mgr.GetCache(), obj,
+			handler.EnqueueRequestForOwner(mgr.GetScheme(), mgr.GetRESTMapper(), &gatewayv1.Gateway{}, handler.OnlyControllerOwner()))); err != nil {
+			return err
+		}
+		if err := c.Watch(source.Kind(mgr.GetCache(), obj,
+			handler.EnqueueRequestsFromMapFunc(gatewaysOfMergedDataplane(mgr.GetClient())))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioner
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ServiceAccountOptions configures the ServiceAccount, Role and RoleBinding
+// provisioned for the dataplane of each Gateway, when it reads the Kubernetes
+// API, like to watch its configuration. The provisioner must hold the granted
+// permissions, as the API rejects the Roles escalating them
+type ServiceAccountOptions struct {
+	// Rules are granted to the dataplane on the namespace of its resources,
+	// besides reading its ConfigMap, or Secret
+	Rules []rbacv1.PolicyRule
+}
+
+// serviceAccount returns the ServiceAccount of the dataplane pods, the Role
+// reading their configuration with the additional rules, and the RoleBinding
+// granting it
+func serviceAccount(objectMeta metav1.ObjectMeta, options *ServiceAccountOptions, configSecret bool) (*corev1.ServiceAccount, *rbacv1.Role, *rbacv1.RoleBinding) {
+	configResource := "configmaps"
+	if configSecret {
+		configResource = "secrets"
+	}
+	rules := append([]rbacv1.PolicyRule{{
+		APIGroups:     []string{""},
+		Resources:     []string{configResource},
+		ResourceNames: []string{objectMeta.Name},
+		Verbs:         []string{"get", "list", "watch"},
+	}}, options.Rules...)
+
+	return &corev1.ServiceAccount{
+		ObjectMeta: *objectMeta.DeepCopy(),
+	}, &rbacv1.Role{
+		ObjectMeta: *objectMeta.DeepCopy(),
+		Rules:      rules,
+	}, &rbacv1.RoleBinding{
+		ObjectMeta: *objectMeta.DeepCopy(),
+		Subjects: []rbacv1.Subject{{
+			Kind:      rbacv1.ServiceAccountKind,
+			Name:      objectMeta.Name,
+			Namespace: objectMeta.Namespace,
+		}},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "Role",
+			Name:     objectMeta.Name,
+		},
+	}
+}
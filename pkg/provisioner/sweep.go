@@ -0,0 +1,156 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioner
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// sweeper periodically removes the orphaned provisioned resources, the ones
+// whose Gateway does not exist anymore, or does not control them, like after
+// being recreated, and the ones of a previous GatewayClass of their Gateway. The
+// shared dataplanes are removed when their GatewayClass does not exist anymore,
+// or MergeGateways is not set. It is a manager Runnable, running on the leader.
+// With the Sharding, each replica only sweeps the resources of the Gateways, and
+// GatewayClasses, it owns
+type sweeper struct {
+	reconciler *reconciler
+	cache      cache.Cache
+	// reader gets the Gateways from the API server, as the Gateways of the
+	// GatewayClasses not managed by kgame may be dropped from the cache
+	reader   client.Reader
+	interval time.Duration
+}
+
+// Start sweeps the provisioned resources every interval, until ctx is done
+func (s *sweeper) Start(ctx context.Context) error {
+	if !s.cache.WaitForCacheSync(ctx) {
+		return nil
+	}
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		if err := s.sweep(ctx); err != nil {
+			s.reconciler.logger.Error(err, "error sweeping the orphaned provisioned resources")
+		}
+	}
+}
+
+// sweep removes the orphaned resources of each provisioned kind
+func (s *sweeper) sweep(ctx context.Context) error {
+	for _, list := range s.reconciler.options.provisionedLists() {
+		if err := s.reconciler.client.List(ctx, list, client.MatchingLabels{ManagedByLabel: ManagedByValue}); err != nil {
+			return fmt.Errorf("error listing the provisioned resources: %w", err)
+		}
+		err := meta.EachListItem(list, func(item runtime.Object) error {
+			obj, ok := item.(client.Object)
+			if !ok {
+				return nil
+			}
+			orphaned, err := s.orphaned(ctx, obj)
+			if err != nil || !orphaned {
+				return err
+			}
+			s.reconciler.logger.Info("removing an orphaned provisioned resource", "namespace", obj.GetNamespace(), "name", obj.GetName(), "kind", fmt.Sprintf("%T", obj))
+			return client.IgnoreNotFound(s.reconciler.client.Delete(ctx, obj))
+		})
+		if err != nil {
+			return fmt.Errorf("error removing the orphaned provisioned resources: %w", err)
+		}
+	}
+	return nil
+}
+
+// orphaned returns true if the provisioned obj is not controlled by its Gateway,
+// found by the GatewayNameLabel, or was provisioned for another GatewayClass.
+// The resources of the Gateways owned by other replicas are never orphaned
+func (s *sweeper) orphaned(ctx context.Context, obj client.Object) (bool, error) {
+	shards := s.reconciler.options.Sharding
+	if value, ok := obj.GetLabels()[GatewayClassLabel]; ok {
+		class, err := classOfLabel(ctx, s.reader, value)
+		if err != nil {
+			return false, err
+		}
+		if class != "" && !shards.Owns(reconcile.Request{NamespacedName: types.NamespacedName{Name: class}}) {
+			return false, nil
+		}
+		return s.orphanedMerged(ctx, obj, class)
+	}
+	value, ok := obj.GetLabels()[GatewayNameLabel]
+	if !ok {
+		return false, nil
+	}
+	name, err := gatewayOfLabel(ctx, s.reader, obj.GetNamespace(), value)
+	if err != nil {
+		return false, err
+	}
+	if name == "" {
+		return true, nil
+	}
+	if !shards.Owns(reconcile.Request{NamespacedName: types.NamespacedName{Namespace: obj.GetNamespace(), Name: name}}) {
+		return false, nil
+	}
+	gw := &gatewayv1.Gateway{}
+	if err := s.reader.Get(ctx, client.ObjectKey{Namespace: obj.GetNamespace(), Name: name}, gw); err != nil {
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, fmt.Errorf("error getting the gateway of %s/%s: %w", obj.GetNamespace(), obj.GetName(), err)
+	}
+	dp := gatewayDataplane(gw)
+	return !metav1.IsControlledBy(obj, gw) || (obj.GetName() != dp.name && obj.GetName() != applySetKey(dp).Name), nil
+}
+
+// provisionedLists returns a list of each kind of provisioned resource
+func (o Options) provisionedLists() []client.ObjectList {
+	lists := []client.ObjectList{&appsv1.DeploymentList{}, &appsv1.DaemonSetList{}, &corev1.ServiceList{}, &corev1.ConfigMapList{}}
+	if o.Autoscaling != nil {
+		lists = append(lists, &autoscalingv2.HorizontalPodAutoscalerList{})
+	}
+	if o.Disruption != nil {
+		lists = append(lists, &policyv1.PodDisruptionBudgetList{})
+	}
+	if o.ConfigSecret {
+		lists = append(lists, &corev1.SecretList{})
+	}
+	if o.ServiceAccount != nil {
+		lists = append(lists, &rbacv1.RoleBindingList{}, &rbacv1.RoleList{}, &corev1.ServiceAccountList{})
+	}
+	return lists
+}
@@ -0,0 +1,348 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/rikatz/kgame/pkg/parameters"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// ImageParameter is the key of the ConfigMap parameters, or the spec field of
+// the other kinds, with the dataplane image read by ParametersImage
+const ImageParameter = "image"
+
+// rolloutRetryInterval is the interval to check again if a Gateway waiting for
+// the rollout of the other Gateways of its class can roll out
+const rolloutRetryInterval = 15 * time.Second
+
+// ClassImageFunc returns the dataplane image of the Gateways of a GatewayClass,
+// like from the version set on params, the object referenced by its
+// parametersRef, nil when unset or not resolved. An empty image keeps the Image
+type ClassImageFunc func(ctx context.Context, class *gatewayv1.GatewayClass, params client.Object) (string, error)
+
+// ParametersImage is a ClassImageFunc returning the ImageParameter of the
+// GatewayClass parameters, a ConfigMap key or, for the kinds not on the scheme,
+// a spec field
+func ParametersImage(_ context.Context, _ *gatewayv1.GatewayClass, params client.Object) (string, error) {
+	switch params := params.(type) {
+	case *corev1.ConfigMap:
+		return params.Data[ImageParameter], nil
+	case *unstructured.Unstructured:
+		image, _, err := unstructured.NestedString(params.Object, "spec", ImageParameter)
+		if err != nil {
+			return "", fmt.Errorf("error reading the image of the parameters: %w", err)
+		}
+		return image, nil
+	}
+	return "", nil
+}
+
+// dataplaneOptions returns the Options of the dataplane of gw, controlled by
+// owner, with the ClassImageFunc image. With the Canary, or MaxUnavailableGateways,
+// the current image of a Gateway is kept, returning true, while the canaries of
+// its class soak the image, or too many Gateways of its class are rolling out
+func (r *reconciler) dataplaneOptions(ctx context.Context, logger logr.Logger, gw *gatewayv1.Gateway, owner client.Object, dp dataplane) (Options, bool, error) {
+	options := r.options
+	if options.ClassImageFunc == nil {
+		return options, false, nil
+	}
+	class := &gatewayv1.GatewayClass{}
+	if err := r.client.Get(ctx, client.ObjectKey{Name: string(gw.Spec.GatewayClassName)}, class); err != nil {
+		return options, false, fmt.Errorf("error getting gatewayclass: %w", err)
+	}
+	image, err := classImage(ctx, r.client, class, options.ClassImageFunc)
+	if err != nil {
+		return options, false, err
+	}
+	if image == "" {
+		return options, false, nil
+	}
+
+	// The shared dataplanes are the only ones of their class
+	if (options.MaxUnavailableGateways > 0 || options.Canary != nil) && owner == client.Object(gw) {
+		workload, err := provisionedWorkload(ctx, r.client, dp)
+		if err != nil {
+			return options, false, err
+		}
+		current := workloadImage(workload)
+		if current == image {
+			if rolledOut, _ := workloadRolledOut(workload); rolledOut {
+				r.rollouts.finish(client.ObjectKeyFromObject(gw))
+			}
+		}
+		if current != "" && current != image {
+			waiting, err := r.waitRollout(ctx, logger, gw, image)
+			if err != nil {
+				return options, false, err
+			}
+			if waiting {
+				options.Image = current
+				return options, true, nil
+			}
+		}
+	}
+	options.Image = image
+	return options, false, nil
+}
+
+// classImage returns the image of the Gateways of the class returned by the
+// imageFunc, empty when it keeps the Image
+func classImage(ctx context.Context, c client.Client, class *gatewayv1.GatewayClass, imageFunc ClassImageFunc) (string, error) {
+	// The invalid references are reported on the GatewayClass Accepted condition
+	params, err := parameters.Resolve(ctx, c, class)
+	if err != nil && !errors.Is(err, parameters.ErrInvalidReference) {
+		return "", fmt.Errorf("error resolving the gatewayclass parameters: %w", err)
+	}
+	image, err := imageFunc(ctx, class, params)
+	if err != nil {
+		return "", fmt.Errorf("error getting the image of the gatewayclass: %w", err)
+	}
+	return image, nil
+}
+
+// waitRollout returns true when gw must keep its current image, as the canary
+// Gateways of its class did not soak the image yet, or too many Gateways of its
+// class are rolling out
+func (r *reconciler) waitRollout(ctx context.Context, logger logr.Logger, gw *gatewayv1.Gateway, image string) (bool, error) {
+	if r.options.Canary != nil {
+		gateways, err := classGateways(ctx, r.client, string(gw.Spec.GatewayClassName))
+		if err != nil {
+			return false, err
+		}
+		canaries := canaryGateways(string(gw.Spec.GatewayClassName), image, gateways, r.options.Canary)
+		if !slices.ContainsFunc(canaries, func(canary gatewayv1.Gateway) bool {
+			return client.ObjectKeyFromObject(&canary) == client.ObjectKeyFromObject(gw)
+		}) {
+			soaked, message, err := canaryProgress(ctx, r.client, r.options.Canary, canaries, image, time.Now())
+			if err != nil {
+				return false, err
+			}
+			if !soaked {
+				logger.V(2).Info("waiting for the canary gateways of the class", "image", image, "reason", message)
+				return true, nil
+			}
+		}
+	}
+
+	if r.options.MaxUnavailableGateways > 0 {
+		observed, err := r.rollingGateways(ctx, gw)
+		if err != nil {
+			return false, err
+		}
+		rolling, started := r.rollouts.start(string(gw.Spec.GatewayClassName), client.ObjectKeyFromObject(gw), image, observed, r.options.MaxUnavailableGateways)
+		if !started {
+			logger.V(2).Info("waiting for the rollout of the other gateways of the class", "image", image, "rolling", rolling)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// provisionedWorkload returns the Deployment, or DaemonSet, provisioned for the
+// dataplane, nil when it is not provisioned yet
+func provisionedWorkload(ctx context.Context, reader client.Reader, dp dataplane) (client.Object, error) {
+	key := types.NamespacedName{Namespace: dp.namespace, Name: dp.name}
+	deployment := &appsv1.Deployment{}
+	err := reader.Get(ctx, key, deployment)
+	switch {
+	case err == nil:
+		return deployment, nil
+	case !apierrors.IsNotFound(err):
+		return nil, fmt.Errorf("error getting the provisioned deployment: %w", err)
+	}
+	daemonSet := &appsv1.DaemonSet{}
+	if err := reader.Get(ctx, key, daemonSet); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting the provisioned daemonset: %w", err)
+	}
+	return daemonSet, nil
+}
+
+// workloadImage returns the image of the dataplane container of the provisioned
+// workload, empty when it is not provisioned yet
+func workloadImage(workload client.Object) string {
+	switch workload := workload.(type) {
+	case *appsv1.Deployment:
+		return containerImage(workload.Spec.Template.Spec)
+	case *appsv1.DaemonSet:
+		return containerImage(workload.Spec.Template.Spec)
+	}
+	return ""
+}
+
+// containerImage returns the image of the dataplane container of the pods
+func containerImage(spec corev1.PodSpec) string {
+	for _, container := range spec.Containers {
+		if container.Name == containerName {
+			return container.Image
+		}
+	}
+	return ""
+}
+
+// rollingGateways returns the other Gateways of the class of gw whose provisioned
+// workload on the cache did not finish its rollout
+func (r *reconciler) rollingGateways(ctx context.Context, gw *gatewayv1.Gateway) (map[types.NamespacedName]struct{}, error) {
+	gateways, err := classGateways(ctx, r.client, string(gw.Spec.GatewayClassName))
+	if err != nil {
+		return nil, err
+	}
+	class := make(map[types.NamespacedName]struct{}, len(gateways))
+	for i := range gateways {
+		if key := client.ObjectKeyFromObject(&gateways[i]); key != client.ObjectKeyFromObject(gw) {
+			class[key] = struct{}{}
+		}
+	}
+	ofClass := func(obj client.Object) bool {
+		owner := metav1.GetControllerOf(obj)
+		if owner == nil || owner.Kind != "Gateway" {
+			return false
+		}
+		_, ok := class[types.NamespacedName{Namespace: obj.GetNamespace(), Name: owner.Name}]
+		return ok
+	}
+
+	rolling := make(map[types.NamespacedName]struct{})
+	deployments := &appsv1.DeploymentList{}
+	if err := r.client.List(ctx, deployments, client.MatchingLabels{ManagedByLabel: ManagedByValue}); err != nil {
+		return nil, fmt.Errorf("error listing the provisioned deployments: %w", err)
+	}
+	for i := range deployments.Items {
+		if ready, _ := deploymentRolledOut(&deployments.Items[i]); !ready && ofClass(&deployments.Items[i]) {
+			rolling[types.NamespacedName{Namespace: deployments.Items[i].Namespace, Name: metav1.GetControllerOf(&deployments.Items[i]).Name}] = struct{}{}
+		}
+	}
+	daemonSets := &appsv1.DaemonSetList{}
+	if err := r.client.List(ctx, daemonSets, client.MatchingLabels{ManagedByLabel: ManagedByValue}); err != nil {
+		return nil, fmt.Errorf("error listing the provisioned daemonsets: %w", err)
+	}
+	for i := range daemonSets.Items {
+		if ready, _ := daemonSetRolledOut(&daemonSets.Items[i]); !ready && ofClass(&daemonSets.Items[i]) {
+			rolling[types.NamespacedName{Namespace: daemonSets.Items[i].Namespace, Name: metav1.GetControllerOf(&daemonSets.Items[i]).Name}] = struct{}{}
+		}
+	}
+	return rolling, nil
+}
+
+// rollouts tracks the Gateways whose dataplane was applied with a new image, by
+// class, until their workload is rolled out. The provisioned workloads on the
+// cache lag behind the applied ones, so counting only them lets concurrent
+// reconciliations roll out more than MaxUnavailableGateways
+type rollouts struct {
+	mu sync.Mutex
+	// classes are the images rolled out by the Gateways of each class
+	classes map[string]map[types.NamespacedName]string
+}
+
+// start tracks gw rolling out image, returning true, when fewer than limit other
+// Gateways of its class are rolling out, tracked or observed on the cache. It
+// returns the number of the other Gateways rolling out
+func (t *rollouts) start(class string, gw types.NamespacedName, image string, observed map[types.NamespacedName]struct{}, limit int) (int, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	tracked := t.classes[class]
+	rolling := 0
+	for key := range tracked {
+		if key != gw {
+			rolling++
+		}
+	}
+	for key := range observed {
+		if _, ok := tracked[key]; !ok && key != gw {
+			rolling++
+		}
+	}
+	if _, ok := tracked[gw]; !ok && rolling >= limit {
+		return rolling, false
+	}
+	if tracked == nil {
+		tracked = make(map[types.NamespacedName]string)
+		t.classes[class] = tracked
+	}
+	tracked[gw] = image
+	return rolling, true
+}
+
+// finish stops tracking the rollout of gw, once rolled out, deleted or not
+// provisioned anymore
+func (t *rollouts) finish(gw types.NamespacedName) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for class, tracked := range t.classes {
+		delete(tracked, gw)
+		if len(tracked) == 0 {
+			delete(t.classes, class)
+		}
+	}
+}
+
+// gatewaysOfClass enqueues the Gateways of a GatewayClass, so they are rolled out
+// when its image changes
+func gatewaysOfClass(reader client.Reader) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		gateways, err := classGateways(ctx, reader, obj.GetName())
+		if err != nil {
+			return nil
+		}
+		requests := make([]reconcile.Request, 0, len(gateways))
+		for i := range gateways {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&gateways[i])})
+		}
+		return requests
+	}
+}
+
+// classesOfParameters enqueues the GatewayClasses referencing a parameters
+// object of the kind gvk, or their Gateways when gateways is set
+func classesOfParameters(c client.Client, gvk schema.GroupVersionKind, gateways bool) handler.MapFunc {
+	ofClass := gatewaysOfClass(c)
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		classes, err := parameters.GatewayClassesOf(ctx, c, gvk, obj)
+		if err != nil {
+			return nil
+		}
+		var requests []reconcile.Request
+		for i := range classes {
+			if gateways {
+				requests = append(requests, ofClass(ctx, &classes[i])...)
+				continue
+			}
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: classes[i].GetName()}})
+		}
+		return requests
+	}
+}
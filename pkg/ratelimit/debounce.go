@@ -0,0 +1,56 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ratelimit
+
+import (
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// NewQueueFunc is the controller-runtime controller.Options NewQueue
+type NewQueueFunc func(controllerName string, rateLimiter workqueue.TypedRateLimiter[reconcile.Request]) workqueue.TypedRateLimitingInterface[reconcile.Request]
+
+// NewQueue returns the constructor of the workqueue debouncing the events by the
+// Debounce window, or nil for the controller-runtime default when it is not set
+func (o Options) NewQueue() NewQueueFunc {
+	if o.Debounce <= 0 {
+		return nil
+	}
+	return func(controllerName string, rateLimiter workqueue.TypedRateLimiter[reconcile.Request]) workqueue.TypedRateLimitingInterface[reconcile.Request] {
+		return &debouncingQueue{
+			TypedRateLimitingInterface: workqueue.NewTypedRateLimitingQueueWithConfig(rateLimiter, workqueue.TypedRateLimitingQueueConfig[reconcile.Request]{
+				Name: controllerName,
+			}),
+			window: o.Debounce,
+		}
+	}
+}
+
+// debouncingQueue delays the requests added by the event handlers by the window.
+// The delaying queue keeps a single entry per request, so all the events of the
+// window collapse into one reconciliation. The retries and the requeues are not
+// delayed further
+type debouncingQueue struct {
+	workqueue.TypedRateLimitingInterface[reconcile.Request]
+	window time.Duration
+}
+
+func (q *debouncingQueue) Add(item reconcile.Request) {
+	q.AddAfter(item, q.window)
+}
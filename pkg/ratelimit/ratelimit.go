@@ -0,0 +1,91 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The package ratelimit configures the workqueue rate limiter and the event
+// debouncing of the kgame controllers.
+package ratelimit
+
+import (
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// DefaultBaseDelay is the delay of the first retry of a failed reconciliation
+	DefaultBaseDelay = 5 * time.Millisecond
+	// DefaultMaxDelay is the maximum delay of the retries of a failed
+	// reconciliation
+	DefaultMaxDelay = 1000 * time.Second
+	// DefaultQPS and DefaultBurst limit the overall rate of reconciliations
+	DefaultQPS   = 10
+	DefaultBurst = 100
+)
+
+// Options configures the rate limiter of a controller. The zero value keeps the
+// controller-runtime defaults
+type Options struct {
+	// RateLimiter replaces the rate limiter of the controller. When set, the
+	// other options are ignored
+	RateLimiter workqueue.TypedRateLimiter[reconcile.Request]
+	// BaseDelay and MaxDelay are the bounds of the exponential backoff of failed
+	// reconciliations, like when the programming backend is slow or unavailable.
+	// Default to DefaultBaseDelay and DefaultMaxDelay
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// QPS and Burst limit the overall rate of reconciliations. Default to
+	// DefaultQPS and DefaultBurst
+	QPS   float64
+	Burst int
+
+	// Debounce delays the reconciliations requested by the events by the given
+	// window, so bursts of events for the same object, like hundreds of routes
+	// applied at once to the same Gateway, collapse into one reconciliation.
+	// Disabled when zero
+	Debounce time.Duration
+}
+
+// New returns the rate limiter of the options, or nil for the controller-runtime
+// default when no option is set
+func (o Options) New() workqueue.TypedRateLimiter[reconcile.Request] {
+	if o.RateLimiter != nil {
+		return o.RateLimiter
+	}
+	if o.BaseDelay == 0 && o.MaxDelay == 0 && o.QPS == 0 && o.Burst == 0 {
+		return nil
+	}
+
+	baseDelay, maxDelay := o.BaseDelay, o.MaxDelay
+	if baseDelay <= 0 {
+		baseDelay = DefaultBaseDelay
+	}
+	if maxDelay <= 0 {
+		maxDelay = DefaultMaxDelay
+	}
+	qps, burst := o.QPS, o.Burst
+	if qps <= 0 {
+		qps = DefaultQPS
+	}
+	if burst <= 0 {
+		burst = DefaultBurst
+	}
+	return workqueue.NewTypedMaxOfRateLimiter(
+		workqueue.NewTypedItemExponentialFailureRateLimiter[reconcile.Request](baseDelay, maxDelay),
+		&workqueue.TypedBucketRateLimiter[reconcile.Request]{Limiter: rate.NewLimiter(rate.Limit(qps), burst)},
+	)
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The package reload holds the settings of the kgame controllers that can be
+// changed while they are running, like from the KgameConfig resource.
+package reload
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Settings are the runtime settings of a controller. The controller runs its
+// workers up to the ceiling, and Settings limits how many of them reconcile at
+// the same time. A nil Settings keeps the settings the controller was built with
+type Settings struct {
+	mu                      sync.Mutex
+	ceiling                 int
+	maxConcurrentReconciles int
+	resyncPeriod            time.Duration
+	active                  int
+	// changed is closed, and replaced, when a worker may be allowed to run
+	changed chan struct{}
+}
+
+// NewSettings returns the Settings of a controller running up to ceiling workers,
+// starting with the given concurrency and resync period
+func NewSettings(ceiling, maxConcurrentReconciles int, resyncPeriod time.Duration) *Settings {
+	if ceiling < 1 {
+		ceiling = 1
+	}
+	s := &Settings{
+		ceiling:      ceiling,
+		resyncPeriod: resyncPeriod,
+		changed:      make(chan struct{}),
+	}
+	s.SetMaxConcurrentReconciles(maxConcurrentReconciles)
+	return s
+}
+
+// Ceiling is the number of workers of the controller
+func (s *Settings) Ceiling() int {
+	return s.ceiling
+}
+
+// SetMaxConcurrentReconciles changes the number of resources reconciled in
+// parallel. Values below 1 are set as 1, and values above the ceiling as the
+// ceiling. Reconciliations already running are not interrupted
+func (s *Settings) SetMaxConcurrentReconciles(n int) {
+	n = max(1, min(n, s.ceiling))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.maxConcurrentReconciles = n
+	s.broadcast()
+}
+
+// MaxConcurrentReconciles returns the number of resources reconciled in parallel
+func (s *Settings) MaxConcurrentReconciles() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.maxConcurrentReconciles
+}
+
+// SetResyncPeriod changes the resync period of the controller. It applies to the
+// reconciliations finished after the change
+func (s *Settings) SetResyncPeriod(period time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.resyncPeriod = period
+}
+
+// ResyncPeriod returns the resync period of the controller
+func (s *Settings) ResyncPeriod() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.resyncPeriod
+}
+
+// Wrap returns rec limited to MaxConcurrentReconciles parallel reconciliations.
+// It returns rec when s is nil
+func (s *Settings) Wrap(rec reconcile.Reconciler) reconcile.Reconciler {
+	if s == nil {
+		return rec
+	}
+	return reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+		if err := s.acquire(ctx); err != nil {
+			return reconcile.Result{}, err
+		}
+		defer s.release()
+		return rec.Reconcile(ctx, req)
+	})
+}
+
+// acquire waits until a reconciliation is allowed to run, or ctx is done
+func (s *Settings) acquire(ctx context.Context) error {
+	s.mu.Lock()
+	for s.active >= s.maxConcurrentReconciles {
+		changed := s.changed
+		s.mu.Unlock()
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		s.mu.Lock()
+	}
+	s.active++
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Settings) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.active--
+	s.broadcast()
+}
+
+// broadcast wakes up the waiting workers. It must be called with mu held
+func (s *Settings) broadcast() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
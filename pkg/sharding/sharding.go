@@ -0,0 +1,121 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The package sharding partitions the reconciliations across active replicas of
+// kgame, for deployments where a single leader cannot keep up with the volume of
+// reconciliations.
+package sharding
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Key is what the resources are partitioned by
+type Key string
+
+const (
+	// KeyNamespace assigns all the resources of a namespace to the same replica.
+	// It is the default
+	KeyNamespace Key = "Namespace"
+	// KeyName assigns each resource to a replica by its namespace and name,
+	// spreading the resources of busy namespaces
+	KeyName Key = "Name"
+)
+
+// Options configures the partition of the reconciliations. Each replica
+// reconciles the resources whose key hash matches its Ordinal, and ignores the
+// others. The cluster scoped resources, like the GatewayClasses, are partitioned
+// by name
+type Options struct {
+	// Replicas is the number of active replicas. Sharding is disabled when it is
+	// lower than 2
+	Replicas int
+	// Ordinal of this replica, from 0 to Replicas-1, like the ordinal of a
+	// StatefulSet pod. See OrdinalFromHostname
+	Ordinal int
+	// Key is what the resources are partitioned by. Defaults to KeyNamespace
+	Key Key
+}
+
+// Enabled returns true if the reconciliations are partitioned
+func (o *Options) Enabled() bool {
+	return o != nil && o.Replicas > 1
+}
+
+// Validate returns an error if the options are not valid
+func (o *Options) Validate() error {
+	if !o.Enabled() {
+		return nil
+	}
+	if o.Ordinal < 0 || o.Ordinal >= o.Replicas {
+		return fmt.Errorf("ordinal must be between 0 and %d, got %d", o.Replicas-1, o.Ordinal)
+	}
+	switch o.Key {
+	case "", KeyNamespace, KeyName:
+		return nil
+	}
+	return fmt.Errorf("unsupported key %q, supported keys are %q and %q", o.Key, KeyNamespace, KeyName)
+}
+
+// Owns returns true if the request is reconciled by this replica
+func (o *Options) Owns(req reconcile.Request) bool {
+	if !o.Enabled() {
+		return true
+	}
+	key := req.Namespace
+	if o.Key == KeyName || key == "" {
+		key = req.String()
+	}
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(key))
+	return int(hash.Sum32()%uint32(o.Replicas)) == o.Ordinal
+}
+
+// Wrap returns rec skipping the requests not owned by this replica. It returns
+// rec when the sharding is not enabled
+func (o *Options) Wrap(rec reconcile.Reconciler) reconcile.Reconciler {
+	if !o.Enabled() {
+		return rec
+	}
+	return reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+		if !o.Owns(req) {
+			return reconcile.Result{}, nil
+		}
+		return rec.Reconcile(ctx, req)
+	})
+}
+
+// OrdinalFromHostname returns the ordinal of a StatefulSet pod, the number after
+// the last dash of its hostname, like 2 for kgame-2
+func OrdinalFromHostname() (int, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return 0, fmt.Errorf("error getting the hostname: %w", err)
+	}
+	i := strings.LastIndex(hostname, "-")
+	ordinal, err := strconv.Atoi(hostname[i+1:])
+	if i < 0 || err != nil || ordinal < 0 {
+		return 0, fmt.Errorf("hostname %q does not end with an ordinal", hostname)
+	}
+	return ordinal, nil
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunables
+
+import (
+	"sync"
+)
+
+// GatewayClassSet is the live set of the GatewayClasses seen by the cache, and
+// whether they are managed by kgame. It is fed by TransformGatewayClass, and read
+// by TransformGateway. The controllerName of a GatewayClass cannot change, so a
+// class seen as not managed stays not managed
+type GatewayClassSet struct {
+	mu      sync.RWMutex
+	classes map[string]bool
+}
+
+// NewGatewayClassSet returns an empty GatewayClassSet
+func NewGatewayClassSet() *GatewayClassSet {
+	return &GatewayClassSet{classes: make(map[string]bool)}
+}
+
+// Set records whether the GatewayClass name is managed
+func (s *GatewayClassSet) Set(name string, managed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.classes[name] = managed
+}
+
+// Managed returns whether the GatewayClass name is managed, and false for known
+// when the class was not seen yet
+func (s *GatewayClassSet) Managed(name string) (managed, known bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	managed, known = s.classes[name]
+	return managed, known
+}
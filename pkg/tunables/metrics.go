@@ -0,0 +1,123 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunables
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/runtime"
+	toolscache "k8s.io/client-go/tools/cache"
+	ctrlcache "sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	cacheObjects = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kgame_cache_objects",
+		Help: "Number of objects on the kgame cache, by kind",
+	}, []string{"group", "version", "kind"})
+	cacheBytes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kgame_cache_bytes",
+		Help: "Approximate size of the objects on the kgame cache, by kind, as their JSON encoding",
+	}, []string{"group", "version", "kind"})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(cacheObjects, cacheBytes)
+}
+
+// CacheMetrics periodically exports the number and approximate size of the
+// cached objects of each kind, so the effect of the transforms can be verified.
+// It is a manager Runnable, running on all the replicas
+type CacheMetrics struct {
+	cache    ctrlcache.Cache
+	scheme   *runtime.Scheme
+	logger   logr.Logger
+	interval time.Duration
+	objects  []client.Object
+}
+
+// NewCacheMetrics returns the CacheMetrics of the kinds of objects, measured
+// every interval. Measuring a kind starts its informer, if it is not started yet
+func NewCacheMetrics(cache ctrlcache.Cache, scheme *runtime.Scheme, logger logr.Logger, interval time.Duration, objects ...client.Object) *CacheMetrics {
+	return &CacheMetrics{
+		cache:    cache,
+		scheme:   scheme,
+		logger:   logger.WithName("cache-metrics"),
+		interval: interval,
+		objects:  objects,
+	}
+}
+
+// NeedLeaderElection returns false, as each replica has its own cache
+func (m *CacheMetrics) NeedLeaderElection() bool {
+	return false
+}
+
+// Start measures the cache every interval, until ctx is done
+func (m *CacheMetrics) Start(ctx context.Context) error {
+	if !m.cache.WaitForCacheSync(ctx) {
+		return nil
+	}
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		m.measure(ctx)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// measure updates the metrics of each kind. The objects are read from the
+// informer store, without copying them
+func (m *CacheMetrics) measure(ctx context.Context) {
+	for _, obj := range m.objects {
+		gvk, err := apiutil.GVKForObject(obj, m.scheme)
+		if err != nil {
+			m.logger.Error(err, "error getting the kind of the cached object")
+			continue
+		}
+		informer, err := m.cache.GetInformer(ctx, obj)
+		if err != nil {
+			m.logger.Error(err, "error getting the informer", "kind", gvk.Kind)
+			continue
+		}
+		storer, ok := informer.(interface{ GetStore() toolscache.Store })
+		if !ok {
+			continue
+		}
+		var size int
+		items := storer.GetStore().List()
+		for _, item := range items {
+			encoded, err := json.Marshal(item)
+			if err == nil {
+				size += len(encoded)
+			}
+		}
+		cacheObjects.WithLabelValues(gvk.Group, gvk.Version, gvk.Kind).Set(float64(len(items)))
+		cacheBytes.WithLabelValues(gvk.Group, gvk.Version, gvk.Kind).Set(float64(size))
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunables
+
+import (
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// RouteTransformOptions configures the routes dropped by TransformRoute
+type RouteTransformOptions struct {
+	// DropNonGatewayParents drops the routes without any parentRef to a Gateway,
+	// like the mesh routes attached to Services
+	DropNonGatewayParents bool
+	// ManagedGateway drops the routes without any parentRef to a Gateway it
+	// returns true for, like the Gateways following a naming convention of the
+	// implementation. The result must not change over time: a dropped route is
+	// only cached again on its next change, or the next full resync
+	ManagedGateway func(gateway types.NamespacedName) bool
+}
+
+// TransformRoute is a cache transformation function that should be applied to
+// HTTPRoute and GRPCRoute. It will:
+// 1. Drop from the cache the routes that cannot target a managed Gateway, as
+// configured by the options
+// 2. Strip managedfields and the last applied annotation from the resource before
+// storing on cache, to save some memory
+func TransformRoute(options RouteTransformOptions) cache.TransformFunc {
+	return Chain(options.dropRoute, StripManagedFields(), StripLastAppliedAnnotation())
+}
+
+// dropRoute drops the routes whose parentRefs cannot target a managed Gateway
+func (o RouteTransformOptions) dropRoute(i any) (any, error) {
+	if !o.DropNonGatewayParents && o.ManagedGateway == nil {
+		return i, nil
+	}
+	var namespace string
+	var parentRefs []gatewayv1.ParentReference
+	switch route := i.(type) {
+	case *gatewayv1.HTTPRoute:
+		namespace, parentRefs = route.GetNamespace(), route.Spec.ParentRefs
+	case *gatewayv1.GRPCRoute:
+		namespace, parentRefs = route.GetNamespace(), route.Spec.ParentRefs
+	default:
+		return i, nil
+	}
+	for _, parentRef := range parentRefs {
+		if parentRef.Group != nil && *parentRef.Group != gatewayv1.GroupName {
+			continue
+		}
+		if parentRef.Kind != nil && *parentRef.Kind != "Gateway" {
+			continue
+		}
+		if o.ManagedGateway == nil {
+			return i, nil
+		}
+		gateway := types.NamespacedName{Namespace: namespace, Name: string(parentRef.Name)}
+		if parentRef.Namespace != nil {
+			gateway.Namespace = string(*parentRef.Namespace)
+		}
+		if o.ManagedGateway(gateway) {
+			return i, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunables
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// tlsSecretKeys are the Secret data keys kept by TransformTLSSecret
+var tlsSecretKeys = []string{corev1.TLSCertKey, corev1.TLSPrivateKeyKey, corev1.ServiceAccountRootCAKey}
+
+// TransformTLSSecret is a cache transformation function that should be applied
+// to Secret, when kgame and the implementer only read the listener
+// certificates. It will:
+// 1. Drop from the cache the Secrets not of type kubernetes.io/tls, if dropNonTLS
+// is true
+// 2. Strip the data of the resource, except the tls.crt, tls.key and ca.crt keys
+// 3. Strip managedfields and the last applied annotation, a copy of the whole
+// Secret when it is applied by kubectl
+func TransformTLSSecret(dropNonTLS bool) cache.TransformFunc {
+	transforms := []cache.TransformFunc{stripNonTLSData, StripManagedFields(), StripLastAppliedAnnotation()}
+	if dropNonTLS {
+		transforms = append([]cache.TransformFunc{DropIf(isNonTLSSecret)}, transforms...)
+	}
+	return Chain(transforms...)
+}
+
+// TLSSecretFieldSelector returns the field selector of the Secrets of type
+// kubernetes.io/tls, so the other Secrets, like the service account tokens, are
+// never listed nor cached
+func TLSSecretFieldSelector() fields.Selector {
+	return fields.OneTermEqualSelector("type", string(corev1.SecretTypeTLS))
+}
+
+func isNonTLSSecret(obj client.Object) bool {
+	secret, ok := obj.(*corev1.Secret)
+	return ok && secret.Type != corev1.SecretTypeTLS
+}
+
+func stripNonTLSData(i any) (any, error) {
+	secret, ok := i.(*corev1.Secret)
+	if !ok {
+		return i, nil
+	}
+	data := make(map[string][]byte, len(tlsSecretKeys))
+	for _, key := range tlsSecretKeys {
+		if value, ok := secret.Data[key]; ok {
+			data[key] = value
+		}
+	}
+	secret.Data = data
+	secret.StringData = nil
+	return secret, nil
+}
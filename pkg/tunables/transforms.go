@@ -0,0 +1,85 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunables
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Chain returns a cache transformation function applying the transforms in
+// order, like Chain(StripManagedFields(), StripLastAppliedAnnotation()). Once a
+// transform drops the object, or returns an error, the following ones are not
+// called. Empty transforms are skipped
+func Chain(transforms ...cache.TransformFunc) cache.TransformFunc {
+	return func(i any) (any, error) {
+		obj := i
+		for _, transform := range transforms {
+			if transform == nil {
+				continue
+			}
+			var err error
+			obj, err = transform(obj)
+			if err != nil || obj == nil {
+				return obj, err
+			}
+		}
+		return obj, nil
+	}
+}
+
+// StripManagedFields returns a cache transformation function removing the
+// managedFields of the objects, usually the biggest part of their metadata
+func StripManagedFields() cache.TransformFunc {
+	return func(i any) (any, error) {
+		if obj, ok := i.(client.Object); ok {
+			obj.SetManagedFields(nil)
+		}
+		return i, nil
+	}
+}
+
+// StripLastAppliedAnnotation returns a cache transformation function removing
+// the kubectl.kubernetes.io/last-applied-configuration annotation, a full copy
+// of the object applied by kubectl
+func StripLastAppliedAnnotation() cache.TransformFunc {
+	return func(i any) (any, error) {
+		obj, ok := i.(client.Object)
+		if !ok {
+			return i, nil
+		}
+		annotations := obj.GetAnnotations()
+		if _, ok := annotations[corev1.LastAppliedConfigAnnotation]; ok {
+			delete(annotations, corev1.LastAppliedConfigAnnotation)
+			obj.SetAnnotations(annotations)
+		}
+		return i, nil
+	}
+}
+
+// DropIf returns a cache transformation function dropping from the cache the
+// objects matching the predicate, like the resources of other implementations.
+// The dropped objects are invisible to kgame, and are never reconciled
+func DropIf(predicate func(obj client.Object) bool) cache.TransformFunc {
+	return func(i any) (any, error) {
+		if obj, ok := i.(client.Object); ok && predicate(obj) {
+			return nil, nil
+		}
+		return i, nil
+	}
+}
@@ -17,25 +17,39 @@ limitations under the License.
 package tunables
 
 import (
+	"slices"
+
 	"github.com/go-logr/logr"
 	"k8s.io/client-go/tools/cache"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 )
 
 type tunables struct {
-	logger      logr.Logger
-	gwClassName gatewayv1.GatewayController
+	logger       logr.Logger
+	gwClassNames []gatewayv1.GatewayController
+	classes      *GatewayClassSet
 }
 
 type TunableConfig struct {
 	Logger           logr.Logger
 	GatewayClassName gatewayv1.GatewayController
+	// AdditionalGatewayClassNames are the other controllerNames whose
+	// GatewayClasses are kept on the cache
+	AdditionalGatewayClassNames []gatewayv1.GatewayController
+	// Classes receives the GatewayClasses seen by TransformGatewayClass. Defaults
+	// to a new GatewayClassSet
+	Classes *GatewayClassSet
 }
 
 func NewTunables(config TunableConfig) *tunables {
+	classes := config.Classes
+	if classes == nil {
+		classes = NewGatewayClassSet()
+	}
 	return &tunables{
-		logger:      config.Logger,
-		gwClassName: config.GatewayClassName,
+		logger:       config.Logger,
+		gwClassNames: append([]gatewayv1.GatewayController{config.GatewayClassName}, config.AdditionalGatewayClassNames...),
+		classes:      classes,
 	}
 }
 
@@ -45,20 +59,48 @@ func NewTunables(config TunableConfig) *tunables {
 // controller
 // 2. Strip managedfields from the resource before storing on cache, to save some memory
 func (t *tunables) TransformGatewayClass() cache.TransformFunc {
-	return func(i any) (any, error) {
-		logger := t.logger.WithName("gwclass-transform")
-		gwclass, ok := i.(*gatewayv1.GatewayClass)
-		if !ok {
-			logger.Info("ignoring object as it is not a gateway class")
-			return nil, nil
-		}
-		// Drop the object from cache if we don't care about it
-		if gwclass.Spec.ControllerName != t.gwClassName {
-			logger.Info("ignoring object with unknown class", "name", gwclass.GetName())
-			return nil, nil
-		}
-		// Clean managed fields for some memory economy
-		gwclass.SetManagedFields(nil)
-		return gwclass, nil
+	return Chain(t.dropUnknownGatewayClass, StripManagedFields())
+}
+
+// dropUnknownGatewayClass drops the objects that are not a GatewayClass of the
+// managed controllerNames
+func (t *tunables) dropUnknownGatewayClass(i any) (any, error) {
+	logger := t.logger.WithName("cache-transform").WithValues("kind", "GatewayClass")
+	gwclass, ok := i.(*gatewayv1.GatewayClass)
+	if !ok {
+		logger.Info("ignoring object as it is not a gateway class")
+		return nil, nil
+	}
+	// Drop the object from cache if we don't care about it
+	managed := slices.Contains(t.gwClassNames, gwclass.Spec.ControllerName)
+	t.classes.Set(gwclass.GetName(), managed)
+	if !managed {
+		logger.Info("ignoring object with unknown class", "name", gwclass.GetName())
+		return nil, nil
+	}
+	return gwclass, nil
+}
+
+// TransformGateway is a cache transformation function that should be applied to
+// Gateway. It will:
+// 1. Ignore and drop from the cache a Gateway whose GatewayClass was seen by
+// TransformGatewayClass as not belonging to this controller. The Gateways of
+// classes not seen yet are kept, and filtered by the Gateway controller
+// 2. Strip managedfields from the resource before storing on cache, to save some memory
+func (t *tunables) TransformGateway() cache.TransformFunc {
+	return Chain(t.dropUnmanagedGateway, StripManagedFields())
+}
+
+// dropUnmanagedGateway drops the Gateways of the GatewayClasses known to be not
+// managed
+func (t *tunables) dropUnmanagedGateway(i any) (any, error) {
+	gw, ok := i.(*gatewayv1.Gateway)
+	if !ok {
+		return i, nil
+	}
+	if managed, known := t.classes.Managed(string(gw.Spec.GatewayClassName)); known && !managed {
+		t.logger.WithName("cache-transform").WithValues("kind", "Gateway").V(4).Info("ignoring gateway of unknown class", "name", gw.GetName(), "namespace", gw.GetNamespace(), "gatewayclass", gw.Spec.GatewayClassName)
+		return nil, nil
 	}
+	return gw, nil
 }
@@ -0,0 +1,346 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/go-logr/logr"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// defaultCertValidity is the validity of the bootstrapped certificate when
+	// none is configured
+	defaultCertValidity = 365 * 24 * time.Hour
+	// certCheckInterval is the period of the webhook certificate checks
+	certCheckInterval = time.Hour
+)
+
+// CertBootstrapOptions configures the bootstrap of the webhook server certificate.
+// The certificate is kept on a Secret, so all the replicas serve the same
+// certificate. It is generated again when it is expired, has a third of its
+// validity left, or was not issued for the Service DNS names. The previous CA
+// stays on the CA bundle, so the replicas still serving the previous certificate
+// are trusted until they load the new one. Each replica checks the Secret every
+// hour
+type CertBootstrapOptions struct {
+	// ServiceName and ServiceNamespace are the Service exposing the webhook server,
+	// used on the certificate DNS names
+	ServiceName      string
+	ServiceNamespace string
+	// SecretName is the Secret, on ServiceNamespace, storing the certificate
+	SecretName string
+	// ValidatingWebhookConfiguration and MutatingWebhookConfiguration are the
+	// webhook configurations receiving the CA bundle. Empty names are skipped
+	ValidatingWebhookConfiguration string
+	MutatingWebhookConfiguration   string
+	// Validity of the generated certificate. Defaults to one year
+	Validity time.Duration
+}
+
+// bootstrapCertificate gets, or generates, the webhook server certificate, writes
+// it to the CertDir and injects its CA on the webhook configurations. It runs
+// before the manager is started, so it uses a client without cache
+func bootstrapCertificate(ctx context.Context, config *rest.Config, options Options) error {
+	bootstrap := options.CertBootstrap
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		return err
+	}
+	if err := admissionregistrationv1.AddToScheme(scheme); err != nil {
+		return err
+	}
+	c, err := client.New(config, client.Options{Scheme: scheme})
+	if err != nil {
+		return fmt.Errorf("error creating the certificate bootstrap client: %w", err)
+	}
+
+	secret, err := certificateSecret(ctx, c, bootstrap)
+	if err != nil {
+		return err
+	}
+
+	certDir := options.CertDir
+	if certDir == "" {
+		certDir = filepath.Join(os.TempDir(), "k8s-webhook-server", "serving-certs")
+	}
+	if err := os.MkdirAll(certDir, 0o700); err != nil {
+		return fmt.Errorf("error creating the certificate directory: %w", err)
+	}
+	for file, key := range map[string]string{"tls.crt": corev1.TLSCertKey, "tls.key": corev1.TLSPrivateKeyKey} {
+		path := filepath.Join(certDir, file)
+		// The webhook server reloads the files when they change
+		if current, err := os.ReadFile(path); err == nil && bytes.Equal(current, secret.Data[key]) {
+			continue
+		}
+		if err := os.WriteFile(path, secret.Data[key], 0o600); err != nil {
+			return fmt.Errorf("error writing the webhook certificate: %w", err)
+		}
+	}
+
+	return injectCABundle(ctx, c, bootstrap, secret.Data["ca.crt"])
+}
+
+// certRotator checks the webhook certificate every certCheckInterval, see
+// CertBootstrapOptions
+type certRotator struct {
+	config  *rest.Config
+	options Options
+	logger  logr.Logger
+}
+
+// NeedLeaderElection returns false, as each replica serves the certificate
+func (r *certRotator) NeedLeaderElection() bool {
+	return false
+}
+
+// Start checks the certificate every certCheckInterval, until ctx is done
+func (r *certRotator) Start(ctx context.Context) error {
+	ticker := time.NewTicker(certCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		if err := bootstrapCertificate(ctx, r.config, r.options); err != nil {
+			r.logger.Error(err, "unable to check the webhook certificate")
+		}
+	}
+}
+
+// certificateSecret returns the Secret with the webhook certificate, creating it
+// when it does not exist and generating it again when it is not current
+func certificateSecret(ctx context.Context, c client.Client, bootstrap *CertBootstrapOptions) (*corev1.Secret, error) {
+	key := types.NamespacedName{Namespace: bootstrap.ServiceNamespace, Name: bootstrap.SecretName}
+	secret := &corev1.Secret{}
+	err := c.Get(ctx, key, secret)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("error getting the webhook certificate secret: %w", err)
+	}
+	exists := err == nil
+	if exists && currentCertificate(secret, bootstrap, time.Now()) {
+		return secret, nil
+	}
+
+	validity := bootstrap.Validity
+	if validity <= 0 {
+		validity = defaultCertValidity
+	}
+	ca, cert, privateKey, err := generateCertificate(bootstrap.ServiceName, bootstrap.ServiceNamespace, validity)
+	if err != nil {
+		return nil, fmt.Errorf("error generating the webhook certificate: %w", err)
+	}
+	data := map[string][]byte{
+		"ca.crt":                append(ca, previousCA(secret, time.Now())...),
+		corev1.TLSCertKey:       cert,
+		corev1.TLSPrivateKeyKey: privateKey,
+	}
+	if exists {
+		secret.Data = data
+		if err := c.Update(ctx, secret); err != nil {
+			if !apierrors.IsConflict(err) {
+				return nil, fmt.Errorf("error updating the webhook certificate secret: %w", err)
+			}
+			// Another replica generated the certificate first
+			if err := c.Get(ctx, key, secret); err != nil {
+				return nil, fmt.Errorf("error getting the webhook certificate secret: %w", err)
+			}
+		}
+		return secret, nil
+	}
+	secret = &corev1.Secret{
+		Type: corev1.SecretTypeTLS,
+		Data: data,
+	}
+	secret.SetNamespace(key.Namespace)
+	secret.SetName(key.Name)
+	if err := c.Create(ctx, secret); err != nil {
+		if !apierrors.IsAlreadyExists(err) {
+			return nil, fmt.Errorf("error creating the webhook certificate secret: %w", err)
+		}
+		// Another replica created the certificate first
+		if err := c.Get(ctx, key, secret); err != nil {
+			return nil, fmt.Errorf("error getting the webhook certificate secret: %w", err)
+		}
+	}
+	return secret, nil
+}
+
+// currentCertificate returns true if the serving certificate of the secret is
+// valid for the Service DNS names, and has more than a third of its validity left
+func currentCertificate(secret *corev1.Secret, bootstrap *CertBootstrapOptions, now time.Time) bool {
+	if len(secret.Data[corev1.TLSPrivateKeyKey]) == 0 || len(secret.Data["ca.crt"]) == 0 {
+		return false
+	}
+	cert, err := parseCertificate(secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		return false
+	}
+	if !now.Before(cert.NotAfter.Add(-cert.NotAfter.Sub(cert.NotBefore) / 3)) {
+		return false
+	}
+	for _, name := range serviceDNSNames(bootstrap.ServiceName, bootstrap.ServiceNamespace) {
+		if !slices.Contains(cert.DNSNames, name) {
+			return false
+		}
+	}
+	return true
+}
+
+// previousCA returns the CA of the secret, PEM encoded, when it is not expired,
+// so the certificates it issued are still trusted while the replicas load the
+// new one
+func previousCA(secret *corev1.Secret, now time.Time) []byte {
+	block, _ := pem.Decode(secret.Data["ca.crt"])
+	if block == nil {
+		return nil
+	}
+	ca, err := x509.ParseCertificate(block.Bytes)
+	if err != nil || !now.Before(ca.NotAfter) {
+		return nil
+	}
+	return encodePEM("CERTIFICATE", block.Bytes)
+}
+
+// parseCertificate parses the first certificate of a PEM bundle
+func parseCertificate(data []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// serviceDNSNames are the DNS names of the Service on the serving certificate
+func serviceDNSNames(service, namespace string) []string {
+	return []string{
+		service,
+		fmt.Sprintf("%s.%s", service, namespace),
+		fmt.Sprintf("%s.%s.svc", service, namespace),
+		fmt.Sprintf("%s.%s.svc.cluster.local", service, namespace),
+	}
+}
+
+// injectCABundle sets the CA bundle on all the webhooks of the configurations
+func injectCABundle(ctx context.Context, c client.Client, bootstrap *CertBootstrapOptions, caBundle []byte) error {
+	if name := bootstrap.ValidatingWebhookConfiguration; name != "" {
+		config := &admissionregistrationv1.ValidatingWebhookConfiguration{}
+		if err := c.Get(ctx, types.NamespacedName{Name: name}, config); err != nil {
+			return fmt.Errorf("error getting the validating webhook configuration: %w", err)
+		}
+		original := config.DeepCopy()
+		for i := range config.Webhooks {
+			config.Webhooks[i].ClientConfig.CABundle = caBundle
+		}
+		if err := c.Patch(ctx, config, client.MergeFrom(original)); err != nil {
+			return fmt.Errorf("error injecting the CA on the validating webhook configuration: %w", err)
+		}
+	}
+
+	if name := bootstrap.MutatingWebhookConfiguration; name != "" {
+		config := &admissionregistrationv1.MutatingWebhookConfiguration{}
+		if err := c.Get(ctx, types.NamespacedName{Name: name}, config); err != nil {
+			return fmt.Errorf("error getting the mutating webhook configuration: %w", err)
+		}
+		original := config.DeepCopy()
+		for i := range config.Webhooks {
+			config.Webhooks[i].ClientConfig.CABundle = caBundle
+		}
+		if err := c.Patch(ctx, config, client.MergeFrom(original)); err != nil {
+			return fmt.Errorf("error injecting the CA on the mutating webhook configuration: %w", err)
+		}
+	}
+	return nil
+}
+
+// generateCertificate generates a self signed CA and a serving certificate for
+// the Service DNS names, returning them PEM encoded
+func generateCertificate(service, namespace string, validity time.Duration) (ca, cert, key []byte, err error) {
+	notBefore := time.Now().Add(-time.Hour)
+	notAfter := notBefore.Add(validity)
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "kgame-webhook-ca"},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	servingKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	servingTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: fmt.Sprintf("%s.%s.svc", service, namespace)},
+		DNSNames:     serviceDNSNames(service, namespace),
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	servingDER, err := x509.CreateCertificate(rand.Reader, servingTemplate, caTemplate, &servingKey.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	servingKeyDER, err := x509.MarshalECPrivateKey(servingKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return encodePEM("CERTIFICATE", caDER), encodePEM("CERTIFICATE", servingDER), encodePEM("EC PRIVATE KEY", servingKeyDER), nil
+}
+
+func encodePEM(blockType string, der []byte) []byte {
+	var buf bytes.Buffer
+	_ = pem.Encode(&buf, &pem.Block{Type: blockType, Bytes: der})
+	return buf.Bytes()
+}
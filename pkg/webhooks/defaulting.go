@@ -0,0 +1,66 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// gatewayDefaulter is the mutating webhook of the Gateways
+type gatewayDefaulter struct {
+	*validator
+	defaulter func(ctx context.Context, gw *gatewayv1.Gateway) error
+}
+
+var _ admission.CustomDefaulter = &gatewayDefaulter{}
+
+func (d *gatewayDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	gw, ok := obj.(*gatewayv1.Gateway)
+	if !ok {
+		return fmt.Errorf("expected a Gateway, got %T", obj)
+	}
+	managed, err := d.managedGateway(ctx, gw)
+	if err != nil || !managed {
+		return err
+	}
+	return d.defaulter(ctx, gw)
+}
+
+// httpRouteDefaulter is the mutating webhook of the HTTPRoutes
+type httpRouteDefaulter struct {
+	*validator
+	defaulter func(ctx context.Context, route *gatewayv1.HTTPRoute) error
+}
+
+var _ admission.CustomDefaulter = &httpRouteDefaulter{}
+
+func (d *httpRouteDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	route, ok := obj.(*gatewayv1.HTTPRoute)
+	if !ok {
+		return fmt.Errorf("expected an HTTPRoute, got %T", obj)
+	}
+	managed, err := d.managedRoute(ctx, route.GetNamespace(), route.Spec.ParentRefs)
+	if err != nil || !managed {
+		return err
+	}
+	return d.defaulter(ctx, route)
+}
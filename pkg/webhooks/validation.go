@@ -0,0 +1,185 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// validator checks the managed resources against the declared capabilities
+type validator struct {
+	client          client.Client
+	controllerNames []gatewayv1.GatewayController
+	capabilities    Capabilities
+}
+
+// managedGateway returns true if the Gateway belongs to a managed GatewayClass.
+// The GatewayClass cache only contains managed classes, so a class that is not
+// found is not managed
+func (v *validator) managedGateway(ctx context.Context, gw *gatewayv1.Gateway) (bool, error) {
+	gatewayClass := &gatewayv1.GatewayClass{}
+	if err := v.client.Get(ctx, types.NamespacedName{Name: string(gw.Spec.GatewayClassName)}, gatewayClass); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return slices.Contains(v.controllerNames, gatewayClass.Spec.ControllerName), nil
+}
+
+func (v *validator) validateGateway(gw *gatewayv1.Gateway) field.ErrorList {
+	var errs field.ErrorList
+	if len(v.capabilities.Protocols) > 0 {
+		for i, listener := range gw.Spec.Listeners {
+			if !slices.Contains(v.capabilities.Protocols, listener.Protocol) {
+				errs = append(errs, field.NotSupported(field.NewPath("spec", "listeners").Index(i).Child("protocol"), listener.Protocol, v.capabilities.Protocols))
+			}
+		}
+	}
+	if len(v.capabilities.AddressTypes) > 0 {
+		for i, address := range gw.Spec.Addresses {
+			addressType := gatewayv1.IPAddressType
+			if address.Type != nil {
+				addressType = *address.Type
+			}
+			if !slices.Contains(v.capabilities.AddressTypes, addressType) {
+				errs = append(errs, field.NotSupported(field.NewPath("spec", "addresses").Index(i).Child("type"), addressType, v.capabilities.AddressTypes))
+			}
+		}
+	}
+	return errs
+}
+
+// managedRoute returns true if any of the route parents is a managed Gateway
+func (v *validator) managedRoute(ctx context.Context, namespace string, parentRefs []gatewayv1.ParentReference) (bool, error) {
+	for _, parentRef := range parentRefs {
+		if parentRef.Group != nil && *parentRef.Group != gatewayv1.GroupName {
+			continue
+		}
+		if parentRef.Kind != nil && *parentRef.Kind != "Gateway" {
+			continue
+		}
+		key := types.NamespacedName{Namespace: namespace, Name: string(parentRef.Name)}
+		if parentRef.Namespace != nil {
+			key.Namespace = string(*parentRef.Namespace)
+		}
+		gw := &gatewayv1.Gateway{}
+		if err := v.client.Get(ctx, key, gw); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return false, err
+		}
+		managed, err := v.managedGateway(ctx, gw)
+		if err != nil || managed {
+			return managed, err
+		}
+	}
+	return false, nil
+}
+
+func (v *validator) validateHTTPRoute(route *gatewayv1.HTTPRoute) field.ErrorList {
+	if len(v.capabilities.HTTPRouteFilters) == 0 {
+		return nil
+	}
+	var errs field.ErrorList
+	validateFilters := func(path *field.Path, filters []gatewayv1.HTTPRouteFilter) {
+		for i, filter := range filters {
+			if !slices.Contains(v.capabilities.HTTPRouteFilters, filter.Type) {
+				errs = append(errs, field.NotSupported(path.Index(i).Child("type"), filter.Type, v.capabilities.HTTPRouteFilters))
+			}
+		}
+	}
+	for i, rule := range route.Spec.Rules {
+		rulePath := field.NewPath("spec", "rules").Index(i)
+		validateFilters(rulePath.Child("filters"), rule.Filters)
+		for j, backendRef := range rule.BackendRefs {
+			validateFilters(rulePath.Child("backendRefs").Index(j).Child("filters"), backendRef.Filters)
+		}
+	}
+	return errs
+}
+
+// gatewayValidator is the validating webhook of the Gateways
+type gatewayValidator struct {
+	*validator
+}
+
+var _ admission.CustomValidator = &gatewayValidator{}
+
+func (v *gatewayValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	gw, ok := obj.(*gatewayv1.Gateway)
+	if !ok {
+		return nil, fmt.Errorf("expected a Gateway, got %T", obj)
+	}
+	managed, err := v.managedGateway(ctx, gw)
+	if err != nil || !managed {
+		return nil, err
+	}
+	if errs := v.validateGateway(gw); len(errs) > 0 {
+		return nil, apierrors.NewInvalid(gatewayv1.SchemeGroupVersion.WithKind("Gateway").GroupKind(), gw.GetName(), errs)
+	}
+	return nil, nil
+}
+
+func (v *gatewayValidator) ValidateUpdate(ctx context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	return v.ValidateCreate(ctx, newObj)
+}
+
+func (v *gatewayValidator) ValidateDelete(context.Context, runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// httpRouteValidator is the validating webhook of the HTTPRoutes
+type httpRouteValidator struct {
+	*validator
+}
+
+var _ admission.CustomValidator = &httpRouteValidator{}
+
+func (v *httpRouteValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	route, ok := obj.(*gatewayv1.HTTPRoute)
+	if !ok {
+		return nil, fmt.Errorf("expected an HTTPRoute, got %T", obj)
+	}
+	managed, err := v.managedRoute(ctx, route.GetNamespace(), route.Spec.ParentRefs)
+	if err != nil || !managed {
+		return nil, err
+	}
+	if errs := v.validateHTTPRoute(route); len(errs) > 0 {
+		return nil, apierrors.NewInvalid(gatewayv1.SchemeGroupVersion.WithKind("HTTPRoute").GroupKind(), route.GetName(), errs)
+	}
+	return nil, nil
+}
+
+func (v *httpRouteValidator) ValidateUpdate(ctx context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	return v.ValidateCreate(ctx, newObj)
+}
+
+func (v *httpRouteValidator) ValidateDelete(context.Context, runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The package webhooks contains the admission webhooks served by kgame, that
+// reject the Gateway API resources using capabilities not declared by the
+// implementation before they are persisted, and set the implementation defaults
+// on them.
+// The webhooks only act on resources managed by kgame: Gateways of a managed
+// GatewayClass, and routes with a managed Gateway as parent.
+package webhooks
+
+import (
+	"context"
+	"fmt"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// Options configures the webhook server hosted by the manager
+type Options struct {
+	// Enabled starts the webhook server. Disabled by default
+	Enabled bool
+	// Port of the webhook server. Defaults to 9443
+	Port int
+	// CertDir is the directory containing the tls.crt and tls.key files of the
+	// webhook server. Defaults to <temp-dir>/k8s-webhook-server/serving-certs
+	CertDir string
+	// Capabilities are the features supported by the implementation. Resources
+	// using any other feature are rejected
+	Capabilities Capabilities
+	// GatewayDefaulter sets the implementation defaults on the managed Gateways,
+	// like the listener TLS options or infrastructure annotations
+	GatewayDefaulter func(ctx context.Context, gw *gatewayv1.Gateway) error
+	// HTTPRouteDefaulter sets the implementation defaults on the managed
+	// HTTPRoutes
+	HTTPRouteDefaulter func(ctx context.Context, route *gatewayv1.HTTPRoute) error
+	// CertBootstrap generates, and rotates, the webhook server certificate and
+	// injects its CA on the webhook configurations. When empty, the certificate is expected on
+	// CertDir and the CA injection is up to the installer
+	CertBootstrap *CertBootstrapOptions
+	// AdditionalControllerNames are the controllerNames managed by kgame besides
+	// the one passed to SetupWithManager. It is set by NewController from the
+	// ControllerOptions.ControllerClasses
+	AdditionalControllerNames []gatewayv1.GatewayController
+}
+
+// Capabilities declares the features supported by the implementation. An empty
+// list supports any value
+type Capabilities struct {
+	// Protocols supported on the Gateway listeners
+	Protocols []gatewayv1.ProtocolType
+	// AddressTypes supported on the Gateway spec.addresses
+	AddressTypes []gatewayv1.AddressType
+	// HTTPRouteFilters supported on the HTTPRoute rules and backends
+	HTTPRouteFilters []gatewayv1.HTTPRouteFilterType
+}
+
+// SetupWithManager registers the validating webhooks on the manager webhook
+// server
+func SetupWithManager(mgr manager.Manager, controllerName gatewayv1.GatewayController, options Options) error {
+	if options.CertBootstrap != nil {
+		if err := bootstrapCertificate(context.Background(), mgr.GetConfig(), options); err != nil {
+			return err
+		}
+		if err := mgr.Add(&certRotator{config: mgr.GetConfig(), options: options, logger: mgr.GetLogger().WithName("webhook-certificate")}); err != nil {
+			return err
+		}
+	}
+
+	v := &validator{
+		client:          mgr.GetClient(),
+		controllerNames: append([]gatewayv1.GatewayController{controllerName}, options.AdditionalControllerNames...),
+		capabilities:    options.Capabilities,
+	}
+
+	gatewayWebhook := ctrl.NewWebhookManagedBy(mgr).
+		For(&gatewayv1.Gateway{}).
+		WithValidator(&gatewayValidator{v})
+	if options.GatewayDefaulter != nil {
+		gatewayWebhook = gatewayWebhook.WithDefaulter(&gatewayDefaulter{validator: v, defaulter: options.GatewayDefaulter})
+	}
+	if err := gatewayWebhook.Complete(); err != nil {
+		return fmt.Errorf("error registering the gateway webhook: %w", err)
+	}
+
+	httpRouteWebhook := ctrl.NewWebhookManagedBy(mgr).
+		For(&gatewayv1.HTTPRoute{}).
+		WithValidator(&httpRouteValidator{v})
+	if options.HTTPRouteDefaulter != nil {
+		httpRouteWebhook = httpRouteWebhook.WithDefaulter(&httpRouteDefaulter{validator: v, defaulter: options.HTTPRouteDefaulter})
+	}
+	if err := httpRouteWebhook.Complete(); err != nil {
+		return fmt.Errorf("error registering the httproute webhook: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The package writer writes the status of the resources managed by kgame, with
+// the configured field manager and write strategy.
+package writer
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// Strategy is how kgame writes the resources
+type Strategy string
+
+const (
+	// StrategyMergePatch sends a JSON merge patch with the changes from the
+	// original object. It is the default
+	StrategyMergePatch Strategy = "MergePatch"
+	// StrategyUpdate updates the whole object, failing on conflicts with writes
+	// from other controllers
+	StrategyUpdate Strategy = "Update"
+	// StrategyServerSideApply applies the status entries owned by kgame, without
+	// forcing the fields owned by other managers. A conflict with them falls back
+	// to a merge patch failing when the original object is stale
+	StrategyServerSideApply Strategy = "ServerSideApply"
+)
+
+// DefaultFieldManager is the field manager of the server side apply writes when
+// none is configured
+const DefaultFieldManager = "kgame"
+
+// Options configures the writes of a controller
+type Options struct {
+	// FieldManager is the manager of the fields written by kgame, as shown on the
+	// managedFields. Defaults to the Kubernetes client one, the program name, or to
+	// DefaultFieldManager with StrategyServerSideApply
+	FieldManager string
+	// Strategy of the status writes. Defaults to StrategyMergePatch
+	Strategy Strategy
+}
+
+// Validate returns an error if the Strategy is not supported
+func (o Options) Validate() error {
+	switch o.Strategy {
+	case "", StrategyMergePatch, StrategyUpdate, StrategyServerSideApply:
+		return nil
+	}
+	return fmt.Errorf("unsupported write strategy %q, supported strategies are %q, %q and %q", o.Strategy, StrategyMergePatch, StrategyUpdate, StrategyServerSideApply)
+}
+
+// Client returns c writing with the FieldManager, if it is set
+func (o Options) Client(c client.Client) client.Client {
+	if o.FieldManager == "" {
+		return c
+	}
+	return client.WithFieldOwner(c, o.FieldManager)
+}
+
+// OwnedStatusFunc drops from the status of obj, a copy of the written object, the
+// entries not owned by the controller, like the conditions and route parents of
+// other controllers, so they are not applied with StrategyServerSideApply
+type OwnedStatusFunc func(obj client.Object)
+
+// StatusWriter writes the status of the objects with the Strategy
+type StatusWriter struct {
+	client  client.Client
+	scheme  *runtime.Scheme
+	options Options
+	owned   OwnedStatusFunc
+}
+
+// NewStatusWriter returns a StatusWriter using c. The scheme resolves the kind of
+// the applied objects, and owned their status owned by the controller, the whole
+// status when empty
+func NewStatusWriter(c client.Client, scheme *runtime.Scheme, options Options, owned OwnedStatusFunc) *StatusWriter {
+	return &StatusWriter{client: options.Client(c), scheme: scheme, options: options, owned: owned}
+}
+
+// Write writes the status of obj. original is the object before the changes,
+// used by StrategyMergePatch. The write is skipped when the status of obj is
+// semantically equal to the original one
+func (w *StatusWriter) Write(ctx context.Context, obj, original client.Object) error {
+	if original != nil && statusEqual(obj, original) {
+		return nil
+	}
+	switch w.options.Strategy {
+	case StrategyUpdate:
+		return w.client.Status().Update(ctx, obj)
+	case StrategyServerSideApply:
+		err := w.apply(ctx, obj)
+		if original == nil || !apierrors.IsConflict(err) {
+			return err
+		}
+		// The fields owned by other managers, like the atomic route parents written
+		// by another controller, are patched from the original object instead, so
+		// a stale cache fails and is retried rather than overwriting them
+		return w.client.Status().Patch(ctx, obj, client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{}))
+	default:
+		return w.client.Status().Patch(ctx, obj, client.MergeFrom(original))
+	}
+}
+
+// apply applies the status of obj owned by the controller, without the
+// metadata of the cached object, as the apply configuration has only the
+// fields set by kgame
+func (w *StatusWriter) apply(ctx context.Context, obj client.Object) error {
+	gvk, err := apiutil.GVKForObject(obj, w.scheme)
+	if err != nil {
+		return err
+	}
+	owned, ok := obj.DeepCopyObject().(client.Object)
+	if !ok {
+		return fmt.Errorf("unable to copy %s", gvk.Kind)
+	}
+	if w.owned != nil {
+		w.owned(owned)
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(owned)
+	if err != nil {
+		return err
+	}
+
+	applied := &unstructured.Unstructured{Object: map[string]any{}}
+	if status, ok := content["status"]; ok {
+		applied.Object["status"] = status
+	}
+	applied.SetGroupVersionKind(gvk)
+	applied.SetNamespace(obj.GetNamespace())
+	applied.SetName(obj.GetName())
+	fieldManager := w.options.FieldManager
+	if fieldManager == "" {
+		fieldManager = DefaultFieldManager
+	}
+	return w.client.Status().Patch(ctx, applied, client.Apply, client.FieldOwner(fieldManager))
+}
+
+// statusEqual returns true if the objects have the same status, ignoring the
+// LastTransitionTime of the conditions. Objects that cannot be converted are
+// not equal
+func statusEqual(a, b client.Object) bool {
+	statusA, err := comparableStatus(a)
+	if err != nil {
+		return false
+	}
+	statusB, err := comparableStatus(b)
+	if err != nil {
+		return false
+	}
+	return equality.Semantic.DeepEqual(statusA, statusB)
+}
+
+// comparableStatus returns the status of obj, without the LastTransitionTime of
+// the conditions
+func comparableStatus(obj client.Object) (any, error) {
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	status := u["status"]
+	stripTransitionTimes(status)
+	return status, nil
+}
+
+// stripTransitionTimes removes the lastTransitionTime of all the conditions in
+// value, including the nested ones like the listeners and parents conditions
+func stripTransitionTimes(value any) {
+	switch v := value.(type) {
+	case map[string]any:
+		if conditions, ok := v["conditions"].([]any); ok {
+			for _, condition := range conditions {
+				if c, ok := condition.(map[string]any); ok {
+					delete(c, "lastTransitionTime")
+				}
+			}
+		}
+		for _, nested := range v {
+			stripTransitionTimes(nested)
+		}
+	case []any:
+		for _, nested := range v {
+			stripTransitionTimes(nested)
+		}
+	}
+}